@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+// alerter rings the bell of the terminal with a summary of the errors at the
+// end of every interval that had at least the minimum of them, so a terminal
+// tab in the background still draws attention to a burst of errors. It writes to stderr, where it
+// doesn't interleave with the entries and stays out of redirected output.
+type alerter struct {
+	mu       sync.Mutex
+	output   io.Writer
+	interval time.Duration
+	minimum  int
+	errors   int
+	done     chan struct{}
+}
+
+func newAlerter(output io.Writer, interval time.Duration, minimum int) *alerter {
+	a := &alerter{output: output, interval: interval, minimum: minimum, done: make(chan struct{})}
+	go a.run()
+	return a
+}
+
+func (a *alerter) run() {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.alert()
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// Observe counts the entry when it's logged at error or above.
+func (a *alerter) Observe(entry *structure.Entry) {
+	if structure.SeverityRank(entry.Severity) < structure.SeverityRank("ERROR") {
+		return
+	}
+	a.mu.Lock()
+	a.errors++
+	a.mu.Unlock()
+}
+
+func (a *alerter) alert() {
+	a.mu.Lock()
+	errors := a.errors
+	a.errors = 0
+	a.mu.Unlock()
+	if errors < a.minimum {
+		return
+	}
+	noun := "errors"
+	if errors == 1 {
+		noun = "error"
+	}
+	fmt.Fprintf(a.output, "\a*** %d %s in the last %s ***\n", errors, noun, a.interval)
+}
+
+// Close stops the alerts, the errors of the unfinished interval are left out.
+func (a *alerter) Close() {
+	close(a.done)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// benchFormats generate the i-th synthetic line of a log format.
+var benchFormats = []struct {
+	name string
+	line func(i int) string
+}{
+	{"zap", func(i int) string {
+		return fmt.Sprintf(`{"level":"%s","ts":%d.%06d,"logger":"api","caller":"server/handler.go:%d","msg":"request handled","method":"GET","path":"/users/%d","status":%d,"duration":%d.%03d}`,
+			benchLevel(i), 1686919896+i/1000, i%1000000, 40+i%20, i, benchStatus(i), i%3, i%1000)
+	}},
+	{"slog", func(i int) string {
+		return fmt.Sprintf(`{"time":"2023-06-16T12:%02d:%02d.%03dZ","level":"%s","msg":"request handled","method":"GET","path":"/users/%d","status":%d,"user":{"id":%d,"role":"admin"}}`,
+			i/60%60, i%60, i%1000, benchLevel(i), i, benchStatus(i), i%500)
+	}},
+	{"journald", func(i int) string {
+		return fmt.Sprintf(`{"__REALTIME_TIMESTAMP":"%d","PRIORITY":"%d","MESSAGE":"Accepted publickey for user%d from 10.0.%d.%d port %d","_SYSTEMD_UNIT":"sshd.service","SYSLOG_IDENTIFIER":"sshd","_PID":"%d","_HOSTNAME":"example.org","_TRANSPORT":"syslog"}`,
+			1686919896987169+int64(i)*1000, 3+i%4, i%100, i%256, i%200, 40000+i%20000, 1000+i%300)
+	}},
+}
+
+func benchLevel(i int) string {
+	switch {
+	case i%100 == 0:
+		return "error"
+	case i%10 == 0:
+		return "warn"
+	}
+	return "info"
+}
+
+func benchStatus(i int) int {
+	if i%100 == 0 {
+		return 500
+	}
+	return 200
+}
+
+// runBench generates lines of synthetic logs of every format, or only the
+// given one, and writes the throughput and allocations of parsing and
+// formatting them as a table.
+func runBench(w io.Writer, format string, lines int) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "format\tlines\ttime\tlines/s\tMB/s\tallocs/line\tbytes/line\t")
+	found := false
+	for _, f := range benchFormats {
+		if format != "" && format != f.name {
+			continue
+		}
+		found = true
+		var data bytes.Buffer
+		for i := 0; i < lines; i++ {
+			data.WriteString(f.line(i))
+			data.WriteByte('\n')
+		}
+		size := data.Len()
+		elapsed, allocs, allocated, err := benchFormat(&data)
+		if err != nil {
+			return err
+		}
+		seconds := elapsed.Seconds()
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.0f\t%.1f\t%.1f\t%.0f\t\n", f.name, lines, elapsed.Round(time.Millisecond),
+			float64(lines)/seconds, float64(size)/seconds/1e6, float64(allocs)/float64(lines), float64(allocated)/float64(lines))
+	}
+	if !found {
+		return fmt.Errorf("unknown format %q, expected zap, slog or journald", format)
+	}
+	return tw.Flush()
+}
+
+// benchFormat parses and formats all lines, returning the time it took and
+// the number of allocations and allocated bytes.
+func benchFormat(r io.Reader) (time.Duration, uint64, uint64, error) {
+	formatter, err := structure.NewFormatter(io.Discard, "")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for line := range stream.New(r).Lines() {
+		entry, ok, err := parser.Line(line)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if !ok {
+			err = formatter.FormatRaw(line.Raw)
+		} else {
+			err = formatter.Format(entry, line.JSON, line.Prefix, line.Suffix)
+		}
+		if err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return elapsed, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc, nil
+}
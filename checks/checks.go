@@ -0,0 +1,123 @@
+// Package checks treats --fail-on rules as named checks, which fail when
+// any log entry matches the rule, and reports them as JUnit XML or TAP.
+package checks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/koenbollen/jl/filter"
+	"github.com/koenbollen/jl/structure"
+)
+
+// Check is a rule that fails as soon as an entry matches it.
+type Check struct {
+	Name    string
+	expr    *filter.Expr
+	Matches int
+	First   string
+}
+
+// New parses every rule as a check, the rule itself is used as its name.
+func New(rules []string) ([]*Check, error) {
+	var checks []*Check
+	for _, rule := range rules {
+		expr, err := filter.Parse(rule)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, &Check{Name: rule, expr: expr})
+	}
+	return checks, nil
+}
+
+// Observe evaluates the check against an entry, remembering the message of
+// the first matching entry.
+func (c *Check) Observe(raw []byte, entry *structure.Entry) {
+	if c.expr.Match(raw, entry) {
+		if c.Matches == 0 {
+			c.First = entry.Message
+		}
+		c.Matches++
+	}
+}
+
+// Failed returns true if any entry matched the check.
+func (c *Check) Failed() bool {
+	return c.Matches > 0
+}
+
+func (c *Check) failure() string {
+	return fmt.Sprintf("%d matching entries, first: %s", c.Matches, c.First)
+}
+
+// AnyFailed returns true if at least one of the checks failed.
+func AnyFailed(checks []*Check) bool {
+	for _, check := range checks {
+		if check.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTAP writes the result of the checks in the Test Anything Protocol.
+func WriteTAP(w io.Writer, checks []*Check) error {
+	lines := []string{"TAP version 13", fmt.Sprintf("1..%d", len(checks))}
+	for i, check := range checks {
+		if check.Failed() {
+			lines = append(lines,
+				fmt.Sprintf("not ok %d - %s", i+1, check.Name),
+				"  ---",
+				fmt.Sprintf("  matches: %d", check.Matches),
+				fmt.Sprintf("  first: %q", check.First),
+				"  ...")
+		} else {
+			lines = append(lines, fmt.Sprintf("ok %d - %s", i+1, check.Name))
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the result of the checks as a JUnit XML test suite.
+func WriteJUnit(w io.Writer, checks []*Check) error {
+	suite := junitSuite{Name: "jl", Tests: len(checks)}
+	for _, check := range checks {
+		testcase := junitCase{Name: check.Name}
+		if check.Failed() {
+			suite.Failures++
+			testcase.Failure = &junitFailure{Message: check.failure()}
+		}
+		suite.Cases = append(suite.Cases, testcase)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,68 @@
+package checks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func observe(t *testing.T, rules []string, loglines ...string) []*Check {
+	t.Helper()
+	checks, err := New(rules)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	for _, logline := range loglines {
+		entry := &structure.Entry{}
+		djson.Unmarshal([]byte(logline), entry)
+		for _, check := range checks {
+			check.Observe([]byte(logline), entry)
+		}
+	}
+	return checks
+}
+
+func TestWriteTAP(t *testing.T) {
+	t.Parallel()
+
+	checks := observe(t, []string{"level>=error", "status=500"},
+		`{"level": "info", "msg": "started", "status": 200}`,
+		`{"level": "error", "msg": "db down"}`,
+	)
+	buf := &bytes.Buffer{}
+	if err := WriteTAP(buf, checks); err != nil {
+		t.Fatalf("WriteTAP() = %v", err)
+	}
+	expect := "TAP version 13\n1..2\nnot ok 1 - level>=error\n  ---\n  matches: 1\n  first: \"db down\"\n  ...\nok 2 - status=500\n"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+	if !AnyFailed(checks) {
+		t.Errorf("AnyFailed() = false, want true")
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	checks := observe(t, []string{"error"},
+		`{"level": "error", "msg": "db down"}`,
+		`{"level": "fatal", "msg": "exiting"}`,
+	)
+	buf := &bytes.Buffer{}
+	if err := WriteJUnit(buf, checks); err != nil {
+		t.Fatalf("WriteJUnit() = %v", err)
+	}
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="jl" tests="1" failures="1">
+  <testcase name="error">
+    <failure message="2 matching entries, first: db down"></failure>
+  </testcase>
+</testsuite>
+`
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+}
@@ -0,0 +1,117 @@
+package checks
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// jsonTypes are the types a field can be expected to have, with aliases.
+var jsonTypes = map[string]string{
+	"string":  "string",
+	"number":  "number",
+	"bool":    "bool",
+	"boolean": "bool",
+	"object":  "object",
+	"array":   "array",
+	"null":    "null",
+}
+
+// Expectation counts the entries in which a field has another json type
+// than expected, to catch schema drift. Entries without the field are
+// ignored.
+type Expectation struct {
+	Field string
+	Type  string
+	// Found counts the unexpected types by name
+	Found map[string]int
+	First string
+}
+
+// NewExpectations parses expectations in the form field:type, like
+// duration_ms:number.
+func NewExpectations(specs []string) ([]*Expectation, error) {
+	var expectations []*Expectation
+	for _, spec := range specs {
+		i := strings.LastIndex(spec, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q is not a field:type pair", spec)
+		}
+		kind, ok := jsonTypes[strings.ToLower(spec[i+1:])]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q in %q, expected string, number, bool, object, array or null", spec[i+1:], spec)
+		}
+		expectations = append(expectations, &Expectation{Field: spec[:i], Type: kind, Found: map[string]int{}})
+	}
+	return expectations, nil
+}
+
+// Observe checks the type of the field in an entry, remembering the message
+// of the first entry with an unexpected type.
+func (e *Expectation) Observe(raw []byte, entry *structure.Entry) {
+	value := gjson.GetBytes(raw, e.Field)
+	if !value.Exists() {
+		return
+	}
+	if kind := jsonType(value); kind != e.Type {
+		if e.Mismatches() == 0 {
+			e.First = entry.Message
+		}
+		e.Found[kind]++
+	}
+}
+
+// Mismatches returns the number of entries in which the field had an
+// unexpected type.
+func (e *Expectation) Mismatches() int {
+	n := 0
+	for _, count := range e.Found {
+		n += count
+	}
+	return n
+}
+
+func jsonType(value gjson.Result) string {
+	switch value.Type {
+	case gjson.String:
+		return "string"
+	case gjson.Number:
+		return "number"
+	case gjson.True, gjson.False:
+		return "bool"
+	case gjson.Null:
+		return "null"
+	}
+	if value.IsArray() {
+		return "array"
+	}
+	return "object"
+}
+
+// WriteExpectations writes the expectations that were not met, with the
+// unexpected types that were found.
+func WriteExpectations(w io.Writer, expectations []*Expectation) error {
+	for _, e := range expectations {
+		if e.Mismatches() == 0 {
+			continue
+		}
+		kinds := make([]string, 0, len(e.Found))
+		for kind := range e.Found {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		found := make([]string, len(kinds))
+		for i, kind := range kinds {
+			found[i] = fmt.Sprintf("%d %s", e.Found[kind], kind)
+		}
+		_, err := fmt.Fprintf(w, "expected %s to be %s, found %s (first: %s)\n", e.Field, e.Type, strings.Join(found, ", "), e.First)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
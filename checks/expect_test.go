@@ -0,0 +1,52 @@
+package checks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestWriteExpectations(t *testing.T) {
+	t.Parallel()
+
+	expectations, err := NewExpectations([]string{"duration_ms:number", "level:string", "tags:array", "user.id:Number"})
+	if err != nil {
+		t.Fatalf("NewExpectations() = %v", err)
+	}
+	for _, logline := range []string{
+		`{"level": "info", "msg": "first", "duration_ms": 12, "tags": ["a"], "user": {"id": 1}}`,
+		`{"level": "info", "msg": "second", "duration_ms": "12ms", "tags": "a"}`,
+		`{"level": 30, "msg": "third", "duration_ms": null, "user": {"id": "1"}}`,
+		`{"msg": "fourth"}`,
+	} {
+		entry := &structure.Entry{}
+		djson.Unmarshal([]byte(logline), entry)
+		for _, expectation := range expectations {
+			expectation.Observe([]byte(logline), entry)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteExpectations(buf, expectations); err != nil {
+		t.Fatalf("WriteExpectations() = %v", err)
+	}
+	expect := "expected duration_ms to be number, found 1 null, 1 string (first: second)\n" +
+		"expected level to be string, found 1 number (first: third)\n" +
+		"expected tags to be array, found 1 string (first: second)\n" +
+		"expected user.id to be number, found 1 string (first: third)\n"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+}
+
+func TestNewExpectationsErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{"duration_ms", ":number", "duration_ms:integer"} {
+		if _, err := NewExpectations([]string{spec}); err == nil {
+			t.Errorf("NewExpectations(%q) = nil, want error", spec)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package checks
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/koenbollen/jl/schema"
+	"github.com/koenbollen/jl/structure"
+)
+
+// The ways entries that don't match the schema are handled.
+const (
+	InvalidAnnotate = "annotate"
+	InvalidHide     = "hide"
+	InvalidFail     = "fail"
+)
+
+// Validation validates entries against a JSON Schema to enforce a logging
+// contract, counting the entries that don't match it.
+type Validation struct {
+	Name    string
+	Schema  *schema.Schema
+	OnError string
+	Invalid int
+	First   string
+}
+
+// NewValidation compiles the schema, invalid entries are handled as given by
+// onError: annotate, hide or fail.
+func NewValidation(name string, data []byte, onError string) (*Validation, error) {
+	switch onError {
+	case InvalidAnnotate, InvalidHide, InvalidFail:
+	default:
+		return nil, fmt.Errorf("unknown mode %q, expected annotate, hide or fail", onError)
+	}
+	s, err := schema.Compile(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Validation{Name: name, Schema: s, OnError: onError}, nil
+}
+
+// Observe validates an entry, annotating it with the violations of the
+// schema. It returns false when the entry should be hidden.
+func (v *Validation) Observe(raw []byte, entry *structure.Entry) bool {
+	violations := v.Schema.Validate(raw)
+	if len(violations) == 0 {
+		return true
+	}
+	if v.Invalid == 0 {
+		v.First = entry.Message + ": " + violations[0]
+	}
+	v.Invalid++
+	if v.OnError == InvalidHide {
+		return false
+	}
+	for _, violation := range violations {
+		entry.Annotations = append(entry.Annotations, "schema: "+violation)
+	}
+	return true
+}
+
+// Failed reports whether invalid entries should fail the run.
+func (v *Validation) Failed() bool {
+	return v.OnError == InvalidFail && v.Invalid > 0
+}
+
+// WriteValidation writes the number of entries that didn't match the
+// schema, with the first violation.
+func WriteValidation(w io.Writer, v *Validation) error {
+	if v == nil || v.Invalid == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%d %s not match %s (first: %s)\n", v.Invalid, pluralEntries(v.Invalid), v.Name, v.First)
+	return err
+}
+
+func pluralEntries(n int) string {
+	if n == 1 {
+		return "entry does"
+	}
+	return "entries do"
+}
@@ -0,0 +1,70 @@
+package checks
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestValidation(t *testing.T) {
+	t.Parallel()
+
+	schema := []byte(`{"required": ["level"], "properties": {"level": {"enum": ["info", "error"]}}}`)
+	for _, tt := range []struct {
+		onError     string
+		shown       []bool
+		annotations []string
+		failed      bool
+	}{
+		{InvalidAnnotate, []bool{true, true, true}, []string{"schema: level: must be one of [\"info\",\"error\"]"}, false},
+		{InvalidHide, []bool{true, false, false}, nil, false},
+		{InvalidFail, []bool{true, true, true}, []string{"schema: level: must be one of [\"info\",\"error\"]"}, true},
+	} {
+		v, err := NewValidation("contract.json", schema, tt.onError)
+		if err != nil {
+			t.Fatalf("NewValidation() = %v", err)
+		}
+		var entries []*structure.Entry
+		for i, logline := range []string{
+			`{"level": "info", "msg": "first"}`,
+			`{"level": "warning", "msg": "second"}`,
+			`{"msg": "third"}`,
+		} {
+			entry := &structure.Entry{}
+			djson.Unmarshal([]byte(logline), entry)
+			if shown := v.Observe([]byte(logline), entry); shown != tt.shown[i] {
+				t.Errorf("%s: Observe(%q) = %v, want %v", tt.onError, logline, shown, tt.shown[i])
+			}
+			entries = append(entries, entry)
+		}
+		if !reflect.DeepEqual(entries[1].Annotations, tt.annotations) {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", entries[1].Annotations, tt.annotations)
+		}
+		if v.Failed() != tt.failed {
+			t.Errorf("%s: Failed() = %v, want %v", tt.onError, v.Failed(), tt.failed)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := WriteValidation(buf, v); err != nil {
+			t.Fatalf("WriteValidation() = %v", err)
+		}
+		expect := "2 entries do not match contract.json (first: second: level: must be one of [\"info\",\"error\"])\n"
+		if buf.String() != expect {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+		}
+	}
+}
+
+func TestNewValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewValidation("a.json", []byte(`{}`), "drop"); err == nil {
+		t.Errorf("NewValidation(drop) = nil, want error")
+	}
+	if _, err := NewValidation("a.json", []byte(`{`), InvalidAnnotate); err == nil {
+		t.Errorf("NewValidation({) = nil, want error")
+	}
+}
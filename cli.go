@@ -1,11 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docopt/docopt-go"
+	"github.com/koenbollen/jl/stream"
 	"github.com/mattn/go-isatty"
 )
 
@@ -19,17 +22,257 @@ will output the entry in a human readable way. Anything else
 is forwarded as is.
 
 Usage:
-  jl [options] [FILE...]
+  jl export sqlite <database> [--index=<column>]... [FILE...]
+  jl export parquet <file> [--columns=<columns>] [FILE...]
+  jl index FILE... [options]
+  jl ctl set-filter <filter>... [options]
+  jl ctl (pause | resume) [options]
+  jl ctl mark <note>... [options]
+  jl bench [--format=<format>] [--lines=<n>] [options]
+  jl (docker | podman) <container> [--follow] [options]
+  jl compose [<service>...] [options]
+  jl ssh <hosts> [options] [--] <command>...
+  jl gcloud [--project=<project>] [--filter=<filter>] [--follow] [options]
+  jl nats --subject=<subject> [--server=<url>] [options]
+  jl mqtt --topic=<topic> [--server=<url>] [options]
+  jl redis --stream=<key> [--addr=<addr>] [--group=<group>] [options]
+  jl serve --otlp-http=<addr> [--otlp-grpc=<addr>] [options]
+  jl serve --otlp-grpc=<addr> [options]
+  jl [-v...] [--fail-on=<rule>]... [--pattern=<regex>]... [--decode-field=<spec>]... [--derive-level=<rule>]... [--add-field=<field>]... [--expect=<type>]... [--mark-pattern=<regex>]... [--route=<rule>]... [--where=<expr>]... [--columns=<columns>] [options] [FILE...]
 
 Options:
   -h, --help    Show this screen.
   --version     Show version.
 
+Input Options:
+  --follow          Keep reading the logs of the container of jl docker or
+                    jl podman, or the entries of jl gcloud, as they are
+                    written
+  --project <project>
+                    Google Cloud project to read the entries of with
+                    jl gcloud (defaults to the project of gcloud)
+  --filter <filter> Cloud Logging query of the entries of jl gcloud, like
+                    resource.type="k8s_container"
+  --subject <subject>
+                    NATS subject to subscribe to with jl nats, like logs.>
+  --topic <topic>   MQTT topic to subscribe to with jl mqtt, like logs/#
+  --server <url>    Server of jl nats or jl mqtt, like nats://host:4222 or
+                    mqtt://host:1883 (defaults to the nats context or
+                    localhost)
+  --password-file <file>
+                    Read the password of jl nats, jl mqtt or jl redis from
+                    this file, which keeps it out of ps
+  --stream <key>    Redis Stream to read the new entries of with jl redis
+  --addr <addr>     Address of the Redis server of jl redis
+                    [default: localhost:6379]
+  --group <group>   Read the Redis Stream as a consumer of this consumer
+                    group, which is created when it doesn't exist
+  --otlp-http <addr>
+                    Receive the logs of OpenTelemetry exporters with
+                    OTLP/HTTP on this address with jl serve, like :4318
+  --otlp-grpc <addr>
+                    Receive the logs of OpenTelemetry exporters with OTLP
+                    over gRPC on this address with jl serve, like :4317
+  --input <format>  Format of the input: json (lines of json and text),
+                    msgpack, fluentd (msgpack of the forward protocol or
+                    buffer chunks), cbor, csv, tsv or auto (detect binary
+                    msgpack and CBOR records, otherwise json)
+                    [default: auto]
+  --map <mapping>   Map the columns of csv and tsv input to json keys by
+                    their index or header, like time=0,level=2,msg=3
+                    (defaults to the header of the first row)
+  --proto-desc <file>
+                    Read a stream of length delimited protobuf messages,
+                    described by this FileDescriptorSet, as written by
+                    the descriptor_set_out flag of protoc
+  --proto-msg <name>
+                    The full name of the protobuf message type, like
+                    mycorp.LogRecord
+  --pattern <regex> Parse text lines of a custom format with this regular
+                    expression, its named groups and grok patterns become
+                    fields, like (?P<time>\S+) %{LOGLEVEL:level} (?P<msg>.*)
+                    (repeatable)
+  --grok-patterns <file>
+                    Load more grok patterns from this Logstash patterns
+                    file, every line is a name followed by its expression
+  --pipeline <file> Read the inputs and options of a pipeline file, a yaml
+                    file with lists of inputs, parsers, filters, transforms
+                    and outputs
+  --exec-filter <command>
+                    Pipe the lines through this shell command before they
+                    are formatted, it writes back (transformed) lines
+  --bracket-levels <mapping>
+                    Additional level markers of bracketed text logs,
+                    like DEPEND=warning,I=info
+  --journald-levels <mapping>
+                    Override the levels of journald priorities, given by
+                    name or number, like notice=info,7=trace
+  --journald-facility
+                    Show the name of the syslog facility of journald
+                    entries, like auth, before the message
+  --parse-errors    Report lines with broken json to stderr, as json with
+                    the line number, offset, error position and a snippet
+  --errors-file <file>
+                    Write the reports of --parse-errors to this file
+
 Output Options:
   --color           Force colorized output
   --no-color        Don't colorize output
   --skip-prefix     Skip printing truncated bytes before the JSON
   --skip-suffix     Skip printing truncated bytes after the JSON
+  --level <level>   Only show entries of at least this level, like warning,
+                    entries without a level and plain text are kept
+  --since <time>    Only show entries from this time on, like 2024-01-01,
+                    "2024-01-01 10:00", 2024-01-01T10:00:00+02:00 or a
+                    duration ago, like 1h; times without a zone are UTC,
+                    entries without a time and plain text are kept
+  --until <time>    Only show entries up to this time, given like --since
+  --sample <rate>   Only show a sample of the entries below --keep-level,
+                    like 1/100 for one in every hundred, plain text is
+                    kept
+  --keep-level <level>
+                    The level from which --sample keeps every entry,
+                    warnings and errors are always kept [default: warning]
+  --strip-ansi      Remove the escape sequences, like colors, that are
+                    embedded in messages, field values and lines of plain
+                    text (the default)
+  --keep-ansi       Keep the escape sequences embedded in the output
+  --highlight-values
+                    Color the values substituted into message templates,
+                    like the UserId of "user {UserId} logged in"
+  --no-infer-level  Don't color lines of plain text by the level they
+                    mention, like ERROR, WARN, panic: or fatal
+  --stderr-level <level>
+                    The level of the lines written to stderr without a
+                    level, for inputs that tell stdout and stderr apart:
+                    jl docker, jl compose and jl ssh
+  --show-thread     Show the thread (or goroutine) of entries between the
+                    level and the message
+  --follow-thread <name>
+                    Only show entries logged by this thread, entries
+                    without a thread and plain text are kept
+  --where <expr>    Only show entries matching an expression, like
+                    status=500 or msg=~timeout, all of them when repeated
+  --pair-by <key>   Combine the entries that start and end something, like
+                    a request, sharing the value of this key into a line
+                    with the latency between them
+  --pair-start <expr>
+                    The expression of the start entries of --pair-by,
+                    like 'msg="request received"'
+  --pair-end <expr>
+                    The expression of the end entries of --pair-by, like
+                    'msg="response sent"'
+  --pair-timeout <duration>
+                    Write the starts of --pair-by without an end after
+                    this time as unpaired, a potential hang [default: 1m]
+  --count           Print the number of entries instead of the entries,
+                    like grep -c
+  --count-per <window>
+                    Print the number of entries per window of their time,
+                    like 1m or 1h, instead of the entries
+  --sessionize <rule>
+                    Print the sessions of every value of a key, with their
+                    duration and number of entries, instead of the
+                    entries, split by a gap of inactivity, like
+                    user_id:30m
+  --control <socket>
+                    Listen on this unix socket for jl ctl, which changes
+                    the filters of the running session, like level=warn,
+                    or pauses and resumes its output
+  --mark-pattern <regex>
+                    Bookmark the entries matching this regular expression
+                    for the timeline of --marks-file (repeatable)
+  --marks-file <file>
+                    Export the bookmarked entries, and the notes added
+                    with jl ctl mark, as an incident timeline to this
+                    file at exit: markdown for .md files, otherwise json
+  --max-memory <size>
+                    Memory to hold back entries in, shared by the starts
+                    of --pair-by, the entries of --group-by-unit and the
+                    output of a paused session: the paused output is
+                    spilled to a temporary file, the other entries are
+                    written early [default: 256MB]
+  --show-source-context <lines>
+                    Print this many lines of source code around the
+                    caller of an entry, when the file exists locally: at
+                    its path, or at the longest end of it in the current
+                    directory that's more than the file name
+  --output <mode>   Output format: text, markdown (a table to paste in
+                    issues), html (a standalone page with colors), gha
+                    (errors and warnings as GitHub Actions annotations),
+                    csv, tsv, table (aligned columns that fit the
+                    terminal), json or logfmt (normalized lines), compact
+                    (short lines) or expanded (a line per field)
+                    [default: text]
+  --columns <columns>
+                    The json keys to output as columns in the csv, tsv
+                    and table output or parquet export (comma separated
+                    list, defaults to time,level,msg)
+  --template <format>
+                    Go template of the text output, with pad, rpad,
+                    trunc and ltrunc to keep columns aligned, like
+                    '{{pad .Level 7}} {{rpad (.Field "logger") 12}} {{.Message}}'
+  --route <rule>    Also write the entries matching an expression to a
+                    file or another terminal, like tenant=acme:>acme.log
+                    or level>=error:>>errors.log to append (repeatable)
+  --highlight <style>
+                    Make entries logged at error or above stand out when
+                    scrolling: background (a red line) or rule (lines
+                    above and below them)
+  --alert <interval>
+                    Ring the bell with the number of errors on stderr at
+                    the end of every interval with a burst of errors,
+                    like 10s, so a terminal tab in the background draws
+                    attention
+  --alert-min <int>
+                    The number of errors within an interval of --alert
+                    that rings the bell, so a single error stays quiet
+                    [default: 2]
+  --heartbeat <interval>
+                    Warn on stderr when no logs arrive within the
+                    interval, like 30s, and every interval after that
+  --heartbeat-exec <command>
+                    Run this command by the shell when the logs stop,
+                    once until they arrive again
+  --no-day-separator
+                    Don't print a separator line when the date of the
+                    entries changes
+  --group-by-unit   Hold back the entries of every second to output them
+                    grouped under a heading of their systemd unit, instead
+                    of interleaved (for journalctl -o json)
+  --stats           Print the number of lines per detected format of every
+                    input to stderr when done
+  --forward-syslog <url>
+                    Also send every entry as an RFC 5424 message to this
+                    syslog server, like udp://collector:514, tcp://host
+                    or unix:///dev/log
+
+Check Options:
+  --fail-on <rule>  Exit with a non-zero status when any entry matches this
+                    rule, like level>=error or status=500 (repeatable)
+  --expect <type>   Report entries in which a json key has another type
+                    than expected to stderr, like duration_ms:number or
+                    level:string (repeatable)
+  --validate <schema>
+                    Validate every entry against this JSON Schema file
+  --on-invalid <mode>
+                    What to do with entries that don't match the schema:
+                    annotate, hide or fail [default: annotate]
+  --report <format>
+                    Report the --fail-on rules as checks in this format:
+                    junit or tap
+  --report-file <file>
+                    Write the report to this file instead of stderr
+
+Export Options:
+  --index <column>  Copy this json key into an indexed column of the
+                    exported database (repeatable)
+
+Bench Options:
+  --format <format>
+                    Only benchmark this log format: zap, slog or journald
+  --lines <n>       Number of synthetic lines to generate per log format
+                    [default: 100000]
 
 Formatting Options:
   --skip-fields     Don't output misc json keys as fields
@@ -37,12 +280,75 @@ Formatting Options:
                     Any field, exceeding the given length (including
                     field name) will be ommitted from output. Use 0
                     to remove the length limit [default: 30]
+  --quote <style>   When to quote the values of fields: auto (only values
+                    containing spaces or special characters), always,
+                    never or shell (shell-safe key=value arguments)
+                    [default: never]
+  --enrich-ua <fields>
+                    Summarize the User-Agent headers in these json keys
+                    as browser and OS, like Chrome 114 / macOS (comma
+                    separated list, -v shows the raw header)
+  --jwt <style>     How to render fields holding a JSON Web Token: redact
+                    (replace the signature), summary (the sub, aud and
+                    exp claims, highlighting expired tokens) or raw
+                    [default: redact]
+  --array-style <style>
+                    How to render array fields: inline ([a,b,c]), index
+                    (key.0=a key.1=b) or count ((3 items))
+                    [default: inline]
+  --max-depth <int>
+                    Summarize objects nested deeper than this as {…N},
+                    where N is the number of keys. Use 0 to remove the
+                    depth limit [default: 3]
+  -v, --verbose     Expand summarized objects and arrays and reveal
+                    fields hidden by the length limit, repeat (-vv) to
+                    show all fields including nested and infrastructure
+                    fields (like hostname and pid)
+  --hidden-count    Show how many fields were hidden, like (+3 fields)
+  --hidden-summary  Report how many entries, fields and bytes were hidden,
+                    and why, to stderr at exit
+  --caller-style <rule>
+                    How to shorten the paths of caller fields: auto
+                    (module relative), short (last two segments) or
+                    full [default: auto]
+  --full-caller     Don't shorten caller paths (same as using the full
+                    caller style)
+  --module <prefix>
+                    Highlight stacktrace frames of this module, instead
+                    of the module in the go.mod of the current directory
+  --error-fields <fields>
+                    Additional json keys containing the error message of
+                    a stacktrace (comma separated list)
+  --stack-fields <fields>
+                    Additional json keys containing a multi-line
+                    stacktrace (comma separated list)
   --include-fields <fields>, -f <fields>
                     Always include these json keys as fields, no matter
                     the length (comma separated list)
   --exclude-fields <fields>
                     Always exclude these json keys (comma separated
                     list)
+  --logger-field <fields>
+                    The json keys of which the first one present is shown
+                    as logger between the level and message, in its own
+                    color (comma separated list, empty to disable)
+                    [default: logger,component,module,@module]
+  --status-level    Raise the level of entries logged at info, or without
+                    a level, to warning or error when their http or gRPC
+                    status field reports a failure
+  --add-field <field>
+                    Add a field computed from other fields, with
+                    arithmetic like "latency_s = duration_ms / 1000" or
+                    a template like "route = {{.method}} {{.path}}"
+                    (repeatable)
+  --derive-level <rule>
+                    Override the level of entries matching a rule, like
+                    status>=500:error or msg=~"timeout":warn, the first
+                    matching rule wins (repeatable)
+  --decode-field <spec>
+                    Decode the base64 or hex payload of a field and show
+                    the json or text it contains, like payload:base64,
+                    blob:base64gzip or data:hex (repeatable)
 
 You can add any option to the JL_OPTS environment variable, ex:
   export JL_OPTS="--no-color"
@@ -54,20 +360,361 @@ Example:
 
 var version = "v1.6.0"
 
-func cli() (files []string, color, showPrefix, showSuffix, showFields bool, includeFields string, excludeFields string, maxFieldLength int) {
-	argv := append(os.Args[1:], strings.Split(os.Getenv("JL_OPTS"), " ")...)
+// options contains the parsed command line arguments.
+type options struct {
+	files            []string
+	input            string
+	mapping          string
+	bracketLevels    string
+	journaldLevels   string
+	journaldFacility bool
+	patterns         []string
+	grokPatterns     string
+	execFilter       string
+	parseErrors      bool
+	errorsFile       string
+	protoDesc        string
+	protoMsg         string
+	export           string
+	indexFiles       bool
+	ctl              bool
+	ctlCommand       []string
+	runtime          string
+	container        string
+	follow           bool
+	compose          bool
+	services         []string
+	hosts            []string
+	command          []string
+	gcloud           bool
+	project          string
+	logFilter        string
+	bus              string
+	subject          string
+	server           string
+	passwordFile     string
+	redisAddr        string
+	redisGroup       string
+	otlpAddr         string
+	otlpGRPCAddr     string
+	control          string
+	maxMemory        int64
+	markPatterns     []string
+	marksFile        string
+	bench            bool
+	benchFormat      string
+	benchLines       int
+	database         string
+	index            []string
+	failOn           []string
+	expect           []string
+	validate         string
+	onInvalid        string
+	report           string
+	reportFile       string
+	color            bool
+	showPrefix       bool
+	showSuffix       bool
+	showFields       bool
+	daySeparator     bool
+	highlight        string
+	alert            time.Duration
+	alertMin         int
+	heartbeat        time.Duration
+	heartbeatExec    string
+	output           string
+	template         string
+	columns          string
+	forwardSyslog    string
+	stats            bool
+	callerRule       string
+	arrayStyle       string
+	quote            string
+	jwt              string
+	enrichUA         string
+	maxDepth         int
+	verbosity        int
+	hiddenCount      bool
+	hiddenSummary    bool
+	level            string
+	since, until     time.Time
+	sample           string
+	keepLevel        string
+	stderrLevel      string
+	inferLevels      bool
+	keepANSI         bool
+	highlightValues  bool
+	showThread       bool
+	groupByUnit      bool
+	followThread     string
+	module           string
+	sourceContext    int
+	errorFields      string
+	stackFields      string
+	includeFields    string
+	excludeFields    string
+	loggerFields     string
+	decodeFields     []string
+	statusLevel      bool
+	deriveLevel      []string
+	routes           []string
+	where            []string
+	pairBy           string
+	pairStart        string
+	pairEnd          string
+	pairTimeout      time.Duration
+	count            bool
+	countPer         time.Duration
+	sessionize       string
+	addFields        []string
+	maxFieldLength   int
+	profile          profiling
+}
+
+func cli() (opts options) {
+	argv := withEnvOptions(os.Args[1:], strings.Fields(os.Getenv("JL_OPTS")))
+	if file := pipelineFile(argv); file != "" {
+		options, files, err := readPipeline(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid pipeline: %v\n", err)
+			os.Exit(1)
+		}
+		argv = append(withEnvOptions(argv, options), files...)
+	}
+	argv, profile, err := profilingFlags(argv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid profiling flag: %v\n", err)
+		os.Exit(1)
+	}
+	opts.profile = profile
 	arguments, err := docopt.ParseArgs(usage, argv, "jl "+version)
 	if err != nil {
 		panic(err)
 	}
 	isTTY := isatty.IsTerminal(os.Stdout.Fd())
-	color = !arguments["--no-color"].(bool) && (arguments["--color"].(bool) || isTTY)
-	showPrefix = !arguments["--skip-prefix"].(bool)
-	showSuffix = !arguments["--skip-suffix"].(bool)
-	showFields = !arguments["--skip-fields"].(bool)
-	maxFieldLength, _ = strconv.Atoi(arguments["--max-field-length"].(string))
-	includeFields, _ = arguments["--include-fields"].(string)
-	excludeFields, _ = arguments["--exclude-fields"].(string)
-	files = arguments["FILE"].([]string)
+	opts.color = !arguments["--no-color"].(bool) && (arguments["--color"].(bool) || isTTY)
+	opts.showPrefix = !arguments["--skip-prefix"].(bool)
+	opts.showSuffix = !arguments["--skip-suffix"].(bool)
+	opts.showFields = !arguments["--skip-fields"].(bool)
+	opts.input, _ = arguments["--input"].(string)
+	opts.mapping, _ = arguments["--map"].(string)
+	opts.bracketLevels, _ = arguments["--bracket-levels"].(string)
+	opts.journaldLevels, _ = arguments["--journald-levels"].(string)
+	opts.journaldFacility = arguments["--journald-facility"].(bool)
+	opts.patterns, _ = arguments["--pattern"].([]string)
+	opts.decodeFields, _ = arguments["--decode-field"].([]string)
+	opts.statusLevel = arguments["--status-level"].(bool)
+	opts.deriveLevel, _ = arguments["--derive-level"].([]string)
+	opts.routes, _ = arguments["--route"].([]string)
+	opts.where, _ = arguments["--where"].([]string)
+	opts.sessionize, _ = arguments["--sessionize"].(string)
+	opts.pairBy, _ = arguments["--pair-by"].(string)
+	opts.pairStart, _ = arguments["--pair-start"].(string)
+	opts.pairEnd, _ = arguments["--pair-end"].(string)
+	if opts.pairBy != "" {
+		timeout := arguments["--pair-timeout"].(string)
+		opts.pairTimeout, err = time.ParseDuration(timeout)
+		if err != nil || opts.pairTimeout <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid pair timeout: %s\n", timeout)
+			os.Exit(1)
+		}
+	}
+	opts.addFields, _ = arguments["--add-field"].([]string)
+	opts.grokPatterns, _ = arguments["--grok-patterns"].(string)
+	opts.execFilter, _ = arguments["--exec-filter"].(string)
+	opts.errorsFile, _ = arguments["--errors-file"].(string)
+	opts.parseErrors = arguments["--parse-errors"].(bool) || opts.errorsFile != ""
+	opts.protoDesc, _ = arguments["--proto-desc"].(string)
+	opts.protoMsg, _ = arguments["--proto-msg"].(string)
+	opts.output, _ = arguments["--output"].(string)
+	opts.template, _ = arguments["--template"].(string)
+	opts.columns, _ = arguments["--columns"].(string)
+	opts.daySeparator = !arguments["--no-day-separator"].(bool)
+	opts.highlight, _ = arguments["--highlight"].(string)
+	opts.forwardSyslog, _ = arguments["--forward-syslog"].(string)
+	opts.stats = arguments["--stats"].(bool)
+	opts.callerRule, _ = arguments["--caller-style"].(string)
+	if arguments["--full-caller"].(bool) {
+		opts.callerRule = "full"
+	}
+	opts.quote, _ = arguments["--quote"].(string)
+	opts.jwt, _ = arguments["--jwt"].(string)
+	opts.enrichUA, _ = arguments["--enrich-ua"].(string)
+	opts.arrayStyle, _ = arguments["--array-style"].(string)
+	opts.maxDepth, _ = strconv.Atoi(arguments["--max-depth"].(string))
+	opts.verbosity = arguments["--verbose"].(int)
+	opts.hiddenCount = arguments["--hidden-count"].(bool)
+	opts.hiddenSummary = arguments["--hidden-summary"].(bool)
+	opts.level, _ = arguments["--level"].(string)
+	if since, ok := arguments["--since"].(string); ok {
+		opts.since, err = parseTimeBound(since, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid since: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if until, ok := arguments["--until"].(string); ok {
+		opts.until, err = parseTimeBound(until, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid until: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	opts.sample, _ = arguments["--sample"].(string)
+	opts.keepLevel, _ = arguments["--keep-level"].(string)
+	opts.stderrLevel, _ = arguments["--stderr-level"].(string)
+	opts.inferLevels = !arguments["--no-infer-level"].(bool)
+	opts.keepANSI = arguments["--keep-ansi"].(bool) && !arguments["--strip-ansi"].(bool)
+	opts.highlightValues = arguments["--highlight-values"].(bool)
+	opts.showThread = arguments["--show-thread"].(bool)
+	opts.groupByUnit = arguments["--group-by-unit"].(bool)
+	opts.followThread, _ = arguments["--follow-thread"].(string)
+	opts.module, _ = arguments["--module"].(string)
+	sourceContext, _ := arguments["--show-source-context"].(string)
+	opts.sourceContext, _ = strconv.Atoi(sourceContext)
+	opts.errorFields, _ = arguments["--error-fields"].(string)
+	opts.stackFields, _ = arguments["--stack-fields"].(string)
+	opts.maxFieldLength, _ = strconv.Atoi(arguments["--max-field-length"].(string))
+	opts.includeFields, _ = arguments["--include-fields"].(string)
+	opts.excludeFields, _ = arguments["--exclude-fields"].(string)
+	opts.loggerFields, _ = arguments["--logger-field"].(string)
+	opts.failOn, _ = arguments["--fail-on"].([]string)
+	opts.expect, _ = arguments["--expect"].([]string)
+	opts.validate, _ = arguments["--validate"].(string)
+	opts.onInvalid, _ = arguments["--on-invalid"].(string)
+	opts.report, _ = arguments["--report"].(string)
+	opts.reportFile, _ = arguments["--report-file"].(string)
+	if arguments["sqlite"].(bool) {
+		opts.export = "sqlite"
+		opts.database, _ = arguments["<database>"].(string)
+		opts.index, _ = arguments["--index"].([]string)
+	}
+	opts.indexFiles = arguments["index"].(bool)
+	opts.control, _ = arguments["--control"].(string)
+	opts.markPatterns, _ = arguments["--mark-pattern"].([]string)
+	opts.marksFile, _ = arguments["--marks-file"].(string)
+	if len(opts.markPatterns) > 0 && opts.marksFile == "" {
+		fmt.Fprintln(os.Stderr, "invalid mark pattern: --mark-pattern needs --marks-file")
+		os.Exit(1)
+	}
+	if alert, ok := arguments["--alert"].(string); ok {
+		opts.alert, err = time.ParseDuration(alert)
+		if err != nil || opts.alert <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid alert interval: %s\n", alert)
+			os.Exit(1)
+		}
+		opts.alertMin, err = strconv.Atoi(arguments["--alert-min"].(string))
+		if err != nil || opts.alertMin < 1 {
+			fmt.Fprintf(os.Stderr, "invalid alert minimum: %s\n", arguments["--alert-min"])
+			os.Exit(1)
+		}
+	}
+	if heartbeat, ok := arguments["--heartbeat"].(string); ok {
+		opts.heartbeat, err = time.ParseDuration(heartbeat)
+		if err != nil || opts.heartbeat <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid heartbeat interval: %s\n", heartbeat)
+			os.Exit(1)
+		}
+	}
+	opts.heartbeatExec, _ = arguments["--heartbeat-exec"].(string)
+	if countPer, ok := arguments["--count-per"].(string); ok {
+		opts.countPer, err = time.ParseDuration(countPer)
+		if err != nil || opts.countPer <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid count window: %s\n", countPer)
+			os.Exit(1)
+		}
+	}
+	opts.count = arguments["--count"].(bool) || opts.countPer > 0
+	maxMemory, _ := arguments["--max-memory"].(string)
+	opts.maxMemory, err = stream.ParseSize(maxMemory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid memory budget: %v\n", err)
+		os.Exit(1)
+	}
+	if arguments["ctl"].(bool) {
+		opts.ctl = true
+		switch {
+		case arguments["pause"].(bool):
+			opts.ctlCommand = []string{"pause"}
+		case arguments["resume"].(bool):
+			opts.ctlCommand = []string{"resume"}
+		case arguments["mark"].(bool):
+			notes, _ := arguments["<note>"].([]string)
+			opts.ctlCommand = append([]string{"mark"}, notes...)
+		default:
+			filters, _ := arguments["<filter>"].([]string)
+			opts.ctlCommand = append([]string{"set-filter"}, filters...)
+		}
+		if opts.control == "" {
+			opts.control = defaultControl
+		}
+	}
+	if arguments["bench"].(bool) {
+		opts.bench = true
+		opts.benchFormat, _ = arguments["--format"].(string)
+		opts.benchLines, _ = strconv.Atoi(arguments["--lines"].(string))
+	}
+	if arguments["docker"].(bool) || arguments["podman"].(bool) {
+		opts.runtime = "docker"
+		if arguments["podman"].(bool) {
+			opts.runtime = "podman"
+		}
+		opts.container, _ = arguments["<container>"].(string)
+		opts.follow = arguments["--follow"].(bool)
+	}
+	if arguments["compose"].(bool) {
+		opts.compose = true
+		opts.services, _ = arguments["<service>"].([]string)
+	}
+	if arguments["ssh"].(bool) {
+		hosts, _ := arguments["<hosts>"].(string)
+		opts.hosts = strings.Split(hosts, ",")
+		opts.command, _ = arguments["<command>"].([]string)
+	}
+	if arguments["gcloud"].(bool) {
+		opts.gcloud = true
+		opts.project, _ = arguments["--project"].(string)
+		opts.logFilter, _ = arguments["--filter"].(string)
+		opts.follow = arguments["--follow"].(bool)
+	}
+	if arguments["nats"].(bool) {
+		opts.bus = "nats"
+		opts.subject, _ = arguments["--subject"].(string)
+	}
+	if arguments["mqtt"].(bool) {
+		opts.bus = "mqtt"
+		opts.subject, _ = arguments["--topic"].(string)
+	}
+	opts.server, _ = arguments["--server"].(string)
+	opts.passwordFile, _ = arguments["--password-file"].(string)
+	if arguments["redis"].(bool) {
+		opts.bus = "redis"
+		opts.subject, _ = arguments["--stream"].(string)
+		opts.redisAddr, _ = arguments["--addr"].(string)
+		opts.redisGroup, _ = arguments["--group"].(string)
+	}
+	if arguments["serve"].(bool) {
+		opts.otlpAddr, _ = arguments["--otlp-http"].(string)
+		opts.otlpGRPCAddr, _ = arguments["--otlp-grpc"].(string)
+	}
+	if arguments["parquet"].(bool) {
+		opts.export = "parquet"
+		opts.database, _ = arguments["<file>"].(string)
+	}
+	opts.files = arguments["FILE"].([]string)
 	return
 }
+
+// withEnvOptions adds the options of JL_OPTS to the arguments, before a --
+// as what follows it isn't parsed as options.
+func withEnvOptions(args, env []string) []string {
+	argv := make([]string, 0, len(args)+len(env))
+	for i, arg := range args {
+		if arg == "--" {
+			argv = append(argv, env...)
+			return append(argv, args[i:]...)
+		}
+		argv = append(argv, arg)
+	}
+	return append(argv, env...)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koenbollen/jl/containers"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// composePoll is how often the containers of the project are listed, to
+// follow containers that are recreated or restarted.
+const composePoll = 2 * time.Second
+
+var invalidProjectName = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// composeProject returns the name of the compose project of the current
+// directory, like docker compose names it.
+func composeProject() (string, error) {
+	if name := os.Getenv("COMPOSE_PROJECT_NAME"); name != "" {
+		return name, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return invalidProjectName.ReplaceAllString(strings.ToLower(filepath.Base(dir)), ""), nil
+}
+
+// composeFollower follows the logs of the containers of a compose project,
+// every line prefixed with the colored name of its container.
+type composeFollower struct {
+	client   *containers.Client
+	project  string
+	services []string
+	colorize bool
+	output   *io.PipeWriter
+	errors   *io.PipeWriter
+
+	mu       sync.Mutex
+	attached map[string]bool
+	stopped  map[string]time.Time
+	width    int
+}
+
+// openCompose follows the logs of the running containers of the compose
+// project in the current directory, or of the given services only.
+func openCompose(services []string, colorize bool) (stream.Stream, error) {
+	project, err := composeProject()
+	if err != nil {
+		return nil, err
+	}
+	socket, err := containers.Socket(false)
+	if err != nil {
+		return nil, err
+	}
+	r, w := io.Pipe()
+	errorsR, errorsW := io.Pipe()
+	f := &composeFollower{
+		client:   containers.NewClient(socket),
+		project:  project,
+		services: services,
+		colorize: colorize,
+		output:   w,
+		errors:   errorsW,
+		attached: map[string]bool{},
+		stopped:  map[string]time.Time{},
+	}
+	for _, service := range services {
+		f.width = max(f.width, len(service)+2)
+	}
+	found, err := f.attach()
+	if err != nil && (found == 0 || fatalComposeError(err)) {
+		return nil, err
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no running containers of compose project %q", project)
+	}
+	go func() {
+		// an error is reported once, until a poll succeeds again:
+		failing := err != nil
+		if failing {
+			fmt.Fprintf(os.Stderr, "failed to follow a container of compose project %q, retrying: %v\n", project, err)
+		}
+		for range time.Tick(composePoll) {
+			_, err := f.attach()
+			if err != nil && fatalComposeError(err) {
+				w.CloseWithError(err)
+				errorsW.Close()
+				return
+			}
+			if err != nil && !failing {
+				fmt.Fprintf(os.Stderr, "failed to follow a container of compose project %q, retrying: %v\n", project, err)
+			}
+			failing = err != nil
+		}
+	}()
+	return stream.MergeOutput(stream.NewSource(r, project), stream.NewSource(errorsR, project)), nil
+}
+
+// fatalComposeError reports whether the error ends following the project,
+// like when the socket can't be used. Other errors, like a daemon that is
+// restarting or a container that is removed while it's inspected, are
+// retried at the next poll.
+func fatalComposeError(err error) bool {
+	return errors.Is(err, os.ErrPermission)
+}
+
+// attach follows the running containers that aren't followed yet and returns
+// the number of containers that are followed. The containers that fail are
+// skipped, to be attached at the next poll, and the first error is returned.
+func (f *composeFollower) attach() (int, error) {
+	ids, err := f.client.List("com.docker.compose.project=" + f.project)
+	if err != nil {
+		return 0, err
+	}
+	var attach []*containers.Container
+	var failed error
+	for _, id := range ids {
+		f.mu.Lock()
+		attached := f.attached[id]
+		f.mu.Unlock()
+		if attached {
+			continue
+		}
+		container, err := f.client.Inspect(id)
+		if err != nil {
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+		if len(f.services) > 0 && !slices.Contains(f.services, container.Config.Labels["com.docker.compose.service"]) {
+			continue
+		}
+		attach = append(attach, container)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, container := range attach {
+		f.width = max(f.width, len(f.name(container)))
+	}
+	for _, container := range attach {
+		logs, err := f.client.Logs(container, true, f.stopped[container.ID])
+		if err != nil {
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+		f.attached[container.ID] = true
+		go f.follow(container, logs)
+	}
+	return len(f.attached), failed
+}
+
+// follow copies the logs of the container until it stops.
+func (f *composeFollower) follow(container *containers.Container, logs io.ReadCloser) {
+	name := f.name(container)
+	f.mu.Lock()
+	prefix := structure.ColorName(name+strings.Repeat(" ", max(f.width-len(name), 0)), f.colorize) + " | "
+	f.mu.Unlock()
+	if container.Config.Tty {
+		_ = containers.Prefix(f.output, logs, prefix)
+	} else {
+		_ = containers.Demux(f.output, f.errors, logs, prefix)
+	}
+	logs.Close()
+	f.mu.Lock()
+	delete(f.attached, container.ID)
+	f.stopped[container.ID] = time.Now()
+	f.mu.Unlock()
+}
+
+// name returns the name of the container without the name of the project,
+// like web-1.
+func (f *composeFollower) name(container *containers.Container) string {
+	name := strings.TrimPrefix(container.Name, f.project+"-")
+	return strings.TrimPrefix(name, f.project+"_")
+}
@@ -0,0 +1,148 @@
+// Package containers reads the logs of docker and podman containers through
+// the docker engine API, which podman implements as well.
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Container is the part of the inspected container that is used to read its
+// logs.
+type Container struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Tty    bool              `json:"Tty"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// Client talks to the engine API of docker or podman.
+type Client struct {
+	http *http.Client
+	base string
+}
+
+// Socket returns the path of the unix socket of the engine API, from
+// DOCKER_HOST (or CONTAINER_HOST for podman) or the default location. Only
+// unix sockets are supported, other hosts, like tcp:// or ssh://, return an
+// error.
+func Socket(podman bool) (string, error) {
+	variable := "DOCKER_HOST"
+	if podman {
+		variable = "CONTAINER_HOST"
+	}
+	host := os.Getenv(variable)
+	if path, ok := strings.CutPrefix(host, "unix://"); ok {
+		return path, nil
+	}
+	if host != "" {
+		return "", fmt.Errorf("only unix sockets are supported in %s, not %s", variable, host)
+	}
+	if !podman {
+		return "/var/run/docker.sock", nil
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "podman", "podman.sock"), nil
+	}
+	return "/run/podman/podman.sock", nil
+}
+
+// NewClient returns a client that connects to the engine API on the unix
+// socket.
+func NewClient(socket string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{http: &http.Client{Transport: transport}, base: "http://engine"}
+}
+
+// Inspect returns the container with the given name or id.
+func (c *Client) Inspect(name string) (*Container, error) {
+	resp, err := c.get("/containers/" + url.PathEscape(name) + "/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	container := &Container{}
+	if err := json.NewDecoder(resp.Body).Decode(container); err != nil {
+		return nil, err
+	}
+	container.Name = strings.TrimPrefix(container.Name, "/")
+	return container, nil
+}
+
+// List returns the ids of the running containers with all of the labels,
+// given as key=value.
+func (c *Client) List(labels ...string) ([]string, error) {
+	filters, err := json.Marshal(map[string][]string{"label": labels})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.get("/containers/json?" + url.Values{"filters": {string(filters)}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var containers []struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(containers))
+	for i, container := range containers {
+		ids[i] = container.ID
+	}
+	return ids, nil
+}
+
+// Logs returns the output of the container since the given time (or all of
+// it for the zero time), the stdout and stderr of containers without a tty
+// are multiplexed and can be read with Demux.
+func (c *Client) Logs(container *Container, follow bool, since time.Time) (io.ReadCloser, error) {
+	query := url.Values{"stdout": {"1"}, "stderr": {"1"}}
+	if follow {
+		query.Set("follow", "1")
+	}
+	if !since.IsZero() {
+		query.Set("since", strconv.FormatInt(since.Unix(), 10))
+	}
+	resp, err := c.get("/containers/" + url.PathEscape(container.ID) + "/logs?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) get(path string) (*http.Response, error) {
+	resp, err := c.http.Get(c.base + path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var failure struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) != nil || failure.Message == "" {
+			failure.Message = resp.Status
+		}
+		return nil, fmt.Errorf("%s", failure.Message)
+	}
+	return resp, nil
+}
@@ -0,0 +1,159 @@
+package containers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// frame returns a multiplexed frame of the payload on the stream.
+func frame(stream byte, payload string) []byte {
+	header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "engine.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/json":
+			if got, want := r.URL.Query().Get("filters"), `{"label":["com.docker.compose.project=shop"]}`; got != want {
+				t.Errorf("filters = %q, want %q", got, want)
+			}
+			w.Write([]byte(`[{"Id": "4cef257c"}, {"Id": "9b199c04"}]`))
+		case "/containers/web/json":
+			w.Write([]byte(`{"Id": "4cef257c", "Name": "/web", "Config": {"Tty": false}}`))
+		case "/containers/4cef257c/logs":
+			if r.URL.Query().Get("follow") != "1" {
+				t.Errorf("follow = %q, want 1", r.URL.Query().Get("follow"))
+			}
+			w.Write(frame(Stdout, `{"msg": "listening"}`+"\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "No such container: db"}`))
+		}
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(socket)
+	container, err := client.Inspect("web")
+	if err != nil {
+		t.Fatalf("Inspect() = %v", err)
+	}
+	if got, want := container.Name, "web"; got != want {
+		t.Errorf("container.Name = %v, want %v", got, want)
+	}
+	logs, err := client.Logs(container, true, time.Time{})
+	if err != nil {
+		t.Fatalf("Logs() = %v", err)
+	}
+	defer logs.Close()
+	buf := &bytes.Buffer{}
+	if err := Demux(buf, buf, logs, "web "); err != nil {
+		t.Fatalf("Demux() = %v", err)
+	}
+	if got, want := buf.String(), "web {\"msg\": \"listening\"}\n"; got != want {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+
+	ids, err := client.List("com.docker.compose.project=shop")
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	if got, want := strings.Join(ids, ","), "4cef257c,9b199c04"; got != want {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+
+	if _, err := client.Inspect("db"); err == nil || err.Error() != "No such container: db" {
+		t.Errorf("Inspect(db) = %v, want No such container: db", err)
+	}
+}
+
+func TestSocket(t *testing.T) {
+	tests := []struct {
+		host   string
+		podman bool
+		expect string
+		err    string
+	}{
+		{"", false, "/var/run/docker.sock", ""},
+		{"unix:///tmp/docker.sock", false, "/tmp/docker.sock", ""},
+		{"tcp://10.0.0.1:2375", false, "", "only unix sockets are supported in DOCKER_HOST, not tcp://10.0.0.1:2375"},
+		{"ssh://me@build", true, "", "only unix sockets are supported in CONTAINER_HOST, not ssh://me@build"},
+	}
+	for _, tt := range tests {
+		t.Setenv("DOCKER_HOST", "")
+		t.Setenv("CONTAINER_HOST", "")
+		if tt.podman {
+			t.Setenv("CONTAINER_HOST", tt.host)
+		} else {
+			t.Setenv("DOCKER_HOST", tt.host)
+		}
+		socket, err := Socket(tt.podman)
+		if socket != tt.expect {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", socket, tt.expect)
+		}
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("Socket() = %v, want %q", err, tt.err)
+		}
+	}
+}
+
+func TestDemux(t *testing.T) {
+	t.Parallel()
+
+	var input []byte
+	input = append(input, frame(Stdout, "first li")...)
+	input = append(input, frame(Stderr, "oops\n")...)
+	input = append(input, frame(Stdout, "ne\nsecond line\nunterminated")...)
+
+	buf := &bytes.Buffer{}
+	if err := Demux(buf, buf, bytes.NewReader(input), "web "); err != nil {
+		t.Fatalf("Demux() = %v", err)
+	}
+	expect := "web oops\n" +
+		"web first line\n" +
+		"web second line\n" +
+		"web unterminated\n"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := Demux(stdout, stderr, bytes.NewReader(input), "web "); err != nil {
+		t.Fatalf("Demux() = %v", err)
+	}
+	if got, want := stderr.String(), "web oops\n"; got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+	if got, want := stdout.String(), "web first line\nweb second line\nweb unterminated\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	if err := Prefix(buf, bytes.NewReader([]byte("one\r\ntwo")), "web "); err != nil {
+		t.Fatalf("Prefix() = %v", err)
+	}
+	if got, want := buf.String(), "web one\nweb two\n"; got != want {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package containers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Stream numbers of the header of multiplexed frames.
+const (
+	Stdout = 1
+	Stderr = 2
+)
+
+// Demux copies the lines of the multiplexed stdout and stderr of a container
+// to the stdout and stderr writers, which may be the same, every line
+// prefixed with the prefix. A frame has a header of eight bytes, the stream
+// number and the big-endian size of the payload. A line can be split over
+// multiple frames, so every stream is buffered until the end of a line.
+func Demux(stdout, stderr io.Writer, r io.Reader, prefix string) error {
+	header := make([]byte, 8)
+	pending := map[byte]*bytes.Buffer{Stdout: {}, Stderr: {}}
+	writers := map[byte]io.Writer{Stdout: stdout, Stderr: stderr}
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		stream := header[0]
+		if _, ok := pending[stream]; !ok {
+			stream = Stdout
+		}
+		buf := pending[stream]
+		if _, err := io.CopyN(buf, r, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			return err
+		}
+		if err := writeLines(writers[stream], buf, prefix); err != nil {
+			return err
+		}
+	}
+	for _, stream := range []byte{Stdout, Stderr} {
+		if buf := pending[stream]; buf.Len() > 0 {
+			buf.WriteByte('\n')
+			if err := writeLines(writers[stream], buf, prefix); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Prefix copies the lines of the raw output of a container with a tty to w,
+// every line prefixed with the prefix.
+func Prefix(w io.Writer, r io.Reader, prefix string) error {
+	buf := &bytes.Buffer{}
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(chunk)
+		buf.Write(bytes.ReplaceAll(chunk[:n], []byte("\r\n"), []byte("\n")))
+		if werr := writeLines(w, buf, prefix); werr != nil {
+			return werr
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+		return writeLines(w, buf, prefix)
+	}
+	return nil
+}
+
+// writeLines writes the complete lines of the buffer, leaving the rest.
+// Every line is a single write, so the lines of multiple containers can be
+// written to the same writer.
+func writeLines(w io.Writer, buf *bytes.Buffer, prefix string) error {
+	for {
+		i := bytes.IndexByte(buf.Bytes(), '\n')
+		if i == -1 {
+			return nil
+		}
+		line := append([]byte(prefix), buf.Next(i+1)...)
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/koenbollen/jl/filter"
+	"github.com/koenbollen/jl/structure"
+)
+
+// defaultControl is the socket used by jl ctl when --control isn't given,
+// one per user so users don't take each other's socket.
+var defaultControl = filepath.Join(os.TempDir(), fmt.Sprintf("jl-%d.ctl", os.Getuid()))
+
+// filters are the filters of a running session that can be changed through
+// the control socket.
+type filters struct {
+	// minLevel is the rank of the lowest level shown, or -1 for all
+	minLevel atomic.Int32
+	// where are the expressions that entries have to match, like --where
+	where atomic.Pointer[[]*filter.Expr]
+	// grep is the regular expression that lines have to match, or nil
+	grep atomic.Pointer[regexp.Regexp]
+}
+
+// set changes a filter, given as key=value. An empty value removes the
+// filter.
+func (f *filters) set(rule string) error {
+	key, value, ok := strings.Cut(rule, "=")
+	if !ok {
+		return fmt.Errorf("%q is not a key=value filter", rule)
+	}
+	switch key {
+	case "level":
+		rank := -1
+		if value != "" && value != "all" {
+			if rank = structure.SeverityRank(value); rank == -1 {
+				return fmt.Errorf("%q is not a known level", value)
+			}
+		}
+		f.minLevel.Store(int32(rank))
+		return nil
+	case "where":
+		var where []*filter.Expr
+		if value != "" {
+			expr, err := filter.Parse(value)
+			if err != nil {
+				return err
+			}
+			where = append(where, expr)
+		}
+		f.where.Store(&where)
+		return nil
+	case "grep":
+		if value == "" {
+			f.grep.Store(nil)
+			return nil
+		}
+		grep, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		f.grep.Store(grep)
+		return nil
+	}
+	return fmt.Errorf("unknown filter %q, expected level, where or grep", key)
+}
+
+// session is the state of a running session that can be changed through the
+// control socket.
+type session struct {
+	filters filters
+	output  *pausedWriter
+	marks   *marker
+}
+
+// serveControl listens on the unix socket for commands of jl ctl, every
+// connection sends one command and receives one line of response. The
+// returned function stops listening and removes the socket. A socket left
+// behind by a session that was killed is replaced.
+func serveControl(path string, s *session) (func(), error) {
+	l, err := net.Listen("unix", path)
+	if err != nil && staleSocket(path) {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+		l, err = net.Listen("unix", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handleControl(conn, s)
+		}
+	}()
+	return func() { l.Close() }, nil
+}
+
+// staleSocket returns true when the path is a socket that nothing listens on.
+func staleSocket(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return false
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}
+	conn.Close()
+	return false
+}
+
+func handleControl(conn net.Conn, s *session) {
+	defer conn.Close()
+	command, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return
+	}
+	// the arguments are separated by tabs, so they can contain spaces:
+	args := strings.Split(strings.TrimRight(command, "\r\n"), "\t")
+	if !strings.Contains(command, "\t") {
+		args = strings.Fields(command)
+	}
+	switch {
+	case len(args) == 1 && args[0] == "pause":
+		s.output.pause()
+		fmt.Fprintln(conn, "paused")
+	case len(args) == 1 && args[0] == "resume":
+		lines, err := s.output.resume()
+		if err != nil {
+			fmt.Fprintf(conn, "error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(conn, "resumed, %d %s arrived while paused\n", lines, plural(lines, "line", "lines"))
+	case len(args) >= 2 && args[0] == "set-filter":
+		for _, filter := range args[1:] {
+			if err := s.filters.set(filter); err != nil {
+				fmt.Fprintf(conn, "error: %v\n", err)
+				return
+			}
+		}
+		fmt.Fprintln(conn, "ok")
+	case len(args) >= 2 && args[0] == "mark":
+		if s.marks == nil {
+			fmt.Fprintln(conn, "error: the session has no --marks-file to export marks to")
+			return
+		}
+		if err := s.marks.Note(strings.Join(args[1:], " ")); err != nil {
+			fmt.Fprintf(conn, "error: %v\n", err)
+			return
+		}
+		fmt.Fprintln(conn, "marked")
+	default:
+		fmt.Fprintf(conn, "error: unknown command %q, expected pause, resume, mark <note> or set-filter key=value...\n", strings.TrimSpace(command))
+	}
+}
+
+// runCtl sends a command to the control socket of a running session.
+func runCtl(w io.Writer, path string, command []string) error {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := fmt.Fprintln(conn, strings.Join(command, "\t")); err != nil {
+		return err
+	}
+	response, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if message, failed := strings.CutPrefix(strings.TrimSpace(response), "error: "); failed {
+		return errors.New(message)
+	}
+	_, err = io.WriteString(w, response)
+	return err
+}
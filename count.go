@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/koenbollen/jl/filter"
+	"github.com/koenbollen/jl/structure"
+)
+
+// parseWhere parses the expressions of --where.
+func parseWhere(texts []string) ([]*filter.Expr, error) {
+	var where []*filter.Expr
+	for _, text := range texts {
+		expr, err := filter.Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, expr)
+	}
+	return where, nil
+}
+
+// matchWhere reports whether the entry matches all expressions. Lines of
+// plain text are matched by their message.
+func matchWhere(where []*filter.Expr, raw []byte, entry *structure.Entry) bool {
+	for _, expr := range where {
+		if !expr.Match(raw, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxCountGaps is the largest number of empty windows that are written as
+// zero counts, so a few entries years apart don't print a year of zeros.
+const maxCountGaps = 10000
+
+// counter counts the entries instead of writing them, in total or per window
+// of their time.
+type counter struct {
+	per     time.Duration
+	total   int
+	windows map[time.Time]int
+	untimed int
+}
+
+func newCounter(per time.Duration) *counter {
+	return &counter{per: per, windows: map[time.Time]int{}}
+}
+
+// Add counts the entry, in the window of its time when counting per window.
+func (c *counter) Add(entry *structure.Entry) {
+	c.total++
+	if c.per == 0 {
+		return
+	}
+	if entry == nil || entry.Timestamp == nil || entry.Timestamp.IsZero() {
+		c.untimed++
+		return
+	}
+	c.windows[entry.Timestamp.Truncate(c.per)]++
+}
+
+// Write writes the total, or a line with the count of every window from the
+// first to the last, with the windows without entries as zero. Entries
+// without a time are counted on a line of their own.
+func (c *counter) Write(w io.Writer) error {
+	if c.per == 0 {
+		_, err := fmt.Fprintln(w, c.total)
+		return err
+	}
+	var starts []time.Time
+	for start := range c.windows {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	layout := c.layout()
+	for i, start := range starts {
+		if _, err := fmt.Fprintf(w, "%s  %d\n", start.Format(layout), c.windows[start]); err != nil {
+			return err
+		}
+		if i+1 == len(starts) || starts[i+1].Sub(start)/c.per > maxCountGaps {
+			continue
+		}
+		for gap := start.Add(c.per); gap.Before(starts[i+1]); gap = gap.Add(c.per) {
+			if _, err := fmt.Fprintf(w, "%s  0\n", gap.Format(layout)); err != nil {
+				return err
+			}
+		}
+	}
+	if c.untimed > 0 {
+		_, err := fmt.Fprintf(w, "no time  %d\n", c.untimed)
+		return err
+	}
+	return nil
+}
+
+// layout returns the layout of the windows, as precise as the window size.
+func (c *counter) layout() string {
+	switch {
+	case c.per%(24*time.Hour) == 0:
+		return "2006-01-02"
+	case c.per%time.Minute == 0:
+		return "2006-01-02 15:04"
+	}
+	return "2006-01-02 15:04:05"
+}
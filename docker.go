@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/koenbollen/jl/containers"
+	"github.com/koenbollen/jl/stream"
+)
+
+// openContainer reads the logs of a docker or podman container through the
+// engine API, every line prefixed with the name of the container and tagged
+// with the stream it was written to.
+func openContainer(runtime, name string, follow bool) (stream.Stream, error) {
+	socket, err := containers.Socket(runtime == "podman")
+	if err != nil {
+		return nil, err
+	}
+	client := containers.NewClient(socket)
+	container, err := client.Inspect(name)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := client.Logs(container, follow, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if container.Config.Tty {
+		// the output of a tty has stdout and stderr merged already:
+		r, w := io.Pipe()
+		go func() {
+			err := containers.Prefix(w, logs, container.Name+" ")
+			logs.Close()
+			w.CloseWithError(err)
+		}()
+		return stream.NewSource(r, container.Name), nil
+	}
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	go func() {
+		err := containers.Demux(stdoutW, stderrW, logs, container.Name+" ")
+		logs.Close()
+		stdoutW.CloseWithError(err)
+		stderrW.Close()
+	}()
+	return stream.MergeOutput(
+		stream.NewSource(stdoutR, container.Name),
+		stream.NewSource(stderrR, container.Name),
+	), nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/koenbollen/jl/stream"
+)
+
+// execFilter pipes the lines of the stream through the command, which is run
+// by the shell, and returns a stream of the lines it writes back.
+func execFilter(s stream.Stream, command string) (stream.Stream, error) {
+	cmd := shellCommand(command)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	read := make(chan error, 1)
+	go func() {
+		for line := range s.Lines() {
+			raw := append(append(make([]byte, 0, len(line.Raw)+1), line.Raw...), '\n')
+			if _, err := stdin.Write(raw); err != nil {
+				break
+			}
+		}
+		read <- s.Err()
+		stdin.Close()
+	}()
+	r, w := io.Pipe()
+	go func() {
+		_, err := io.Copy(w, stdout)
+		if werr := cmd.Wait(); err == nil && werr != nil {
+			err = fmt.Errorf("%s: %w", command, werr)
+		}
+		select {
+		case rerr := <-read:
+			if err == nil {
+				err = rerr
+			}
+		default:
+		}
+		w.CloseWithError(err)
+	}()
+	return stream.NewSource(r, command), nil
+}
+
+// shellCommand returns the command to run the command line by the shell.
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/koenbollen/jl/export"
+	"github.com/koenbollen/jl/index"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// exporter is implemented by the writers of the export package.
+type exporter interface {
+	Write(entry *structure.Entry, raw json.RawMessage) error
+	Close() error
+}
+
+// runExport ingests the input into a sqlite database or parquet file.
+func runExport(opts options) error {
+	s, err := openFiles(opts.files, decoder(opts), mappable(opts), index.Query{})
+	if err != nil {
+		return err
+	}
+	if opts.export == "parquet" {
+		return exportParquet(s, opts)
+	}
+	return exportSQLite(s, opts)
+}
+
+// exportSQLite uses the sqlite3 shell to execute the generated statements.
+func exportSQLite(s stream.Stream, opts options) error {
+	existing, err := sqliteColumns(opts.database)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("sqlite3", "-batch", "-bail", opts.database)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	db, err := export.NewSQLite(stdin, opts.index, existing)
+	if err == nil {
+		err = exportEntries(s, db)
+	}
+	return errors.Join(err, stdin.Close(), cmd.Wait())
+}
+
+// sqliteColumns returns the columns of the logs table of the database, none
+// when it doesn't have one yet.
+func sqliteColumns(database string) ([]string, error) {
+	out, err := exec.Command("sqlite3", "-batch", database, "SELECT name FROM pragma_table_info('logs');").Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, errors.New("the sqlite3 command is required to export to sqlite")
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return nil, fmt.Errorf("failed to read the logs table of %s: %s", database, strings.TrimSpace(string(exit.Stderr)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	for _, name := range strings.Split(string(out), "\n") {
+		if name != "" {
+			columns = append(columns, name)
+		}
+	}
+	return columns, nil
+}
+
+func exportParquet(s stream.Stream, opts options) error {
+	f, err := os.Create(opts.database)
+	if err != nil {
+		return err
+	}
+	var columns []string
+	if opts.columns != "" {
+		columns = strings.Split(opts.columns, ",")
+	}
+	file, err := export.NewParquet(f, columns)
+	if err == nil {
+		err = exportEntries(s, file)
+	}
+	return errors.Join(err, f.Close())
+}
+
+func exportEntries(s stream.Stream, w exporter) error {
+	for line := range s.Lines() {
+		entry, ok, err := parser.Line(line)
+		if err != nil {
+			return err
+		}
+		if err := writeEntry(w, line, entry, ok); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// dialSyslog connects to the syslog server at the given url, the returned
+// closer closes the connection.
+func dialSyslog(target string) (*export.Syslog, io.Closer, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	network, address := u.Scheme, u.Host
+	switch network {
+	case "udp", "tcp":
+		if u.Port() == "" {
+			address = net.JoinHostPort(u.Hostname(), "514")
+		}
+	case "unix":
+		network, address = "unixgram", u.Path
+	default:
+		return nil, nil, fmt.Errorf("unsupported syslog url %q, expected udp, tcp or unix", target)
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, nil, err
+	}
+	hostname, _ := os.Hostname()
+	return export.NewSyslog(conn, hostname, network == "tcp"), conn, nil
+}
+
+// writeEntry writes the entry, or the raw line when it couldn't be parsed.
+func writeEntry(w exporter, line *stream.Line, entry *structure.Entry, ok bool) error {
+	if !ok {
+		return w.Write(&structure.Entry{Message: string(line.Raw)}, nil)
+	}
+	return w.Write(entry, line.JSON)
+}
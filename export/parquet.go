@@ -0,0 +1,211 @@
+package export
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+// parquetRowGroupSize is the number of rows buffered per row group.
+const parquetRowGroupSize = 100_000
+
+// Parquet enums, as defined in parquet.thrift.
+const (
+	parquetByteArray    = 6
+	parquetOptional     = 1
+	parquetUTF8         = 0
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet writes entries as an uncompressed parquet file, every column is
+// stored as an optional string. The values of the columns are found like
+// the columns of the csv output.
+type Parquet struct {
+	output  io.Writer
+	columns []string
+	offset  int64
+	rows    [][]*string
+	groups  []parquetRowGroup
+	total   int64
+}
+
+type parquetRowGroup struct {
+	rows   int64
+	chunks []parquetChunk
+}
+
+type parquetChunk struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+// NewParquet starts a parquet file with the given columns on w.
+func NewParquet(w io.Writer, columns []string) (*Parquet, error) {
+	if len(columns) == 0 {
+		columns = structure.DefaultColumns
+	}
+	p := &Parquet{output: w, columns: columns}
+	return p, p.write(parquetMagic)
+}
+
+// Write adds a row with the columns of the entry, raw may be nil for lines
+// that couldn't be parsed.
+func (p *Parquet) Write(entry *structure.Entry, raw json.RawMessage) error {
+	row := make([]*string, len(p.columns))
+	for i, column := range p.columns {
+		if value, ok := structure.ColumnValue(column, entry, raw); ok {
+			row[i] = &value
+		}
+	}
+	p.rows = append(p.rows, row)
+	if len(p.rows) >= parquetRowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+// Close writes the remaining rows and the footer of the file.
+func (p *Parquet) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	footer := p.metadata()
+	if err := p.write(footer); err != nil {
+		return err
+	}
+	if err := p.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	return p.write(parquetMagic)
+}
+
+func (p *Parquet) write(data []byte) error {
+	n, err := p.output.Write(data)
+	p.offset += int64(n)
+	return err
+}
+
+// flush writes the buffered rows as a row group, with a single data page
+// per column.
+func (p *Parquet) flush() error {
+	if len(p.rows) == 0 {
+		return nil
+	}
+	group := parquetRowGroup{rows: int64(len(p.rows))}
+	for column := range p.columns {
+		levels := make([]bool, len(p.rows))
+		var values []byte
+		for i, row := range p.rows {
+			if row[column] != nil {
+				levels[i] = true
+				values = binary.LittleEndian.AppendUint32(values, uint32(len(*row[column])))
+				values = append(values, *row[column]...)
+			}
+		}
+		page := append(definitionLevels(levels), values...)
+
+		header := &thriftWriter{}
+		header.beginStruct()
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structField(5)
+		header.i32(1, int32(len(p.rows)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunk := parquetChunk{offset: p.offset, values: int64(len(p.rows))}
+		if err := p.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(page); err != nil {
+			return err
+		}
+		chunk.size = p.offset - chunk.offset
+		group.chunks = append(group.chunks, chunk)
+	}
+	p.groups = append(p.groups, group)
+	p.total += group.rows
+	p.rows = p.rows[:0]
+	return nil
+}
+
+// definitionLevels encodes the definition levels (1 for present values, 0
+// for nulls) with the bit-packed hybrid encoding, prefixed by its length.
+func definitionLevels(levels []bool) []byte {
+	groups := (len(levels) + 7) / 8
+	data := binary.AppendUvarint(nil, uint64(groups<<1|1))
+	packed := make([]byte, groups)
+	for i, present := range levels {
+		if present {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	data = append(data, packed...)
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(data))), data...)
+}
+
+func (p *Parquet) metadata() []byte {
+	t := &thriftWriter{}
+	t.beginStruct()
+	t.i32(1, 1)
+
+	t.list(2, thriftStruct, len(p.columns)+1)
+	t.beginStruct()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(p.columns)))
+	t.endStruct()
+	for _, column := range p.columns {
+		t.beginStruct()
+		t.i32(1, parquetByteArray)
+		t.i32(3, parquetOptional)
+		t.binary(4, column)
+		t.i32(6, parquetUTF8)
+		t.endStruct()
+	}
+
+	t.i64(3, p.total)
+
+	t.list(4, thriftStruct, len(p.groups))
+	for _, group := range p.groups {
+		t.beginStruct()
+		t.list(1, thriftStruct, len(group.chunks))
+		size := int64(0)
+		for i, chunk := range group.chunks {
+			size += chunk.size
+			t.beginStruct()
+			t.i64(2, chunk.offset)
+			t.structField(3)
+			t.i32(1, parquetByteArray)
+			t.list(2, thriftI32, 2)
+			t.listI32(parquetPlain, parquetRLE)
+			t.list(3, thriftBinary, 1)
+			t.listBinary(p.columns[i])
+			t.i32(4, parquetUncompressed)
+			t.i64(5, chunk.values)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, size)
+		t.i64(3, group.rows)
+		t.endStruct()
+	}
+
+	t.binary(6, "jl")
+	t.endStruct()
+	return t.buf.Bytes()
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestParquet(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	file, err := NewParquet(buf, []string{"level", "msg", "port"})
+	if err != nil {
+		t.Fatalf("NewParquet() = %v", err)
+	}
+	for _, logline := range []string{
+		`{"level": "info", "msg": "server started", "port": 8080}`,
+		`{"level": "error", "msg": "failed"}`,
+	} {
+		entry := &structure.Entry{}
+		djson.Unmarshal([]byte(logline), entry)
+		if err := file.Write(entry, []byte(logline)); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, parquetMagic) || !bytes.HasSuffix(data, parquetMagic) {
+		t.Fatalf("missing PAR1 magic: %q", data)
+	}
+	footer := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	metadata := data[len(data)-8-footer : len(data)-8]
+	for _, expect := range []string{"schema", "level", "msg", "port", "jl"} {
+		if !bytes.Contains(metadata, []byte(expect)) {
+			t.Errorf("metadata doesn't contain %q", expect)
+		}
+	}
+
+	// the port column has a null in the second row:
+	page := []byte{2, 0, 0, 0, 0x03, 0x01, 4, 0, 0, 0, '8', '0', '8', '0'}
+	if !bytes.Contains(data, page) {
+		t.Errorf("port column page %v not found in %v", page, data)
+	}
+}
+
+func TestDefinitionLevels(t *testing.T) {
+	t.Parallel()
+
+	levels := make([]bool, 10)
+	levels[0], levels[3], levels[9] = true, true, true
+	got := definitionLevels(levels)
+	expect := []byte{3, 0, 0, 0, 2<<1 | 1, 0b00001001, 0b00000010}
+	if !bytes.Equal(got, expect) {
+		t.Errorf("definitionLevels() = %v, want %v", got, expect)
+	}
+}
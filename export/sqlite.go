@@ -0,0 +1,134 @@
+// Package export writes log entries into files or databases for further
+// analysis, outside of the terminal.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// sqliteBatchSize is the number of rows inserted per transaction.
+const sqliteBatchSize = 1000
+
+// sqliteColumns are the columns of the logs table before the promoted ones.
+var sqliteColumns = []string{"time", "level", "msg", "fields"}
+
+// SQLite writes entries as SQL statements for the sqlite3 shell, into a
+// single logs table with a time, level, msg and a json fields column. The
+// given promoted columns are copied out of the fields into indexed columns.
+type SQLite struct {
+	output   io.Writer
+	promoted []string
+	insert   string
+	rows     int
+}
+
+// NewSQLite creates the logs table and its indices on w. The existing
+// columns are those of the logs table when the database already has one,
+// the columns it lacks are added to it.
+func NewSQLite(w io.Writer, promoted, existing []string) (*SQLite, error) {
+	s := &SQLite{output: w, promoted: promoted}
+	all := append(append([]string(nil), sqliteColumns...), promoted...)
+	var columns, definitions []string
+	for _, column := range all {
+		columns = append(columns, sqliteIdentifier(column))
+		definitions = append(definitions, strings.TrimSpace(sqliteIdentifier(column)+" "+sqliteType(column)))
+	}
+	s.insert = fmt.Sprintf("INSERT INTO logs (%s) VALUES", strings.Join(columns, ", "))
+	var statements []string
+	if len(existing) == 0 {
+		statements = append(statements, fmt.Sprintf("CREATE TABLE IF NOT EXISTS logs (%s);", strings.Join(definitions, ", ")))
+	} else {
+		have := map[string]bool{}
+		for _, column := range existing {
+			have[strings.ToLower(column)] = true
+		}
+		for i, column := range all {
+			if !have[strings.ToLower(column)] {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE logs ADD COLUMN %s;", definitions[i]))
+			}
+		}
+	}
+	indices := append([]string{"time", "level"}, promoted...)
+	for _, column := range indices {
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON logs (%s);",
+			sqliteIdentifier("logs_"+column), sqliteIdentifier(column)))
+	}
+	statements = append(statements, "BEGIN;")
+	_, err := fmt.Fprintln(w, strings.Join(statements, "\n"))
+	return s, err
+}
+
+// Write inserts the entry, raw may be nil for lines that couldn't be parsed.
+func (s *SQLite) Write(entry *structure.Entry, raw json.RawMessage) error {
+	values := []string{"NULL", sqliteString(structure.NormalizeSeverity(entry.Severity)), sqliteString(entry.Message), "NULL"}
+	if entry.Timestamp != nil {
+		values[0] = sqliteString(entry.Timestamp.UTC().Format(time.RFC3339Nano))
+	} else if entry.RawTimestamp != "" {
+		values[0] = sqliteString(entry.RawTimestamp)
+	}
+	if raw != nil {
+		values[3] = sqliteString(string(raw))
+	}
+	for _, column := range s.promoted {
+		values = append(values, sqliteValue(raw, column))
+	}
+	statement := fmt.Sprintf("%s (%s);", s.insert, strings.Join(values, ", "))
+	s.rows++
+	if s.rows%sqliteBatchSize == 0 {
+		statement += "\nCOMMIT;\nBEGIN;"
+	}
+	_, err := fmt.Fprintln(s.output, statement)
+	return err
+}
+
+// Close commits the last rows.
+func (s *SQLite) Close() error {
+	_, err := fmt.Fprintln(s.output, "COMMIT;")
+	return err
+}
+
+func sqliteValue(raw json.RawMessage, key string) string {
+	value := gjson.GetBytes(raw, key)
+	if !value.Exists() {
+		value = gjson.GetBytes(raw, strings.ReplaceAll(key, ".", `\.`))
+	}
+	switch value.Type {
+	case gjson.Null:
+		return "NULL"
+	case gjson.Number:
+		return value.Raw
+	case gjson.True:
+		return "1"
+	case gjson.False:
+		return "0"
+	case gjson.String:
+		return sqliteString(value.String())
+	}
+	return sqliteString(value.Raw)
+}
+
+func sqliteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqliteType returns the type of a column, promoted columns have none so
+// they keep the type of their json values.
+func sqliteType(column string) string {
+	for _, c := range sqliteColumns {
+		if c == column {
+			return "TEXT"
+		}
+	}
+	return ""
+}
+
+func sqliteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
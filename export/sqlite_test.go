@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestSQLite(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	db, err := NewSQLite(buf, []string{"status", "user.id"}, nil)
+	if err != nil {
+		t.Fatalf("NewSQLite() = %v", err)
+	}
+	raw := []byte(`{"time":"2023-06-16T12:51:36Z","level":"warn","msg":"can't login","status":401,"user":{"id":"u1"}}`)
+	entry := &structure.Entry{}
+	djson.Unmarshal(raw, entry)
+	if err := db.Write(entry, raw); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if err := db.Write(&structure.Entry{Message: "plain text"}, nil); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	expect := `CREATE TABLE IF NOT EXISTS logs ("time" TEXT, "level" TEXT, "msg" TEXT, "fields" TEXT, "status", "user.id");
+CREATE INDEX IF NOT EXISTS "logs_time" ON logs ("time");
+CREATE INDEX IF NOT EXISTS "logs_level" ON logs ("level");
+CREATE INDEX IF NOT EXISTS "logs_status" ON logs ("status");
+CREATE INDEX IF NOT EXISTS "logs_user.id" ON logs ("user.id");
+BEGIN;
+INSERT INTO logs ("time", "level", "msg", "fields", "status", "user.id") VALUES ('2023-06-16T12:51:36Z', 'WARNING', 'can''t login', '{"time":"2023-06-16T12:51:36Z","level":"warn","msg":"can''t login","status":401,"user":{"id":"u1"}}', 401, 'u1');
+INSERT INTO logs ("time", "level", "msg", "fields", "status", "user.id") VALUES (NULL, '', 'plain text', NULL, NULL, NULL);
+COMMIT;
+`
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match:\n%s\n\t   expect:\n%s\n", buf.String(), expect)
+	}
+}
+
+func TestSQLiteExistingTable(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	_, err := NewSQLite(buf, []string{"status", "other"}, []string{"time", "level", "msg", "fields", "status"})
+	if err != nil {
+		t.Fatalf("NewSQLite() = %v", err)
+	}
+	expect := `ALTER TABLE logs ADD COLUMN "other";
+CREATE INDEX IF NOT EXISTS "logs_time" ON logs ("time");
+CREATE INDEX IF NOT EXISTS "logs_level" ON logs ("level");
+CREATE INDEX IF NOT EXISTS "logs_status" ON logs ("status");
+CREATE INDEX IF NOT EXISTS "logs_other" ON logs ("other");
+BEGIN;
+`
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match:\n%s\n\t   expect:\n%s\n", buf.String(), expect)
+	}
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// syslogEnterpriseID is the private enterprise number reserved for
+// documentation (RFC 5612), used for the structured data id of the fields.
+const syslogEnterpriseID = 32473
+
+// syslogFacility is the user-level messages facility.
+const syslogFacility = 1
+
+var syslogSeverities = map[string]int{
+	"EMERGENCY": 0,
+	"ALERT":     1,
+	"CRITICAL":  2,
+	"FATAL":     2,
+	"PANIC":     2,
+	"ERROR":     3,
+	"WARNING":   4,
+	"NOTICE":    5,
+	"INFO":      6,
+	"DEBUG":     7,
+	"TRACE":     7,
+}
+
+// Syslog writes entries as RFC 5424 messages, with the severity mapped from
+// the level and the remaining fields as structured data. When octetCounting
+// is set every message is prefixed with its length (RFC 6587), as required
+// for stream transports like tcp, otherwise every message is a single write.
+type Syslog struct {
+	output        io.Writer
+	hostname      string
+	octetCounting bool
+}
+
+// NewSyslog creates a syslog writer, hostname is used for entries without a
+// hostname field.
+func NewSyslog(w io.Writer, hostname string, octetCounting bool) *Syslog {
+	return &Syslog{output: w, hostname: hostname, octetCounting: octetCounting}
+}
+
+// Write sends the entry as a single message, raw may be nil for lines that
+// couldn't be parsed.
+func (s *Syslog) Write(entry *structure.Entry, raw json.RawMessage) error {
+	severity, ok := syslogSeverities[structure.NormalizeSeverity(entry.Severity)]
+	if !ok {
+		severity = syslogSeverities["INFO"]
+	}
+	timestamp := "-"
+	if entry.Timestamp != nil {
+		timestamp = entry.Timestamp.UTC().Format("2006-01-02T15:04:05.999999Z07:00")
+	}
+	hostname := s.hostname
+	if value := gjson.GetBytes(raw, "hostname"); value.Type == gjson.String {
+		hostname = value.String()
+	}
+	procID := "-"
+	if value := gjson.GetBytes(raw, "pid"); value.Exists() {
+		procID = value.String()
+	}
+
+	message := fmt.Sprintf("<%d>1 %s %s %s %s - %s", syslogFacility*8+severity, timestamp,
+		syslogHeader(hostname, 255), syslogHeader(entry.Name, 48), syslogHeader(procID, 128),
+		syslogStructuredData(raw))
+	if entry.Message != "" {
+		message += " " + entry.Message
+	}
+	if s.octetCounting {
+		message = fmt.Sprintf("%d %s", len(message), message)
+	}
+	_, err := io.WriteString(s.output, message)
+	return err
+}
+
+// Close does nothing, the connection is owned by the caller.
+func (s *Syslog) Close() error {
+	return nil
+}
+
+// syslogHeader returns the value as a header field, these may only contain
+// printable ascii and use a dash for empty values.
+func syslogHeader(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !unicode.IsGraphic(r) || r == ' ' {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > max {
+		value = value[:max]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// syslogStructuredData returns the fields that aren't part of the header or
+// message as a single structured data element.
+func syslogStructuredData(raw json.RawMessage) string {
+	var params []string
+	gjson.ParseBytes(raw).ForEach(func(key, value gjson.Result) bool {
+		name := key.String()
+		if structure.IsEntryKey(name) || name == "hostname" || name == "pid" {
+			return true
+		}
+		text := value.Raw
+		if value.Type == gjson.String {
+			text = value.String()
+		}
+		params = append(params, fmt.Sprintf(`%s="%s"`, syslogParamName(name), syslogParamValue(text)))
+		return true
+	})
+	if len(params) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("[fields@%d %s]", syslogEnterpriseID, strings.Join(params, " "))
+}
+
+func syslogParamName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, syslogHeader(name, 32))
+}
+
+func syslogParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestSyslog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		logline       string
+		octetCounting bool
+		expect        string
+	}{
+		{
+			"entry",
+			`{"time":"2023-06-16T12:51:36.123Z","level":"warn","msg":"can't login","status":401,"app":"api","pid":12}`,
+			false,
+			`<12>1 2023-06-16T12:51:36.123Z host api 12 - [fields@32473 status="401"] can't login`,
+		},
+		{
+			"escaping",
+			`{"level":"fatal","msg":"boom","hostname":"web 1","query":"a=\"b]\""}`,
+			false,
+			`<10>1 - web_1 - - - [fields@32473 query="a=\"b\]\""] boom`,
+		},
+		{
+			"octet counting",
+			`{"level":"debug","msg":"hi"}`,
+			true,
+			`23 <15>1 - host - - - - hi`,
+		},
+		{
+			"raw line",
+			``,
+			false,
+			`<14>1 - host - - - - plain text`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			entry := &structure.Entry{Message: "plain text"}
+			var raw []byte
+			if test.logline != "" {
+				raw = []byte(test.logline)
+				entry = &structure.Entry{}
+				djson.Unmarshal(raw, entry)
+			}
+			if err := NewSyslog(buf, "host", test.octetCounting).Write(entry, raw); err != nil {
+				t.Fatalf("Write() = %v", err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), test.expect)
+			}
+		})
+	}
+}
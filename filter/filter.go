@@ -0,0 +1,177 @@
+// Package filter implements the small expression language used to select
+// log entries, like `level>=warn`, `status=500` or `msg=~"time(out)?"`.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// operators in order of matching, longer operators first.
+var operators = []string{"=~", "!~", ">=", "<=", "!=", "=", ">", "<"}
+
+// Expr is a single comparison of a field with a value.
+type Expr struct {
+	Key   string
+	Op    string
+	Value string
+
+	text   string
+	regexp *regexp.Regexp
+	number float64
+	isNum  bool
+}
+
+// Parse compiles an expression of the form `key OP value`, where OP is one
+// of =, !=, >, >=, <, <=, =~ (regexp match) or !~. A bare level name, like
+// `error`, is short for `level>=error`.
+func Parse(text string) (*Expr, error) {
+	text = strings.TrimSpace(text)
+	for i := range text {
+		for _, op := range operators {
+			if !strings.HasPrefix(text[i:], op) {
+				continue
+			}
+			expr := &Expr{
+				Key:   strings.TrimSpace(text[:i]),
+				Op:    op,
+				Value: unquote(strings.TrimSpace(text[i+len(op):])),
+				text:  text,
+			}
+			if expr.Key == "" {
+				return nil, fmt.Errorf("missing field in expression %q", text)
+			}
+			return expr, expr.compile()
+		}
+	}
+	if structure.SeverityRank(text) >= 0 {
+		return &Expr{Key: "level", Op: ">=", Value: text, text: text}, nil
+	}
+	return nil, fmt.Errorf("invalid expression %q, expected key=value", text)
+}
+
+func (e *Expr) compile() error {
+	if e.Op == "=~" || e.Op == "!~" {
+		re, err := regexp.Compile(e.Value)
+		if err != nil {
+			return fmt.Errorf("invalid regexp in expression %q: %w", e.text, err)
+		}
+		e.regexp = re
+	}
+	if n, err := strconv.ParseFloat(e.Value, 64); err == nil {
+		e.number, e.isNum = n, true
+	}
+	return nil
+}
+
+func (e *Expr) String() string {
+	return e.text
+}
+
+// Match evaluates the expression against the json of a log entry. The level
+// and msg keys refer to the severity and message of the entry, levels are
+// compared by their severity.
+func (e *Expr) Match(raw []byte, entry *structure.Entry) bool {
+	value, exists := e.lookup(raw, entry)
+	switch e.Op {
+	case "=~":
+		return exists && e.regexp.MatchString(value)
+	case "!~":
+		return !exists || !e.regexp.MatchString(value)
+	case "=":
+		return exists && e.equal(value)
+	case "!=":
+		return !exists || !e.equal(value)
+	}
+	if !exists {
+		return false
+	}
+	var cmp int
+	if isLevel(e.Key) {
+		left, right := structure.SeverityRank(value), structure.SeverityRank(e.Value)
+		if left < 0 || right < 0 {
+			return false
+		}
+		cmp = left - right
+	} else if e.isNum {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		cmp = e.compareNumber(strconv.FormatFloat(n, 'f', -1, 64))
+	} else {
+		cmp = strings.Compare(value, e.Value)
+	}
+	switch e.Op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+func (e *Expr) compareNumber(value string) int {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return -2
+	}
+	switch {
+	case n < e.number:
+		return -1
+	case n > e.number:
+		return 1
+	}
+	return 0
+}
+
+// equal compares the value with the value of the expression, either as
+// numbers or as text. Levels are compared by their normalized name, so
+// level=warn matches an entry logged at WARNING.
+func (e *Expr) equal(value string) bool {
+	if isLevel(e.Key) {
+		return structure.NormalizeSeverity(value) == structure.NormalizeSeverity(e.Value)
+	}
+	return value == e.Value || (e.isNum && e.compareNumber(value) == 0)
+}
+
+func (e *Expr) lookup(raw []byte, entry *structure.Entry) (string, bool) {
+	if entry != nil {
+		switch {
+		case isLevel(e.Key):
+			return structure.NormalizeSeverity(entry.Severity), entry.Severity != ""
+		case e.Key == "msg" || e.Key == "message":
+			return entry.Message, true
+		}
+	}
+	value := gjson.GetBytes(raw, e.Key)
+	if !value.Exists() {
+		value = gjson.GetBytes(raw, strings.ReplaceAll(e.Key, ".", `\.`))
+	}
+	return value.String(), value.Exists()
+}
+
+func isLevel(key string) bool {
+	return key == "level" || key == "severity"
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		if value[0] == '"' {
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
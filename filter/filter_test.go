@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{"level": "warn", "msg": "request timeout", "status": 503, "user": {"name": "john"}, "tenant": "acme"}`)
+	entry := &structure.Entry{}
+	djson.Unmarshal(raw, entry)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"level>=warn", true},
+		{"level>=error", false},
+		{"level<error", true},
+		{"level=warning", true},
+		{"level=error", false},
+		{"level!=info", true},
+		{"level!=warn", false},
+		{"severity=warn", true},
+		{"warning", true},
+		{"error", false},
+		{"status>=500", true},
+		{"status=503", true},
+		{"status!=503", false},
+		{"status<500", false},
+		{"user.name=john", true},
+		{"tenant=acme", true},
+		{"tenant!=acme", false},
+		{"missing=1", false},
+		{"missing!=1", true},
+		{`msg=~"time(out)?"`, true},
+		{`msg!~timeout`, false},
+		{`msg="request timeout"`, true},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", tt.expr, err)
+			continue
+		}
+		if got := expr.Match(raw, entry); got != tt.want {
+			t.Errorf("Parse(%q).Match() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{"", "=value", "nonsense", "msg=~("} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) = nil, want an error", text)
+		}
+	}
+}
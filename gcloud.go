@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/koenbollen/jl/stream"
+)
+
+// openCloudLogging reads the entries of Google Cloud Logging that match the
+// filter with the gcloud command, oldest first. With follow the entries are
+// tailed as they are written instead.
+func openCloudLogging(project, filter string, follow bool) (stream.Stream, error) {
+	args := []string{"logging", "read", "--order=asc"}
+	if follow {
+		args = []string{"beta", "logging", "tail"}
+	}
+	if filter != "" {
+		args = append(args, filter)
+	}
+	args = append(args, "--format=json")
+	if project != "" {
+		args = append(args, "--project="+project)
+	}
+	cmd := exec.Command("gcloud", args...)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("the gcloud command is required to read cloud logging")
+		}
+		return nil, err
+	}
+	r, w := io.Pipe()
+	go func() {
+		err := compactEntries(w, output)
+		if werr := cmd.Wait(); err == nil && werr != nil {
+			err = fmt.Errorf("gcloud: %w", werr)
+		}
+		w.CloseWithError(err)
+	}()
+	source := "cloud logging"
+	if project != "" {
+		source = project
+	}
+	return stream.NewSource(r, source), nil
+}
+
+// compactEntries writes the indented entries that gcloud outputs as lines of
+// json, a list of entries is written one entry per line.
+func compactEntries(w io.Writer, r io.Reader) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var entries []json.RawMessage
+		if json.Unmarshal(value, &entries) != nil {
+			entries = []json.RawMessage{value}
+		}
+		for _, entry := range entries {
+			buf := &bytes.Buffer{}
+			if err := json.Compact(buf, entry); err != nil {
+				return err
+			}
+			buf.WriteByte('\n')
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+}
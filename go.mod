@@ -1,17 +1,17 @@
 module github.com/koenbollen/jl
 
-go 1.21
+go 1.24
 
 require (
 	github.com/docopt/docopt-go v0.0.0-20180111231733-ee0de3bc6815
 	github.com/fatih/color v1.15.0
 	github.com/mattn/go-isatty v0.0.19
 	github.com/tidwall/gjson v1.16.0
+	golang.org/x/sys v0.11.0
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
-	golang.org/x/sys v0.11.0 // indirect
 )
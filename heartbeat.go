@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var watchdogColor = color.New(color.FgHiYellow, color.Bold)
+
+// watchdog warns when no lines arrive within the interval, as a service that
+// stops logging is often the actual incident. The warning is repeated every
+// interval of silence, the command is run once per silence.
+type watchdog struct {
+	output   io.Writer
+	interval time.Duration
+	command  string
+	colorize bool
+	beats    chan struct{}
+	done     chan struct{}
+}
+
+func newWatchdog(output io.Writer, interval time.Duration, command string, colorize bool) *watchdog {
+	w := &watchdog{
+		output:   output,
+		interval: interval,
+		command:  command,
+		colorize: colorize,
+		beats:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *watchdog) run() {
+	timer := time.NewTimer(w.interval)
+	defer timer.Stop()
+	var silence time.Duration
+	for {
+		select {
+		case <-w.beats:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			silence = 0
+		case <-timer.C:
+			silence += w.interval
+			w.warn(silence)
+		case <-w.done:
+			return
+		}
+		timer.Reset(w.interval)
+	}
+}
+
+// Beat tells the watchdog a line arrived.
+func (w *watchdog) Beat() {
+	select {
+	case w.beats <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watchdog) warn(silence time.Duration) {
+	warning := fmt.Sprintf("*** no logs for %s ***", silence)
+	if w.colorize {
+		watchdogColor.EnableColor()
+		warning = watchdogColor.Sprint(warning)
+	}
+	fmt.Fprintln(w.output, warning)
+	if w.command == "" || silence != w.interval {
+		return
+	}
+	cmd := shellCommand(w.command)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to run heartbeat command: %v\n", err)
+	}
+}
+
+// Close stops the watchdog.
+func (w *watchdog) Close() {
+	close(w.done)
+}
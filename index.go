@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/koenbollen/jl/index"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// runIndex writes the sidecar index of every file, next to it.
+func runIndex(w io.Writer, files []string) error {
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		idx, err := buildIndex(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		f, err := os.Create(file + index.Suffix)
+		if err != nil {
+			return err
+		}
+		if err := errors.Join(index.Write(f, idx), f.Close()); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: %d blocks\n", file+index.Suffix, len(idx.Blocks))
+	}
+	return nil
+}
+
+// buildIndex reads every line of the file, adding its level and minute to
+// the index.
+func buildIndex(file string) (*index.Index, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, errors.New("only regular files can be indexed")
+	}
+	s, ok := mapFile(f, file)
+	if !ok {
+		defer f.Close()
+		s = stream.NewSource(f, file)
+	}
+	idx := &index.Index{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	for line := range s.Lines() {
+		rank, minute := -1, int64(0)
+		entry, ok, err := parser.Line(line)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rank = structure.SeverityRank(entry.Severity)
+			if entry.Timestamp != nil && !entry.Timestamp.IsZero() {
+				minute = entry.Timestamp.Unix() / 60
+			}
+		}
+		idx.Add(line.Offset, line.Number, rank, minute)
+	}
+	return idx, s.Err()
+}
+
+// indexedStream returns a stream of the parts of the file that contain the
+// entries selected by the query, according to its index. The lines keep
+// their line numbers and offsets in the file. ok is false when the file has
+// no index or the index is outdated.
+func indexedStream(f *os.File, file string, query index.Query) (stream.Stream, bool) {
+	data, err := os.Open(file + index.Suffix)
+	if err != nil {
+		return nil, false
+	}
+	defer data.Close()
+	idx, err := index.Read(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ignoring index of %s: %v\n", file, err)
+		return nil, false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false
+	}
+	if !idx.Fresh(info) {
+		fmt.Fprintf(os.Stderr, "ignoring outdated index of %s, run: jl index %s\n", file, file)
+		return nil, false
+	}
+	var sections []stream.Section
+	for _, r := range idx.Ranges(query) {
+		sections = append(sections, stream.Section{Offset: r.Offset, End: r.End, Line: r.Line})
+	}
+	return stream.NewSections(f, file, sections), true
+}
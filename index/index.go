@@ -0,0 +1,216 @@
+// Package index reads and writes the sidecar index of a large log file, which
+// lets jl seek to the parts of the file that match a query instead of
+// scanning all of it.
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// header starts every index file, followed by the version.
+const header = "jlidx"
+
+const version = 3
+
+// NoLevel is the bit in Block.Levels of lines without a known level.
+const NoLevel = 15
+
+// maxBlockLines limits the number of lines in a block, so blocks of a busy
+// minute can still be skipped by level.
+const maxBlockLines = 4096
+
+// Suffix is appended to the path of a log file to get the path of its index.
+const Suffix = ".jlidx"
+
+// Block is a run of lines of the same minute.
+type Block struct {
+	// Offset is the byte offset of the first line of the block, and Line
+	// its line number
+	Offset int64
+	Line   int
+	// Minute is the unix minute of the first entry with a timestamp, or 0
+	Minute int64
+	// Levels has a bit set for the rank of every level in the block, and
+	// the NoLevel bit for lines without a level
+	Levels uint16
+	// Untimed is set when the block has lines without a timestamp, like
+	// plain text, which are shown whatever the time range
+	Untimed bool
+}
+
+// Index describes the blocks of a log file, the size and modification time
+// of the file are used to detect an outdated index.
+type Index struct {
+	Size    int64
+	ModTime int64
+	Blocks  []Block
+
+	lines int
+}
+
+// Add indexes the line at the offset with the line number, rank is the rank
+// of its level or -1 and minute its unix minute or 0.
+func (idx *Index) Add(offset int64, number int, rank int, minute int64) {
+	n := len(idx.Blocks)
+	if n == 0 || idx.lines >= maxBlockLines || (minute != 0 && idx.Blocks[n-1].Minute != 0 && minute != idx.Blocks[n-1].Minute) {
+		idx.Blocks = append(idx.Blocks, Block{Offset: offset, Line: number})
+		idx.lines = 0
+		n++
+	}
+	block := &idx.Blocks[n-1]
+	if block.Minute == 0 {
+		block.Minute = minute
+	}
+	if minute == 0 {
+		block.Untimed = true
+	}
+	if rank < 0 || rank >= NoLevel {
+		rank = NoLevel
+	}
+	block.Levels |= 1 << rank
+	idx.lines++
+}
+
+// Matches reports whether the block contains lines that are at least of the
+// given level rank, or lines without a level.
+func (b Block) Matches(minRank int) bool {
+	if minRank <= 0 || b.Levels&(1<<NoLevel) != 0 {
+		return true
+	}
+	return b.Levels>>minRank != 0
+}
+
+// Within reports whether the block contains entries between since and
+// until, a zero time leaves that side open. Blocks with lines without a
+// timestamp are never skipped.
+func (b Block) Within(since, until time.Time) bool {
+	if b.Minute == 0 || b.Untimed {
+		return true
+	}
+	if !since.IsZero() && (b.Minute+1)*60 <= since.Unix() {
+		return false
+	}
+	return until.IsZero() || b.Minute*60 <= until.Unix()
+}
+
+// Query selects the lines of at least the level rank MinRank, between Since
+// and Until. Zero values select every line.
+type Query struct {
+	MinRank      int
+	Since, Until time.Time
+}
+
+// Selective reports whether the query can skip lines.
+func (q Query) Selective() bool {
+	return q.MinRank > 0 || !q.Since.IsZero() || !q.Until.IsZero()
+}
+
+// Range is a part of the file, from the offset of its first line, which has
+// the line number, up to End.
+type Range struct {
+	Offset, End int64
+	Line        int
+}
+
+// Ranges returns the parts of the file that contain the lines selected by
+// the query. Adjacent blocks are merged into one range.
+func (idx *Index) Ranges(q Query) []Range {
+	var ranges []Range
+	for i, block := range idx.Blocks {
+		if !block.Matches(q.MinRank) || !block.Within(q.Since, q.Until) {
+			continue
+		}
+		end := idx.Size
+		if i+1 < len(idx.Blocks) {
+			end = idx.Blocks[i+1].Offset
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].End == block.Offset {
+			ranges[n-1].End = end
+			continue
+		}
+		ranges = append(ranges, Range{Offset: block.Offset, End: end, Line: block.Line})
+	}
+	return ranges
+}
+
+// Fresh reports whether the index still describes the file.
+func (idx *Index) Fresh(info os.FileInfo) bool {
+	return idx.Size == info.Size() && idx.ModTime == info.ModTime().UnixNano()
+}
+
+// Write writes the index in its compact binary form: varints of the
+// differences between consecutive blocks.
+func Write(w io.Writer, idx *Index) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	put := func(v int64) {
+		bw.Write(buf[:binary.PutVarint(buf, v)])
+	}
+	bw.WriteString(header)
+	put(version)
+	put(idx.Size)
+	put(idx.ModTime)
+	put(int64(len(idx.Blocks)))
+	var previous Block
+	for _, block := range idx.Blocks {
+		put(block.Offset - previous.Offset)
+		put(int64(block.Line - previous.Line))
+		put(block.Minute - previous.Minute)
+		put(int64(block.Levels))
+		untimed := int64(0)
+		if block.Untimed {
+			untimed = 1
+		}
+		put(untimed)
+		previous = block
+	}
+	return bw.Flush()
+}
+
+// Read reads an index written by Write.
+func Read(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(header))
+	if _, err := io.ReadFull(br, head); err != nil || string(head) != header {
+		return nil, errors.New("not a jl index")
+	}
+	var err error
+	get := func() int64 {
+		if err != nil {
+			return 0
+		}
+		var v int64
+		v, err = binary.ReadVarint(br)
+		return v
+	}
+	if v := get(); err == nil && v != version {
+		return nil, fmt.Errorf("unsupported index version %d", v)
+	}
+	idx := &Index{Size: get(), ModTime: get()}
+	count := get()
+	if err == nil && (count < 0 || count > idx.Size+1) {
+		return nil, errors.New("corrupt index")
+	}
+	var previous Block
+	for i := int64(0); i < count && err == nil; i++ {
+		block := Block{
+			Offset: previous.Offset + get(),
+			Line:   previous.Line + int(get()),
+			Minute: previous.Minute + get(),
+			Levels: uint16(get()),
+		}
+		block.Untimed = get() != 0
+		idx.Blocks = append(idx.Blocks, block)
+		previous = block
+	}
+	if err != nil {
+		return nil, fmt.Errorf("corrupt index: %w", err)
+	}
+	return idx, nil
+}
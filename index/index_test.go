@@ -0,0 +1,145 @@
+package index
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	idx := &Index{Size: 700, ModTime: 1686919896987169000}
+	idx.Add(0, 1, -1, 0)         // plain text before the first entry
+	idx.Add(100, 2, 2, 28115331) // info
+	idx.Add(200, 3, 1, 28115331) // debug
+	idx.Add(300, 4, 1, 28115332) // debug, in the next minute
+	idx.Add(400, 5, 2, 0)        // info without a timestamp
+	idx.Add(500, 6, 5, 28115333) // error
+	idx.Add(600, 7, 8, 28115333) // emergency
+
+	expected := []Block{
+		{Offset: 0, Line: 1, Minute: 28115331, Levels: 1<<NoLevel | 1<<2 | 1<<1, Untimed: true},
+		{Offset: 300, Line: 4, Minute: 28115332, Levels: 1<<1 | 1<<2, Untimed: true},
+		{Offset: 500, Line: 6, Minute: 28115333, Levels: 1<<5 | 1<<8},
+	}
+	if !reflect.DeepEqual(idx.Blocks, expected) {
+		t.Errorf("\n\tnot match: %+v\n\t   expect: %+v\n", idx.Blocks, expected)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, idx); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	read, err := Read(buf)
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+	if !reflect.DeepEqual(read.Blocks, idx.Blocks) || read.Size != idx.Size || read.ModTime != idx.ModTime {
+		t.Errorf("\n\tnot match: %+v\n\t   expect: %+v\n", read, idx)
+	}
+
+	tests := map[int][]Range{
+		0: {{0, 700, 1}},
+		2: {{0, 700, 1}},
+		4: {{0, 300, 1}, {500, 700, 6}},
+		6: {{0, 300, 1}, {500, 700, 6}},
+	}
+	for rank, expected := range tests {
+		if got := idx.Ranges(Query{MinRank: rank}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Ranges(%d) = %v, expected %v", rank, got, expected)
+		}
+	}
+
+	minute := func(m int64, s int64) time.Time { return time.Unix(m*60+s, 0) }
+	times := []struct {
+		since, until time.Time
+		expected     []Range
+	}{
+		{minute(28115333, 30), time.Time{}, []Range{{0, 700, 1}}},
+		{time.Time{}, minute(28115331, 59), []Range{{0, 500, 1}}},
+		{minute(28115334, 0), time.Time{}, []Range{{0, 500, 1}}},
+	}
+	for _, test := range times {
+		if got := idx.Ranges(Query{Since: test.since, Until: test.until}); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Ranges(%v, %v) = %v, expected %v", test.since, test.until, got, test.expected)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range []string{"", "not an index", "jlidx\x04", "jlidx\x06\x10"} {
+		if _, err := Read(bytes.NewBufferString(data)); err == nil {
+			t.Errorf("Read(%q) = nil, want error", data)
+		}
+	}
+}
+
+func TestRangesMatchFullScan(t *testing.T) {
+	t.Parallel()
+
+	// lines of 10 bytes, with a plain text or untimed line every few lines
+	// and the occasional entry that is logged out of order:
+	type line struct {
+		rank   int
+		minute int64
+	}
+	var lines []line
+	idx := &Index{}
+	for i := 0; i < 10000; i++ {
+		l := line{rank: i % 9, minute: 28115331 + int64(i/700)}
+		switch {
+		case i%13 == 0:
+			l = line{rank: -1}
+		case i%17 == 0:
+			l.minute = 0
+		case i%101 == 0:
+			l.minute -= 3
+		}
+		lines = append(lines, l)
+		idx.Add(int64(i*10), i+1, l.rank, l.minute)
+	}
+	idx.Size = int64(len(lines) * 10)
+
+	selected := func(l line, q Query) bool {
+		if l.rank >= 0 && l.rank < q.MinRank {
+			return false
+		}
+		if l.minute == 0 {
+			return true
+		}
+		if !q.Since.IsZero() && (l.minute+1)*60 <= q.Since.Unix() {
+			return false
+		}
+		return q.Until.IsZero() || l.minute*60 <= q.Until.Unix()
+	}
+	minute := func(m int64) time.Time { return time.Unix(m*60, 0) }
+	queries := []Query{
+		{MinRank: 5},
+		{Since: minute(28115335)},
+		{Until: minute(28115333)},
+		{MinRank: 4, Since: minute(28115332), Until: minute(28115340)},
+		{MinRank: 8, Since: minute(28115350)},
+	}
+	for _, q := range queries {
+		var scanned, indexed []int
+		for i, l := range lines {
+			if selected(l, q) {
+				scanned = append(scanned, i)
+			}
+		}
+		for _, r := range idx.Ranges(q) {
+			for i := int(r.Offset / 10); i < int(r.End/10); i++ {
+				if selected(lines[i], q) {
+					indexed = append(indexed, i)
+				}
+			}
+		}
+		if !reflect.DeepEqual(indexed, scanned) {
+			t.Errorf("Ranges(%+v) selects %d lines, a full scan %d", q, len(indexed), len(scanned))
+		}
+	}
+}
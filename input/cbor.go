@@ -0,0 +1,219 @@
+package input
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// cborBreak is returned when the break code of an indefinite length item
+// is read.
+var cborBreak = errors.New("unexpected cbor break")
+
+// decodeCBOR reads a single CBOR data item.
+func decodeCBOR(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := b>>5, b&0x1f
+	if b == 0xff {
+		return nil, cborBreak
+	}
+	// the additional information 28 to 30 is reserved, and 31 is only valid
+	// for the indefinite length strings, arrays and maps, like 0xdc to 0xdf
+	// which would be tags:
+	if info >= 28 && info <= 30 || info == 31 && (major < 2 || major == 6) {
+		return nil, fmt.Errorf("invalid cbor initial byte 0x%x: reserved", b)
+	}
+	if major == 7 {
+		return cborSimple(r, info)
+	}
+
+	indefinite := info == 31 && major >= 2 && major <= 5
+	var n uint64
+	if !indefinite {
+		n, err = cborArgument(r, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch major {
+	case 0:
+		return n, nil
+	case 1:
+		if n > math.MaxInt64 {
+			return -float64(n) - 1, nil
+		}
+		return -int64(n) - 1, nil
+	case 2, 3:
+		var data []byte
+		if indefinite {
+			data, err = cborChunks(r)
+		} else {
+			data, err = readN(r, n)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(data), nil
+		}
+		return data, nil
+	case 4:
+		result := []interface{}{}
+		for i := uint64(0); indefinite || i < n; i++ {
+			value, err := decodeCBOR(r)
+			if indefinite && err == cborBreak {
+				break
+			}
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	case 5:
+		result := map[interface{}]interface{}{}
+		for i := uint64(0); indefinite || i < n; i++ {
+			key, err := decodeCBOR(r)
+			if indefinite && err == cborBreak {
+				break
+			}
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			value, err := decodeCBOR(r)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			result[key] = value
+		}
+		return result, nil
+	}
+
+	// major type 6, a tagged data item:
+	value, err := decodeCBOR(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	switch n {
+	case 0:
+		if text, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+				return t, nil
+			}
+		}
+	case 1:
+		switch epoch := value.(type) {
+		case uint64:
+			return time.Unix(int64(epoch), 0), nil
+		case int64:
+			return time.Unix(epoch, 0), nil
+		case float64:
+			sec, frac := math.Modf(epoch)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+	case 55799:
+		// the self-describe tag only marks the data as CBOR
+	}
+	return value, nil
+}
+
+// cborArgument reads the argument of the initial byte, either stored in the
+// additional information itself or in the following 1, 2, 4 or 8 bytes.
+func cborArgument(r *bufio.Reader, info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info <= 27:
+		n, err := msgpackUint(r, 1<<(info-24))
+		return n, unexpectedEOF(err)
+	}
+	return 0, fmt.Errorf("invalid cbor additional information %d", info)
+}
+
+// cborChunks concatenates the chunks of an indefinite length string.
+func cborChunks(r *bufio.Reader) ([]byte, error) {
+	var data []byte
+	for {
+		chunk, err := decodeCBOR(r)
+		if err == cborBreak {
+			return data, nil
+		}
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		switch c := chunk.(type) {
+		case []byte:
+			data = append(data, c...)
+		case string:
+			data = append(data, c...)
+		default:
+			return nil, fmt.Errorf("invalid cbor string chunk %T", chunk)
+		}
+	}
+}
+
+// cborSimple reads the simple values and floats of major type 7.
+func cborSimple(r *bufio.Reader, info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 24:
+		_, err := r.ReadByte()
+		return nil, unexpectedEOF(err)
+	case 25:
+		buf, err := readN(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return float16(binary.BigEndian.Uint16(buf)), nil
+	case 26:
+		buf, err := readN(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	case 27:
+		buf, err := readN(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+	}
+	if info < 20 {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid cbor simple value %d", info)
+}
+
+// float16 converts an IEEE 754 half-precision float.
+func float16(bits uint16) float64 {
+	exp := int(bits>>10) & 0x1f
+	mant := float64(bits & 0x3ff)
+	var value float64
+	switch exp {
+	case 0:
+		value = math.Ldexp(mant, -24)
+	case 31:
+		value = math.Inf(1)
+		if mant != 0 {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mant+1024, exp-25)
+	}
+	if bits&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+package input
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NewCSV returns a reader of json lines for the rows of a csv (or with a tab
+// as comma, tsv) file. The mapping (like time=0,level=2,msg=3) names the
+// columns by their index or header. The first row is used as header, unless
+// the mapping only uses indices, columns that aren't mapped are named after
+// their header or index. Empty values are skipped.
+func NewCSV(r io.Reader, comma rune, mapping string) (io.Reader, error) {
+	names := map[string]string{}
+	indices := map[int]string{}
+	if mapping != "" {
+		for _, pair := range strings.Split(mapping, ",") {
+			key, column, ok := strings.Cut(pair, "=")
+			if !ok || key == "" || column == "" {
+				return nil, fmt.Errorf("invalid column mapping %q, expected key=column", pair)
+			}
+			if index, err := strconv.Atoi(column); err == nil {
+				indices[index] = key
+			} else {
+				names[column] = key
+			}
+		}
+	}
+
+	br := bufio.NewReader(r)
+	reader := csv.NewReader(br)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	var header []string
+	hasHeader := mapping == "" || len(names) > 0
+	return decode(br, func(*bufio.Reader) (interface{}, error) {
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		if hasHeader && header == nil {
+			header = append([]string{}, row...)
+			for i, column := range header {
+				if key, ok := names[column]; ok {
+					indices[i] = key
+				}
+			}
+			if row, err = reader.Read(); err != nil {
+				return nil, err
+			}
+		}
+		record := map[interface{}]interface{}{}
+		for i, value := range row {
+			if value == "" {
+				continue
+			}
+			key, ok := indices[i]
+			if !ok && i < len(header) {
+				key = header[i]
+			}
+			if key == "" {
+				key = strconv.Itoa(i)
+			}
+			record[key] = value
+		}
+		return record, nil
+	}), nil
+}
@@ -0,0 +1,62 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewCSV(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		comma   rune
+		mapping string
+		in      string
+		expect  string
+	}{
+		{
+			"header",
+			',',
+			"",
+			"time,level,msg\n2023-06-16T12:51:36Z,info,\"hello, world\"\n",
+			"{\"level\":\"info\",\"msg\":\"hello, world\",\"time\":\"2023-06-16T12:51:36Z\"}\n",
+		},
+		{
+			"header mapping",
+			'\t',
+			"msg=Message,level=Severity",
+			"Severity\tMessage\tHost\nerror\tdisk full\t\n",
+			"{\"level\":\"error\",\"msg\":\"disk full\"}\n",
+		},
+		{
+			"index mapping",
+			',',
+			"time=0,msg=2",
+			"2023-06-16T12:51:36Z,web1,started\n",
+			"{\"1\":\"web1\",\"msg\":\"started\",\"time\":\"2023-06-16T12:51:36Z\"}\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := NewCSV(strings.NewReader(test.in), test.comma, test.mapping)
+			if err != nil {
+				t.Fatalf("NewCSV() = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() = %v", err)
+			}
+			if string(got) != test.expect {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, test.expect)
+			}
+		})
+	}
+
+	if _, err := NewCSV(strings.NewReader(""), ',', "time"); err == nil {
+		t.Errorf("NewCSV() with an invalid mapping succeeded")
+	}
+}
@@ -0,0 +1,156 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// fluentdEventTime is the msgpack extension type of a Fluentd EventTime.
+const fluentdEventTime = 0
+
+// event is a record of the Fluentd forward protocol or a buffer chunk, the
+// tag is written before the json as the prefix of the line.
+type event struct {
+	tag    string
+	record map[interface{}]interface{}
+}
+
+// decodeFluentd reads a msgpack value and unpacks the events when it's a
+// message of the Fluentd forward protocol (in message, forward or packed
+// forward mode) or an entry of a buffer chunk.
+func decodeFluentd(r *bufio.Reader) (interface{}, error) {
+	value, err := decodeMsgpack(r)
+	if err != nil {
+		return nil, err
+	}
+	if events, ok := fluentdEvents(value); ok {
+		return events, nil
+	}
+	return value, nil
+}
+
+func fluentdEvents(value interface{}) ([]event, bool) {
+	message, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	// a buffer chunk contains [time, record] entries without a tag:
+	if len(message) == 2 {
+		if record, ok := fluentdRecord("", message[0], message[1]); ok {
+			return []event{record}, true
+		}
+	}
+
+	if len(message) < 2 || len(message) > 4 {
+		return nil, false
+	}
+	tag, ok := message[0].(string)
+	if !ok {
+		return nil, false
+	}
+
+	switch entries := message[1].(type) {
+	case []interface{}:
+		var events []event
+		for _, entry := range entries {
+			pair, ok := entry.([]interface{})
+			if !ok || len(pair) != 2 {
+				return nil, false
+			}
+			record, ok := fluentdRecord(tag, pair[0], pair[1])
+			if !ok {
+				return nil, false
+			}
+			events = append(events, record)
+		}
+		return events, true
+	case []byte, string:
+		data, ok := entries.([]byte)
+		if !ok {
+			data = []byte(entries.(string))
+		}
+		if len(message) == 3 {
+			if option, ok := message[2].(map[interface{}]interface{}); ok && option["compressed"] == "gzip" {
+				var err error
+				if data, err = gunzip(data); err != nil {
+					return nil, false
+				}
+			}
+		}
+		var events []event
+		packed := bufio.NewReader(bytes.NewReader(data))
+		for {
+			entry, err := decodeMsgpack(packed)
+			if err == io.EOF {
+				break
+			}
+			pair, ok := entry.([]interface{})
+			if err != nil || !ok || len(pair) != 2 {
+				return nil, false
+			}
+			record, ok := fluentdRecord(tag, pair[0], pair[1])
+			if !ok {
+				return nil, false
+			}
+			events = append(events, record)
+		}
+		return events, true
+	}
+
+	if len(message) >= 3 {
+		if record, ok := fluentdRecord(tag, message[1], message[2]); ok {
+			return []event{record}, true
+		}
+	}
+	return nil, false
+}
+
+// fluentdRecord adds the time of the event to the record, unless the record
+// contains a time itself. The time is either an integer of seconds or an
+// EventTime extension, with the seconds and nanoseconds.
+func fluentdRecord(tag string, timestamp, value interface{}) (event, bool) {
+	record, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return event{}, false
+	}
+	var t time.Time
+	switch v := timestamp.(type) {
+	case int64:
+		t = time.Unix(v, 0)
+	case uint64:
+		t = time.Unix(int64(v), 0)
+	case float64:
+		t = time.Unix(0, int64(v*1e9))
+	case time.Time:
+		t = v
+	case extension:
+		if v.kind != fluentdEventTime || len(v.data) != 8 {
+			return event{}, false
+		}
+		t = time.Unix(int64(binary.BigEndian.Uint32(v.data)), int64(binary.BigEndian.Uint32(v.data[4:])))
+	default:
+		return event{}, false
+	}
+	for _, key := range []string{"timestamp", "@timestamp", "time", "date", "ts"} {
+		if _, ok := record[key]; ok {
+			return event{tag, record}, true
+		}
+	}
+	record["time"] = t
+	return event{tag, record}, true
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	// a compressed chunk may consist of multiple gzip members, which the
+	// reader concatenates by default
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,193 @@
+// Package input converts binary and foreign log formats into lines of json,
+// so they can be read by a stream like any other structured log.
+package input
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// Input formats, JSON passes lines of (mixed) json and text through as is
+// and Auto detects msgpack and CBOR records, falling back to JSON. Fluentd is
+// msgpack of which the forward protocol messages and buffer chunks are
+// unpacked into their events.
+const (
+	Auto    = "auto"
+	JSON    = "json"
+	Msgpack = "msgpack"
+	Fluentd = "fluentd"
+	CBOR    = "cbor"
+	CSV     = "csv"
+	TSV     = "tsv"
+)
+
+// decoder reads the next value from the reader, io.EOF is returned when the
+// stream ends between two values.
+type decoder func(r *bufio.Reader) (interface{}, error)
+
+// NewReader returns a reader of json lines for the given input format.
+func NewReader(r io.Reader, format string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if format == Auto {
+		format = detect(br)
+	}
+	switch format {
+	case JSON:
+		return br, nil
+	case Msgpack:
+		return decode(br, decodeMsgpack), nil
+	case Fluentd:
+		return decode(br, decodeFluentd), nil
+	case CBOR:
+		return decode(br, decodeCBOR), nil
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+// detect peeks at the first bytes of the input to detect its format.
+func detect(r *bufio.Reader) string {
+	head, _ := r.Peek(3)
+	return Detect(head)
+}
+
+// Detect returns the format of input starting with the given bytes. A record
+// (a map) or a batch of records (an array) in msgpack starts with a byte that
+// can't start a line of utf-8 text, the same goes for a CBOR map or the CBOR
+// self-describe tag.
+func Detect(head []byte) string {
+	if len(head) == 0 {
+		return JSON
+	}
+	switch b := head[0]; {
+	case len(head) >= 3 && head[0] == 0xd9 && head[1] == 0xd9 && head[2] == 0xf7:
+		return CBOR
+	case b >= 0x80 && b <= 0x9f, b >= 0xdc && b <= 0xdf:
+		return Msgpack
+	case b >= 0xa0 && b <= 0xbf:
+		return CBOR
+	}
+	return JSON
+}
+
+// decode writes every decoded value as a line of json to the returned reader.
+func decode(r *bufio.Reader, next decoder) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		w := bufio.NewWriter(pw)
+		for {
+			value, err := next(r)
+			if err == io.EOF {
+				break
+			}
+			if err == nil {
+				err = writeValue(w, value)
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if r.Buffered() == 0 {
+				if err := w.Flush(); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+		}
+		pw.CloseWithError(w.Flush())
+	}()
+	return pr
+}
+
+// writeValue writes the value as a line of json, or a line per event
+// prefixed with its tag.
+func writeValue(w io.Writer, value interface{}) error {
+	events, ok := value.([]event)
+	if !ok {
+		return writeLine(w, "", value)
+	}
+	for _, e := range events {
+		if err := writeLine(w, e.tag, e.record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLine(w io.Writer, prefix string, value interface{}) error {
+	data, err := json.Marshal(jsonValue(value))
+	if err != nil {
+		return err
+	}
+	if prefix != "" {
+		data = append([]byte(prefix+": "), data...)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// jsonValue converts the decoded value into something encoding/json accepts:
+// keys become strings, bytes become text when they are valid utf-8 and
+// timestamps are formatted as RFC 3339.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			if b, ok := key.([]byte); ok {
+				key = string(b)
+			}
+			result[fmt.Sprint(key)] = jsonValue(val)
+		}
+		return result
+	case []interface{}:
+		for i, val := range v {
+			v[i] = jsonValue(val)
+		}
+		return v
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case extension:
+		return base64.StdEncoding.EncodeToString(v.data)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprint(v)
+		}
+	case float32:
+		return jsonValue(float64(v))
+	}
+	return value
+}
+
+// readN reads exactly n bytes, an io.ErrUnexpectedEOF is returned when the
+// stream ends before that.
+func readN(r *bufio.Reader, n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("length of %d bytes too large", n)
+	}
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf, err
+}
+
+// unexpectedEOF turns a plain io.EOF in the middle of a value into an
+// io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		in     string
+		expect string
+	}{
+		{
+			"json",
+			Auto,
+			"{\"msg\": \"text\"}\nplain\n",
+			"{\"msg\": \"text\"}\nplain\n",
+		},
+		{
+			"msgpack",
+			Auto,
+			"\x83\xa5level\xa4info\xa3msg\xa5hello\xa1n\xcd\x01\x00" + "\x81\xa1t\xd6\xff\x64\x8c\x5a\x48",
+			"{\"level\":\"info\",\"msg\":\"hello\",\"n\":256}\n{\"t\":\"2023-06-16T12:49:12Z\"}\n",
+		},
+		{
+			"msgpack types",
+			Msgpack,
+			"\x86\xa1a\x93\xc0\xc3\xff\xa1b\xc4\x02hi\xa1c\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00\xa1d\xd0\x80\xa1e\xd9\x03str\xa1f\xc4\x01\x80",
+			"{\"a\":[null,true,-1],\"b\":\"hi\",\"c\":1.5,\"d\":-128,\"e\":\"str\",\"f\":\"gA==\"}\n",
+		},
+		{
+			"cbor",
+			Auto,
+			"\xa3\x65level\x64warn\x63msg\x64cbor\x62ts\xc1\x1a\x64\x8c\x5a\x48",
+			"{\"level\":\"warn\",\"msg\":\"cbor\",\"ts\":\"2023-06-16T12:49:12Z\"}\n",
+		},
+		{
+			"cbor types",
+			CBOR,
+			"\xd9\xd9\xf7\xbf\x61a\x9f\x20\xf5\xf6\xff\x61b\x7f\x62he\x63llo\xff\x61c\xf9\x3e\x00\xff",
+			"{\"a\":[-1,true,null],\"b\":\"hello\",\"c\":1.5}\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := NewReader(strings.NewReader(test.in), test.format)
+			if err != nil {
+				t.Fatalf("NewReader() = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() = %v", err)
+			}
+			if string(got) != test.expect {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestNewReaderTruncated(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewReader(strings.NewReader("\x82\xa5level\xa4info\xa3msg"), Msgpack)
+	if err != nil {
+		t.Fatalf("NewReader() = %v", err)
+	}
+	if _, err := io.ReadAll(r); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadAll() = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestNewReaderCBORReserved(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"\xdc", "\xdf\x00", "\x1c", "\xa1\x61a\xdd"} {
+		r, err := NewReader(strings.NewReader(in), CBOR)
+		if err != nil {
+			t.Fatalf("NewReader() = %v", err)
+		}
+		if _, err := io.ReadAll(r); err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Errorf("ReadAll(%q) = %v, want a reserved initial byte error", in, err)
+		}
+	}
+}
+
+func TestNewReaderFluentd(t *testing.T) {
+	t.Parallel()
+
+	record := "\x81\xa3msg\xa2hi"
+	eventTime := "\xd7\x00\x64\x8c\x5a\x48\x00\x00\x00\x00"
+	packed := "\x92\xce\x64\x8c\x5a\x48" + record
+	tests := []struct {
+		name   string
+		in     string
+		expect string
+	}{
+		{
+			"message mode",
+			"\x93\xa3app" + eventTime + record,
+			"app: {\"msg\":\"hi\",\"time\":\"2023-06-16T12:49:12Z\"}\n",
+		},
+		{
+			"forward mode",
+			"\x92\xa3app\x92\x92\xce\x64\x8c\x5a\x48" + record + "\x92\x01\x82\xa3msg\xa3bye\xa2ts\xa1t",
+			"app: {\"msg\":\"hi\",\"time\":\"2023-06-16T12:49:12Z\"}\napp: {\"msg\":\"bye\",\"ts\":\"t\"}\n",
+		},
+		{
+			"packed forward mode",
+			"\x92\xa3app\xc4" + string(rune(len(packed))) + packed,
+			"app: {\"msg\":\"hi\",\"time\":\"2023-06-16T12:49:12Z\"}\n",
+		},
+		{
+			"buffer chunk",
+			packed + packed,
+			"{\"msg\":\"hi\",\"time\":\"2023-06-16T12:49:12Z\"}\n{\"msg\":\"hi\",\"time\":\"2023-06-16T12:49:12Z\"}\n",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := NewReader(strings.NewReader(test.in), Fluentd)
+			if err != nil {
+				t.Fatalf("NewReader() = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() = %v", err)
+			}
+			if string(got) != test.expect {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestNewReaderMsgpackNotFluentd(t *testing.T) {
+	t.Parallel()
+
+	// without --input fluentd, an array of a time and a map isn't an event:
+	r, err := NewReader(strings.NewReader("\x92\x01\x81\xa3msg\xa2hi"), Auto)
+	if err != nil {
+		t.Fatalf("NewReader() = %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	expect := "[1,{\"msg\":\"hi\"}]\n"
+	if string(got) != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, expect)
+	}
+}
@@ -0,0 +1,181 @@
+package input
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// msgpackTimestamp is the extension type of the msgpack timestamp.
+const msgpackTimestamp = -1
+
+// extension is the raw data of an application specific extension type.
+type extension struct {
+	kind int8
+	data []byte
+}
+
+// decodeMsgpack reads a single msgpack value.
+func decodeMsgpack(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b >= 0x80 && b <= 0x8f:
+		return msgpackMap(r, uint64(b&0x0f))
+	case b >= 0x90 && b <= 0x9f:
+		return msgpackArray(r, uint64(b&0x0f))
+	case b >= 0xa0 && b <= 0xbf:
+		return msgpackString(r, uint64(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := msgpackLength(r, b-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readN(r, n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := msgpackLength(r, b-0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return msgpackExt(r, n)
+	case 0xca:
+		buf, err := readN(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	case 0xcb:
+		buf, err := readN(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return msgpackUint(r, 1<<(b-0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		n, err := msgpackUint(r, size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return msgpackExt(r, 1<<(b-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := msgpackLength(r, b-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return msgpackString(r, n)
+	case 0xdc, 0xdd:
+		n, err := msgpackLength(r, b-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return msgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := msgpackLength(r, b-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return msgpackMap(r, n)
+	}
+	return nil, fmt.Errorf("invalid msgpack format 0x%02x", b)
+}
+
+// msgpackLength reads the length of a value, with a size of 1, 2 or 4
+// bytes for the given class 0, 1 or 2.
+func msgpackLength(r *bufio.Reader, class byte) (uint64, error) {
+	return msgpackUint(r, 1<<class)
+}
+
+func msgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	buf, err := readN(r, uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, b := range buf {
+		n = n<<8 | uint64(b)
+	}
+	return n, nil
+}
+
+func msgpackString(r *bufio.Reader, n uint64) (interface{}, error) {
+	buf, err := readN(r, n)
+	return string(buf), err
+}
+
+func msgpackArray(r *bufio.Reader, n uint64) (interface{}, error) {
+	result := []interface{}{}
+	for i := uint64(0); i < n; i++ {
+		value, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func msgpackMap(r *bufio.Reader, n uint64) (interface{}, error) {
+	result := map[interface{}]interface{}{}
+	for i := uint64(0); i < n; i++ {
+		key, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		value, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// msgpackExt reads an extension value of n bytes, timestamps are decoded and
+// any other extension is returned as is.
+func msgpackExt(r *bufio.Reader, n uint64) (interface{}, error) {
+	kind, err := r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	data, err := readN(r, n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(kind) != msgpackTimestamp {
+		return extension{int8(kind), data}, nil
+	}
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+	case 8:
+		value := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(value&0x3ffffffff), int64(value>>34)), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)), nil
+	}
+	return nil, fmt.Errorf("invalid msgpack timestamp of %d bytes", len(data))
+}
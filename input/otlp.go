@@ -0,0 +1,358 @@
+package input
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// otlpSeverities are the names of the ranges of OTLP severity numbers, every
+// name covers four numbers starting at 1.
+var otlpSeverities = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// otlpRecord is a log record of OTLP, with the attributes of its resource
+// and the name of its instrumentation scope.
+type otlpRecord struct {
+	resource       map[string]interface{}
+	scope          string
+	time           uint64
+	observedTime   uint64
+	severityNumber int64
+	severityText   string
+	body           interface{}
+	attributes     map[string]interface{}
+	traceID        []byte
+	spanID         []byte
+}
+
+// WriteOTLPLogs writes the log records of an OTLP ExportLogsServiceRequest as
+// lines of json. The request is encoded as protobuf, or as the json of
+// OTLP/HTTP when isJSON is set.
+func WriteOTLPLogs(w io.Writer, data []byte, isJSON bool) error {
+	var records []otlpRecord
+	var err error
+	if isJSON {
+		records, err = parseOTLPJSON(data)
+	} else {
+		records, err = parseOTLPProto(data)
+	}
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := writeLine(w, "", record.value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value returns the record as an entry: the body is the message (or its
+// fields when it's a map), the attributes are fields and the scope is the
+// logger.
+func (r otlpRecord) value() map[string]interface{} {
+	value := map[string]interface{}{}
+	for k, v := range r.resource {
+		value[k] = v
+	}
+	for k, v := range r.attributes {
+		value[k] = v
+	}
+	if body, ok := r.body.(map[string]interface{}); ok {
+		for k, v := range body {
+			value[k] = v
+		}
+	} else if r.body != nil {
+		value["msg"] = r.body
+	}
+	if r.time == 0 {
+		r.time = r.observedTime
+	}
+	if r.time != 0 {
+		value["time"] = time.Unix(0, int64(r.time)).UTC().Format(time.RFC3339Nano)
+	}
+	level := r.severityText
+	if level == "" && r.severityNumber > 0 && int(r.severityNumber-1)/4 < len(otlpSeverities) {
+		level = otlpSeverities[(r.severityNumber-1)/4]
+	}
+	if level != "" {
+		value["level"] = level
+	}
+	if _, ok := value["logger"]; !ok && r.scope != "" {
+		value["logger"] = r.scope
+	}
+	if len(r.traceID) > 0 {
+		value["trace_id"] = hex.EncodeToString(r.traceID)
+	}
+	if len(r.spanID) > 0 {
+		value["span_id"] = hex.EncodeToString(r.spanID)
+	}
+	return value
+}
+
+// parseOTLPProto returns the log records of a protobuf encoded
+// ExportLogsServiceRequest.
+func parseOTLPProto(data []byte) ([]otlpRecord, error) {
+	var records []otlpRecord
+	err := protoFields(data, func(number, wire, _ uint64, resourceLogs []byte) error {
+		if number != 1 || wire != wireLen {
+			return nil
+		}
+		resource := map[string]interface{}{}
+		var scopeLogs [][]byte
+		err := protoFields(resourceLogs, func(number, wire, _ uint64, data []byte) error {
+			switch {
+			case number == 1 && wire == wireLen:
+				return protoFields(data, func(number, wire, _ uint64, data []byte) error {
+					if number == 1 && wire == wireLen {
+						return protoKeyValue(data, resource)
+					}
+					return nil
+				})
+			case number == 2 && wire == wireLen:
+				scopeLogs = append(scopeLogs, data)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, scopeLog := range scopeLogs {
+			var scope string
+			err := protoFields(scopeLog, func(number, wire, _ uint64, data []byte) error {
+				switch {
+				case number == 1 && wire == wireLen:
+					return protoFields(data, func(number, wire, _ uint64, data []byte) error {
+						if number == 1 && wire == wireLen {
+							scope = string(data)
+						}
+						return nil
+					})
+				case number == 2 && wire == wireLen:
+					record, err := protoLogRecord(data)
+					if err != nil {
+						return err
+					}
+					record.resource, record.scope = resource, scope
+					records = append(records, record)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+func protoLogRecord(data []byte) (otlpRecord, error) {
+	record := otlpRecord{attributes: map[string]interface{}{}}
+	err := protoFields(data, func(number, wire, value uint64, data []byte) error {
+		var err error
+		switch {
+		case number == 1 && wire == wireI64:
+			record.time = binary.LittleEndian.Uint64(data)
+		case number == 11 && wire == wireI64:
+			record.observedTime = binary.LittleEndian.Uint64(data)
+		case number == 2 && wire == wireVarint:
+			record.severityNumber = int64(value)
+		case number == 3 && wire == wireLen:
+			record.severityText = string(data)
+		case number == 5 && wire == wireLen:
+			record.body, err = protoAnyValue(data)
+		case number == 6 && wire == wireLen:
+			err = protoKeyValue(data, record.attributes)
+		case number == 9 && wire == wireLen:
+			record.traceID = data
+		case number == 10 && wire == wireLen:
+			record.spanID = data
+		}
+		return err
+	})
+	return record, err
+}
+
+// protoKeyValue adds the KeyValue to the map.
+func protoKeyValue(data []byte, values map[string]interface{}) error {
+	var key string
+	var value interface{}
+	err := protoFields(data, func(number, wire, _ uint64, data []byte) error {
+		var err error
+		switch {
+		case number == 1 && wire == wireLen:
+			key = string(data)
+		case number == 2 && wire == wireLen:
+			value, err = protoAnyValue(data)
+		}
+		return err
+	})
+	values[key] = value
+	return err
+}
+
+func protoAnyValue(data []byte) (interface{}, error) {
+	var result interface{}
+	err := protoFields(data, func(number, wire, value uint64, data []byte) error {
+		switch number {
+		case 1:
+			result = string(data)
+		case 2:
+			result = value != 0
+		case 3:
+			result = int64(value)
+		case 4:
+			if wire == wireI64 {
+				result = math.Float64frombits(binary.LittleEndian.Uint64(data))
+			}
+		case 5:
+			values := []interface{}{}
+			err := protoFields(data, func(number, wire, _ uint64, data []byte) error {
+				if number != 1 || wire != wireLen {
+					return nil
+				}
+				value, err := protoAnyValue(data)
+				values = append(values, value)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			result = values
+		case 6:
+			values := map[string]interface{}{}
+			err := protoFields(data, func(number, wire, _ uint64, data []byte) error {
+				if number == 1 && wire == wireLen {
+					return protoKeyValue(data, values)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			result = values
+		case 7:
+			result = base64.StdEncoding.EncodeToString(data)
+		}
+		return nil
+	})
+	return result, err
+}
+
+type otlpJSONKeyValue struct {
+	Key   string        `json:"key"`
+	Value otlpJSONValue `json:"value"`
+}
+
+type otlpJSONValue struct {
+	StringValue *string     `json:"stringValue"`
+	BoolValue   *bool       `json:"boolValue"`
+	IntValue    json.Number `json:"intValue"`
+	DoubleValue *float64    `json:"doubleValue"`
+	ArrayValue  *struct {
+		Values []otlpJSONValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []otlpJSONKeyValue `json:"values"`
+	} `json:"kvlistValue"`
+	BytesValue *string `json:"bytesValue"`
+}
+
+type otlpJSONRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpJSONKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			LogRecords []struct {
+				TimeUnixNano         json.Number        `json:"timeUnixNano"`
+				ObservedTimeUnixNano json.Number        `json:"observedTimeUnixNano"`
+				SeverityNumber       int64              `json:"severityNumber"`
+				SeverityText         string             `json:"severityText"`
+				Body                 *otlpJSONValue     `json:"body"`
+				Attributes           []otlpJSONKeyValue `json:"attributes"`
+				TraceID              string             `json:"traceId"`
+				SpanID               string             `json:"spanId"`
+			} `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+// parseOTLPJSON returns the log records of an ExportLogsServiceRequest in the
+// json encoding of OTLP/HTTP, with its 64 bit integers as strings and its ids
+// in hex.
+func parseOTLPJSON(data []byte) ([]otlpRecord, error) {
+	var request otlpJSONRequest
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&request); err != nil {
+		return nil, err
+	}
+	var records []otlpRecord
+	for _, resourceLogs := range request.ResourceLogs {
+		resource := jsonKeyValues(resourceLogs.Resource.Attributes)
+		for _, scopeLogs := range resourceLogs.ScopeLogs {
+			for _, r := range scopeLogs.LogRecords {
+				record := otlpRecord{
+					resource:       resource,
+					scope:          scopeLogs.Scope.Name,
+					severityNumber: r.SeverityNumber,
+					severityText:   r.SeverityText,
+					attributes:     jsonKeyValues(r.Attributes),
+				}
+				record.time, _ = strconv.ParseUint(r.TimeUnixNano.String(), 10, 64)
+				record.observedTime, _ = strconv.ParseUint(r.ObservedTimeUnixNano.String(), 10, 64)
+				if r.Body != nil {
+					record.body = r.Body.value()
+				}
+				record.traceID, _ = hex.DecodeString(r.TraceID)
+				record.spanID, _ = hex.DecodeString(r.SpanID)
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+func jsonKeyValues(keyValues []otlpJSONKeyValue) map[string]interface{} {
+	values := make(map[string]interface{}, len(keyValues))
+	for _, kv := range keyValues {
+		values[kv.Key] = kv.Value.value()
+	}
+	return values
+}
+
+func (v otlpJSONValue) value() interface{} {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != "":
+		if i, err := v.IntValue.Int64(); err == nil {
+			return i
+		}
+		return v.IntValue.String()
+	case v.DoubleValue != nil:
+		return jsonValue(*v.DoubleValue)
+	case v.ArrayValue != nil:
+		values := make([]interface{}, len(v.ArrayValue.Values))
+		for i, value := range v.ArrayValue.Values {
+			values[i] = value.value()
+		}
+		return values
+	case v.KvlistValue != nil:
+		return jsonKeyValues(v.KvlistValue.Values)
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package input
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWriteOTLPLogs(t *testing.T) {
+	t.Parallel()
+
+	str := func(s string) []byte { return pb(1, s) }
+	keyValue := func(key string, value []byte) []byte { return pb(1, key, 2, value) }
+	request := pb(1, pb(
+		1, pb(1, keyValue("service.name", str("checkout"))),
+		2, pb(
+			1, pb(1, "io.opentelemetry.payments"),
+			2, pb(
+				1, math.Float64frombits(1686919896123000000),
+				2, uint64(13),
+				5, str("card declined"),
+				6, keyValue("attempt", pb(3, uint64(2))),
+				6, keyValue("amount", pb(4, 12.5)),
+				9, []byte{0x4b, 0xf9, 0x2f, 0x35},
+			),
+			2, pb(
+				11, math.Float64frombits(1686919897000000000),
+				3, "Information",
+				5, pb(6, pb(1, keyValue("msg", str("refunded")), 1, keyValue("ok", pb(2, uint64(1))))),
+			),
+		),
+	))
+	buf := &bytes.Buffer{}
+	if err := WriteOTLPLogs(buf, request, false); err != nil {
+		t.Fatalf("WriteOTLPLogs() = %v", err)
+	}
+	expect := `{"amount":12.5,"attempt":2,"level":"WARN","logger":"io.opentelemetry.payments","msg":"card declined","service.name":"checkout","time":"2023-06-16T12:51:36.123Z","trace_id":"4bf92f35"}` + "\n" +
+		`{"level":"Information","logger":"io.opentelemetry.payments","msg":"refunded","ok":true,"service.name":"checkout","time":"2023-06-16T12:51:37Z"}` + "\n"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+
+	json := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},` +
+		`"scopeLogs":[{"scope":{"name":"payments"},"logRecords":[{"timeUnixNano":"1686919896123000000","severityNumber":17,` +
+		`"body":{"stringValue":"card declined"},"attributes":[{"key":"attempt","value":{"intValue":"2"}}],"traceId":"4bf92f35"}]}]}]}`
+	buf.Reset()
+	if err := WriteOTLPLogs(buf, []byte(json), true); err != nil {
+		t.Fatalf("WriteOTLPLogs() = %v", err)
+	}
+	expect = `{"attempt":2,"level":"ERROR","logger":"payments","msg":"card declined","service.name":"checkout","time":"2023-06-16T12:51:36.123Z","trace_id":"4bf92f35"}` + "\n"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+}
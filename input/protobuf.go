@@ -0,0 +1,425 @@
+package input
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireLen    = 2
+	wireSGroup = 3
+	wireEGroup = 4
+	wireI32    = 5
+)
+
+// Field types and labels of a FieldDescriptorProto.
+const (
+	protoDouble   = 1
+	protoFloat    = 2
+	protoInt64    = 3
+	protoUint64   = 4
+	protoInt32    = 5
+	protoFixed64  = 6
+	protoFixed32  = 7
+	protoBool     = 8
+	protoString   = 9
+	protoGroup    = 10
+	protoMessage  = 11
+	protoBytes    = 12
+	protoUint32   = 13
+	protoEnum     = 14
+	protoSfixed32 = 15
+	protoSfixed64 = 16
+	protoSint32   = 17
+	protoSint64   = 18
+
+	protoRepeated = 3
+)
+
+type protoField struct {
+	name     string
+	kind     uint64
+	repeated bool
+	typeName string
+}
+
+type protoType struct {
+	fields   map[uint64]*protoField
+	mapEntry bool
+}
+
+// protoSchema holds the messages and enums of a descriptor set, by their
+// fully qualified names (with a leading dot, like protoc's type names).
+type protoSchema struct {
+	messages map[string]*protoType
+	enums    map[string]map[int64]string
+}
+
+// NewProtobuf returns a reader of json lines for a stream of length
+// delimited protobuf messages of the given type, described by the
+// serialized FileDescriptorSet (as created by protoc --descriptor_set_out).
+func NewProtobuf(r io.Reader, descriptorSet []byte, message string) (io.Reader, error) {
+	schema, err := parseDescriptorSet(descriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	name := "." + strings.TrimPrefix(message, ".")
+	if _, ok := schema.messages[name]; !ok {
+		return nil, fmt.Errorf("message %q not found in descriptor set", message)
+	}
+	return decode(bufio.NewReader(r), func(r *bufio.Reader) (interface{}, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readN(r, n)
+		if err != nil {
+			return nil, err
+		}
+		return schema.decode(name, data)
+	}), nil
+}
+
+// protoFields iterates over the fields of an encoded message, calling fn
+// with the field number, wire type, and the varint value or the data of the
+// field.
+func protoFields(data []byte, fn func(number, wire, value uint64, data []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("invalid tag")
+		}
+		data = data[n:]
+		number, wire := tag>>3, tag&7
+		var value uint64
+		var field []byte
+		switch wire {
+		case wireVarint:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("invalid varint")
+			}
+		case wireI64, wireI32:
+			n = 8
+			if wire == wireI32 {
+				n = 4
+			}
+			if len(data) < n {
+				return io.ErrUnexpectedEOF
+			}
+			field = data[:n]
+		case wireLen:
+			length, m := binary.Uvarint(data)
+			if m <= 0 || length > uint64(len(data)-m) {
+				return io.ErrUnexpectedEOF
+			}
+			field, n = data[m:m+int(length)], m+int(length)
+		case wireSGroup, wireEGroup:
+			// groups are deprecated, their fields are read (and ignored) as
+			// fields of the enclosing message
+			n = 0
+		default:
+			return fmt.Errorf("invalid wire type %d", wire)
+		}
+		data = data[n:]
+		if err := fn(number, wire, value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseDescriptorSet(data []byte) (*protoSchema, error) {
+	schema := &protoSchema{messages: map[string]*protoType{}, enums: map[string]map[int64]string{}}
+	// the well known types are often not included in the set:
+	for _, name := range []string{".google.protobuf.Timestamp", ".google.protobuf.Duration"} {
+		schema.messages[name] = &protoType{fields: map[uint64]*protoField{
+			1: {name: "seconds", kind: protoInt64},
+			2: {name: "nanos", kind: protoInt32},
+		}}
+	}
+	err := protoFields(data, func(number, wire, _ uint64, file []byte) error {
+		if number != 1 || wire != wireLen {
+			return nil
+		}
+		var pkg string
+		var messages, enums [][]byte
+		err := protoFields(file, func(number, wire, _ uint64, data []byte) error {
+			switch {
+			case number == 2 && wire == wireLen:
+				pkg = string(data)
+			case number == 4 && wire == wireLen:
+				messages = append(messages, data)
+			case number == 5 && wire == wireLen:
+				enums = append(enums, data)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		scope := ""
+		if pkg != "" {
+			scope = "." + pkg
+		}
+		for _, message := range messages {
+			if err := schema.addMessage(scope, message); err != nil {
+				return err
+			}
+		}
+		for _, enum := range enums {
+			if err := schema.addEnum(scope, enum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return schema, err
+}
+
+func (s *protoSchema) addMessage(scope string, data []byte) error {
+	message := &protoType{fields: map[uint64]*protoField{}}
+	var name string
+	var nested, enums [][]byte
+	err := protoFields(data, func(number, wire, _ uint64, data []byte) error {
+		if wire != wireLen {
+			return nil
+		}
+		switch number {
+		case 1:
+			name = string(data)
+		case 2:
+			field := &protoField{}
+			var fieldNumber uint64
+			err := protoFields(data, func(number, wire, value uint64, data []byte) error {
+				switch number {
+				case 1:
+					field.name = string(data)
+				case 3:
+					fieldNumber = value
+				case 4:
+					field.repeated = value == protoRepeated
+				case 5:
+					field.kind = value
+				case 6:
+					field.typeName = string(data)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			message.fields[fieldNumber] = field
+		case 3:
+			nested = append(nested, data)
+		case 4:
+			enums = append(enums, data)
+		case 7:
+			// MessageOptions, with the map_entry option as field 7:
+			return protoFields(data, func(number, wire, value uint64, _ []byte) error {
+				if number == 7 && wire == wireVarint {
+					message.mapEntry = value != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fullName := scope + "." + name
+	s.messages[fullName] = message
+	for _, data := range nested {
+		if err := s.addMessage(fullName, data); err != nil {
+			return err
+		}
+	}
+	for _, data := range enums {
+		if err := s.addEnum(fullName, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *protoSchema) addEnum(scope string, data []byte) error {
+	var name string
+	values := map[int64]string{}
+	err := protoFields(data, func(number, wire, _ uint64, data []byte) error {
+		switch {
+		case number == 1 && wire == wireLen:
+			name = string(data)
+		case number == 2 && wire == wireLen:
+			var valueName string
+			var valueNumber int64
+			err := protoFields(data, func(number, _, value uint64, data []byte) error {
+				switch number {
+				case 1:
+					valueName = string(data)
+				case 2:
+					valueNumber = int64(int32(value))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			values[valueNumber] = valueName
+		}
+		return nil
+	})
+	s.enums[scope+"."+name] = values
+	return err
+}
+
+// decode converts an encoded message into a map, using the original field
+// names as keys. Unknown fields are skipped.
+func (s *protoSchema) decode(name string, data []byte) (map[interface{}]interface{}, error) {
+	message := s.messages[name]
+	result := map[interface{}]interface{}{}
+	err := protoFields(data, func(number, wire, value uint64, data []byte) error {
+		field, ok := message.fields[number]
+		if !ok || wire == wireSGroup || wire == wireEGroup {
+			return nil
+		}
+		var values []interface{}
+		if wire == wireLen && field.kind != protoString && field.kind != protoBytes && field.kind != protoMessage {
+			// a packed repeated field of scalars:
+			for len(data) > 0 {
+				v, n, err := s.scalar(field, data)
+				if err != nil {
+					return err
+				}
+				values, data = append(values, v), data[n:]
+			}
+		} else {
+			v, err := s.value(field, wire, value, data)
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+		}
+
+		if entry, ok := s.messages[field.typeName]; ok && entry.mapEntry {
+			m, _ := result[field.name].(map[interface{}]interface{})
+			if m == nil {
+				m = map[interface{}]interface{}{}
+				result[field.name] = m
+			}
+			for _, v := range values {
+				kv := v.(map[interface{}]interface{})
+				m[fmt.Sprint(kv["key"])] = kv["value"]
+			}
+			return nil
+		}
+		if field.repeated {
+			list, _ := result[field.name].([]interface{})
+			result[field.name] = append(list, values...)
+			return nil
+		}
+		result[field.name] = values[len(values)-1]
+		return nil
+	})
+	return result, err
+}
+
+// value converts a single, non-packed field.
+func (s *protoSchema) value(field *protoField, wire, value uint64, data []byte) (interface{}, error) {
+	switch field.kind {
+	case protoString:
+		return string(data), nil
+	case protoBytes:
+		return data, nil
+	case protoMessage, protoGroup:
+		if _, ok := s.messages[field.typeName]; !ok {
+			return data, nil
+		}
+		message, err := s.decode(field.typeName, data)
+		if err != nil {
+			return nil, err
+		}
+		return wellKnown(field.typeName, message), nil
+	}
+	if wire == wireVarint {
+		var buf [binary.MaxVarintLen64]byte
+		data = buf[:binary.PutUvarint(buf[:], value)]
+	}
+	v, _, err := s.scalar(field, data)
+	return v, err
+}
+
+// scalar reads a single scalar value from the start of data, returning the
+// number of bytes read.
+func (s *protoSchema) scalar(field *protoField, data []byte) (interface{}, int, error) {
+	switch field.kind {
+	case protoDouble, protoFixed64, protoSfixed64:
+		if len(data) < 8 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		bits := binary.LittleEndian.Uint64(data)
+		switch field.kind {
+		case protoDouble:
+			return math.Float64frombits(bits), 8, nil
+		case protoSfixed64:
+			return int64(bits), 8, nil
+		}
+		return bits, 8, nil
+	case protoFloat, protoFixed32, protoSfixed32:
+		if len(data) < 4 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		bits := binary.LittleEndian.Uint32(data)
+		switch field.kind {
+		case protoFloat:
+			return float64(math.Float32frombits(bits)), 4, nil
+		case protoSfixed32:
+			return int64(int32(bits)), 4, nil
+		}
+		return uint64(bits), 4, nil
+	}
+
+	value, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, 0, errors.New("invalid varint")
+	}
+	switch field.kind {
+	case protoInt64:
+		return int64(value), n, nil
+	case protoInt32:
+		return int64(int32(value)), n, nil
+	case protoSint32, protoSint64:
+		return int64(value>>1) ^ -int64(value&1), n, nil
+	case protoBool:
+		return value != 0, n, nil
+	case protoEnum:
+		if name, ok := s.enums[field.typeName][int64(int32(value))]; ok {
+			return name, n, nil
+		}
+		return int64(int32(value)), n, nil
+	}
+	return value, n, nil
+}
+
+// wellKnown converts the well known timestamp and duration types into their
+// json representation.
+func wellKnown(name string, message map[interface{}]interface{}) interface{} {
+	seconds, _ := message["seconds"].(int64)
+	nanos, _ := message["nanos"].(int64)
+	switch name {
+	case ".google.protobuf.Timestamp":
+		return time.Unix(seconds, nanos)
+	case ".google.protobuf.Duration":
+		return (time.Duration(seconds)*time.Second + time.Duration(nanos)).String()
+	}
+	return message
+}
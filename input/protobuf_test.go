@@ -0,0 +1,106 @@
+package input
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// pb encodes the given fields as a protobuf message, values are either a
+// uint64 (varint), a string or []byte (length delimited).
+func pb(fields ...interface{}) []byte {
+	var buf []byte
+	for i := 0; i < len(fields); i += 2 {
+		number := uint64(fields[i].(int))
+		switch v := fields[i+1].(type) {
+		case uint64:
+			buf = binary.AppendUvarint(buf, number<<3|wireVarint)
+			buf = binary.AppendUvarint(buf, v)
+		case float64:
+			buf = binary.AppendUvarint(buf, number<<3|wireI64)
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+		case string:
+			buf = binary.AppendUvarint(buf, number<<3|wireLen)
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+			buf = append(buf, v...)
+		case []byte:
+			buf = binary.AppendUvarint(buf, number<<3|wireLen)
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+			buf = append(buf, v...)
+		}
+	}
+	return buf
+}
+
+func field(name string, number, kind uint64, typeName string, repeated bool) []byte {
+	label := uint64(1)
+	if repeated {
+		label = protoRepeated
+	}
+	return pb(1, name, 3, number, 4, label, 5, kind, 6, typeName)
+}
+
+func TestNewProtobuf(t *testing.T) {
+	t.Parallel()
+
+	descriptorSet := pb(1, pb(
+		1, "logs.proto",
+		2, "mycorp",
+		4, pb(
+			1, "LogRecord",
+			2, field("time", 1, protoMessage, ".google.protobuf.Timestamp", false),
+			2, field("level", 2, protoEnum, ".mycorp.LogRecord.Level", false),
+			2, field("msg", 3, protoString, "", false),
+			2, field("latency", 4, protoDouble, "", false),
+			2, field("codes", 5, protoSint32, "", true),
+			2, field("labels", 6, protoMessage, ".mycorp.LogRecord.LabelsEntry", true),
+			3, pb(
+				1, "LabelsEntry",
+				2, field("key", 1, protoString, "", false),
+				2, field("value", 2, protoString, "", false),
+				7, pb(7, uint64(1)),
+			),
+			4, pb(
+				1, "Level",
+				2, pb(1, "INFO", 2, uint64(0)),
+				2, pb(1, "ERROR", 2, uint64(1)),
+			),
+		),
+	))
+
+	record := pb(
+		1, pb(1, uint64(1686919896), 2, uint64(5e8)),
+		2, uint64(1),
+		3, "request failed",
+		4, 0.25,
+		5, []byte{0x01, 0x04},
+		6, pb(1, "region", 2, "eu"),
+		6, pb(1, "zone", 2, "b"),
+		99, "unknown",
+	)
+	var stream []byte
+	stream = binary.AppendUvarint(stream, uint64(len(record)))
+	stream = append(stream, record...)
+	stream = append(stream, 0)
+
+	r, err := NewProtobuf(strings.NewReader(string(stream)), descriptorSet, "mycorp.LogRecord")
+	if err != nil {
+		t.Fatalf("NewProtobuf() = %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	expect := `{"codes":[-1,2],"labels":{"region":"eu","zone":"b"},"latency":0.25,"level":"ERROR","msg":"request failed","time":"2023-06-16T12:51:36.5Z"}
+{}
+`
+	if string(got) != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, expect)
+	}
+
+	if _, err := NewProtobuf(strings.NewReader(""), descriptorSet, "mycorp.Missing"); err == nil {
+		t.Errorf("NewProtobuf() with an unknown message succeeded")
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/koenbollen/jl/stream"
+)
+
+// interrupts handles interrupts, like ctrl-c or SIGTERM. Once the lines are
+// read, an interrupt ends the session like the input ended, so the held back
+// entries, counts and marks are written and the control socket is removed.
+// Before that, while opening the input may block, it runs the cleanup
+// functions and exits right away. A second interrupt exits right away too.
+type interrupts struct {
+	signals chan os.Signal
+	done    chan struct{}
+
+	mu      sync.Mutex
+	reading bool
+	cleanup []func()
+}
+
+func notifyInterrupts() *interrupts {
+	i := &interrupts{signals: make(chan os.Signal, 1), done: make(chan struct{})}
+	signal.Notify(i.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-i.signals
+		signal.Stop(i.signals)
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		if !i.reading {
+			for _, cleanup := range i.cleanup {
+				cleanup()
+			}
+			os.Exit(130)
+		}
+		close(i.done)
+	}()
+	return i
+}
+
+// onExit adds a function to run when jl is interrupted before it reads the
+// lines.
+func (i *interrupts) onExit(cleanup func()) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.cleanup = append(i.cleanup, cleanup)
+}
+
+// until passes on the lines until the input ends or jl is interrupted.
+func (i *interrupts) until(lines <-chan *stream.Line) <-chan *stream.Line {
+	i.mu.Lock()
+	i.reading = true
+	i.mu.Unlock()
+	out := make(chan *stream.Line)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					return
+				}
+				select {
+				case out <- line:
+				case <-i.done:
+					return
+				}
+			case <-i.done:
+				return
+			}
+		}
+	}()
+	return out
+}
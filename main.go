@@ -1,108 +1,805 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"os"
+	"sort"
 	"strings"
-	"time"
 
-	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/checks"
+	"github.com/koenbollen/jl/export"
+	"github.com/koenbollen/jl/index"
+	"github.com/koenbollen/jl/input"
+	"github.com/koenbollen/jl/objects"
+	"github.com/koenbollen/jl/parse"
+	"github.com/koenbollen/jl/parsers"
 	"github.com/koenbollen/jl/processors"
 	"github.com/koenbollen/jl/stream"
 	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
 
 	_ "github.com/koenbollen/jl/structure/stacktracers"
 )
 
 func main() {
-	files, color, showPrefix, showSuffix, showFields, includeFields, excludeFields, maxFieldLength := cli()
-	formatter, err := structure.NewFormatter(os.Stdout, "")
+	opts := cli()
+	stopProfiling, err := opts.profile.start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to profile: %v\n", err)
+		os.Exit(1)
+	}
+	defer stopProfile(stopProfiling)
+	if opts.ctl {
+		if err := runCtl(os.Stdout, opts.control, opts.ctlCommand); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to control session: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if opts.indexFiles {
+		if err := runIndex(os.Stdout, opts.files); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to index: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if opts.bench {
+		if err := runBench(os.Stdout, opts.benchFormat, opts.benchLines); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to benchmark: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if opts.export != "" {
+		if err := runExport(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to export: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var output io.Writer = os.Stdout
+	budget := stream.NewBudget(opts.maxMemory)
+	var paused *pausedWriter
+	if opts.control != "" {
+		paused = newPausedWriter(os.Stdout, budget)
+		output = paused
+	}
+	var page *structure.HTMLWriter
+	switch opts.output {
+	case structure.OutputHTML:
+		page = structure.NewHTMLWriter(output)
+		output = page
+		opts.color = true
+	case structure.OutputMarkdown, structure.OutputGitHub, structure.OutputCSV, structure.OutputTSV, structure.OutputJSON, structure.OutputLogfmt:
+		opts.color = false
+	}
+	formatter, err := structure.NewFormatter(output, opts.template)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "invalid format: %v\n", err)
 		os.Exit(1)
 	}
 
-	formatter.Colorize = color
-	formatter.ShowPrefix = showPrefix
-	formatter.ShowSuffix = showSuffix
-	formatter.ShowFields = showFields
-	formatter.MaxFieldLength = maxFieldLength
-	formatter.IncludeFields = strings.Split(includeFields, ",")
-	formatter.ExcludeFields = append(formatter.ExcludeFields, strings.Split(excludeFields, ",")...)
+	formatter.Colorize = opts.color
+	formatter.ShowPrefix = opts.showPrefix
+	formatter.ShowSuffix = opts.showSuffix
+	formatter.ShowFields = opts.showFields
+	formatter.DaySeparator = opts.daySeparator
+	formatter.Highlight = opts.highlight
+	formatter.InferLevels = opts.inferLevels
+	formatter.KeepANSI = opts.keepANSI
+	formatter.HighlightValues = opts.highlightValues
+	formatter.Width = terminalWidth()
+	formatter.CallerRule = opts.callerRule
+	formatter.ArrayStyle = opts.arrayStyle
+	formatter.Quote = opts.quote
+	formatter.JWT = opts.jwt
+	if opts.enrichUA != "" {
+		formatter.UserAgentFields = strings.Split(opts.enrichUA, ",")
+	}
+	formatter.Output = opts.output
+	formatter.Printer = structure.NewPrinter(opts.output, opts.color)
+	if opts.columns != "" {
+		formatter.Columns = strings.Split(opts.columns, ",")
+	}
+	formatter.MaxDepth = opts.maxDepth
+	formatter.Verbosity = opts.verbosity
+	formatter.ShowHiddenCount = opts.hiddenCount
+	formatter.WorkDir, _ = os.Getwd()
+	formatter.Modules = ownModules(opts.module, formatter.WorkDir)
+	formatter.SourceContext = opts.sourceContext
+	if opts.errorFields != "" {
+		formatter.ErrorFields = append(formatter.ErrorFields, strings.Split(opts.errorFields, ",")...)
+	}
+	if opts.stackFields != "" {
+		formatter.StackFields = append(formatter.StackFields, strings.Split(opts.stackFields, ",")...)
+	}
+	formatter.MaxFieldLength = opts.maxFieldLength
+	formatter.IncludeFields = strings.Split(opts.includeFields, ",")
+	formatter.ExcludeFields = append(formatter.ExcludeFields, strings.Split(opts.excludeFields, ",")...)
+	formatter.LoggerFields = strings.Split(opts.loggerFields, ",")
+	formatter.ShowThread = opts.showThread
+
+	if opts.bracketLevels != "" {
+		if err := parsers.AddBracketLevels(opts.bracketLevels); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid bracket levels: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.journaldLevels != "" {
+		if err := processors.AddPriorityLevels(opts.journaldLevels); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid journald levels: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	processors.JournaldFacilities = opts.journaldFacility
+
+	if opts.grokPatterns != "" {
+		if err := loadGrokPatterns(opts.grokPatterns); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid grok patterns: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := parsers.AddPatterns(opts.patterns); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid pattern: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := processors.AddDecoders(opts.decodeFields); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid decode field: %v\n", err)
+		os.Exit(1)
+	}
 
-	r, err := openFiles(files)
+	if len(opts.addFields) > 0 {
+		add, err := processors.NewAddFieldProcessor(opts.addFields)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid field: %v\n", err)
+			os.Exit(1)
+		}
+		processors.All = append(processors.All, add)
+	}
+	if opts.statusLevel {
+		processors.All = append(processors.All, &processors.StatusLevelProcessor{})
+	}
+	if len(opts.deriveLevel) > 0 {
+		derive, err := processors.NewDeriveLevelProcessor(opts.deriveLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid rule: %v\n", err)
+			os.Exit(1)
+		}
+		processors.All = append(processors.All, derive)
+	}
+
+	minLevel := -1
+	if opts.level != "" {
+		minLevel = structure.SeverityRank(opts.level)
+		if minLevel == -1 {
+			fmt.Fprintf(os.Stderr, "invalid level: %q is not a known level\n", opts.level)
+			os.Exit(1)
+		}
+	}
+	if opts.stderrLevel != "" && structure.SeverityRank(opts.stderrLevel) == -1 {
+		fmt.Fprintf(os.Stderr, "invalid level: %q is not a known level\n", opts.stderrLevel)
+		os.Exit(1)
+	}
+	checkList, err := checks.New(opts.failOn)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to open file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "invalid rule: %v\n", err)
 		os.Exit(1)
 	}
-	s := stream.New(r)
-	for line := range s.Lines() {
-		var err error
-		entry := &structure.Entry{}
-		if line.JSON != nil && len(line.JSON) > 0 {
-			var unused interface{}
-			err = json.Unmarshal(line.JSON, &unused)
-			djson.Unmarshal(line.JSON, entry)
+
+	expectations, err := checks.NewExpectations(opts.expect)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid expectation: %v\n", err)
+		os.Exit(1)
+	}
+
+	var validation *checks.Validation
+	if opts.validate != "" {
+		validation, err = loadSchema(opts.validate, opts.onInvalid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid schema: %v\n", err)
+			os.Exit(1)
 		}
+	}
 
-		// unable to parse entry, outputting raw line:
-		if line.JSON == nil || err != nil {
-			writeBytes(line.Raw)
-			writeBytes(structure.NewLine)
+	var parseErrors *json.Encoder
+	if opts.parseErrors {
+		var w io.Writer = os.Stderr
+		if opts.errorsFile != "" {
+			f, err := os.Create(opts.errorsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to create errors file: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			w = f
+		}
+		parseErrors = json.NewEncoder(w)
+	}
+
+	var forward *export.Syslog
+	if opts.forwardSyslog != "" {
+		var conn io.Closer
+		forward, conn, err = dialSyslog(opts.forwardSyslog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to connect to syslog: %v\n", err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+	}
+
+	var marks *marker
+	if opts.marksFile != "" {
+		marks, err = newMarker(opts.markPatterns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid mark pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	routes, err := newRoutes(opts.routes, formatter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid route: %v\n", err)
+		os.Exit(1)
+	}
+	where, err := parseWhere(opts.where)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid expression: %v\n", err)
+		os.Exit(1)
+	}
+	var pairs *pairer
+	if opts.pairBy != "" {
+		pairs, err = newPairer(opts.pairBy, opts.pairStart, opts.pairEnd, opts.pairTimeout, budget)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid pair: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var sampling *sampler
+	if opts.sample != "" {
+		sampling, err = newSampler(opts.sample, opts.keepLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid sample: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var counts *counter
+	if opts.count {
+		counts = newCounter(opts.countPer)
+	}
+	var sessions *sessionizer
+	if opts.sessionize != "" {
+		sessions, err = newSessionizer(opts.sessionize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid sessionize rule: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var alerts *alerter
+	if opts.alert > 0 {
+		alerts = newAlerter(os.Stderr, opts.alert, opts.alertMin)
+	}
+	var heartbeat *watchdog
+	if opts.heartbeat > 0 {
+		heartbeat = newWatchdog(os.Stderr, opts.heartbeat, opts.heartbeatExec, opts.color)
+	}
+
+	interrupted := notifyInterrupts()
+	controls := &session{output: paused, marks: marks}
+	controls.filters.minLevel.Store(int32(minLevel))
+	controls.filters.where.Store(&where)
+	if opts.control != "" {
+		stop, err := serveControl(opts.control, controls)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to listen on control socket: %v\n", err)
+			os.Exit(1)
+		}
+		interrupted.onExit(stop)
+		defer stop()
+	}
+
+	// the level of an entry can only be read without parsing it when it isn't
+	// raised by processors and the entry isn't checked, forwarded, routed or
+	// bookmarked:
+	fastLevel := !opts.statusLevel && len(opts.deriveLevel) == 0 &&
+		len(checkList) == 0 && len(expectations) == 0 && validation == nil && forward == nil &&
+		len(routes) == 0 && len(opts.markPatterns) == 0
+
+	// the parts of indexed files that are skipped can't be shown when the
+	// level is lowered through the control socket:
+	var indexQuery index.Query
+	if fastLevel {
+		indexQuery = index.Query{Since: opts.since, Until: opts.until}
+		if opts.control == "" {
+			indexQuery.MinRank = minLevel
+		}
+	}
+	password, err := readPassword(opts.passwordFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read password file: %v\n", err)
+		os.Exit(1)
+	}
+	var s stream.Stream
+	switch {
+	case opts.container != "":
+		s, err = openContainer(opts.runtime, opts.container, opts.follow)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read container logs: %v\n", err)
+			os.Exit(1)
+		}
+	case opts.compose:
+		s, err = openCompose(opts.services, opts.color)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to follow compose project: %v\n", err)
+			os.Exit(1)
+		}
+	case len(opts.hosts) > 0:
+		s, err = openSSH(opts.hosts, opts.command, opts.color)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to run remote command: %v\n", err)
+			os.Exit(1)
+		}
+	case opts.gcloud:
+		s, err = openCloudLogging(opts.project, opts.logFilter, opts.follow)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read cloud logging: %v\n", err)
+			os.Exit(1)
+		}
+	case opts.otlpAddr != "" || opts.otlpGRPCAddr != "":
+		s, err = openOTLP(opts.otlpAddr, opts.otlpGRPCAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to receive otlp: %v\n", err)
+			os.Exit(1)
+		}
+	case opts.bus == "redis":
+		s, err = openRedis(opts.redisAddr, opts.subject, opts.redisGroup, password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read redis stream: %v\n", err)
+			os.Exit(1)
+		}
+	case opts.bus != "":
+		s, err = openSubscription(opts.bus, opts.server, opts.subject, password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to subscribe: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		s, err = openFiles(opts.files, decoder(opts), mappable(opts), indexQuery)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.execFilter != "" {
+		s, err = execFilter(s, opts.execFilter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to run filter: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var units *unitGrouper
+	if opts.groupByUnit {
+		units = newUnitGrouper(formatter, budget)
+	}
+	write := func(line *stream.Line, entry *structure.Entry) error {
+		if sessions != nil {
+			sessions.Add(line.JSON, entry)
+		}
+		if counts != nil {
+			counts.Add(entry)
+		}
+		if counts != nil || sessions != nil {
+			return nil
+		}
+		if units != nil {
+			return units.add(line, entry)
+		}
+		return formatter.Format(entry, line.JSON, line.Prefix, line.Suffix)
+	}
+	lines := interrupted.until(s.Lines())
+	if pairs != nil {
+		lines = pairs.ticking(lines)
+	}
+	for {
+		line, more := units.next(lines)
+		if !more {
+			break
+		}
+		if line == nil {
+			// a tick of the pairer, to write the starts that timed out:
+			if err := outputAll(pairs.Expire(), write); err != nil {
+				fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+				break
+			}
+			continue
+		}
+		if heartbeat != nil {
+			heartbeat.Beat()
+		}
+		if title, start, ok := parsers.CIGroup(line.Raw); ok {
+			if start {
+				err = formatter.StartGroup(title)
+			} else {
+				err = formatter.EndGroup()
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+				break
+			}
+			continue
+		}
+
+		minLevel := int(controls.filters.minLevel.Load())
+		where, grep := *controls.filters.where.Load(), controls.filters.grep.Load()
+		if fastLevel && belowLevel(line.JSON, minLevel) {
+			parser.Count(line.Source, "json")
+			formatter.Hidden.AddEntry(structure.HiddenBelowLevel, len(line.Raw))
 			continue
 		}
 
-		if (entry.Timestamp == nil || entry.Timestamp.IsZero()) && entry.FloatTimestamp > 0 {
-			sec, dec := math.Modf(entry.FloatTimestamp)
-			t := time.Unix(int64(sec), int64(dec*(1e9))).UTC()
-			entry.Timestamp = &t
+		entry, ok, err := parser.Line(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to process message: %v\n", err)
+			os.Exit(1)
+		}
+		entry, ok = stderrEntry(line, entry, ok, opts.stderrLevel)
+
+		if forward != nil {
+			if err := writeEntry(forward, line, entry, ok); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to forward: %v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		for _, processor := range processors.All {
-			if processor.Detect(line, entry) {
-				if err := processor.Process(line, entry); err != nil {
-					fmt.Fprintf(os.Stderr, "failed to process message: %v\n", err)
+		// unable to parse entry, outputting raw line:
+		if !ok {
+			if diagnostic, broken := stream.Diagnose(line); broken && parseErrors != nil {
+				if err := parseErrors.Encode(diagnostic); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to report parse error: %v\n", err)
 					os.Exit(1)
 				}
 			}
+			if len(where) > 0 && !matchWhere(where, nil, &structure.Entry{Message: string(line.Raw)}) || grep != nil && !grep.Match(line.Raw) {
+				formatter.Hidden.AddEntry(structure.HiddenNotMatching, len(line.Raw))
+				continue
+			}
+			tagStderr(line, false, opts.color)
+			if counts != nil {
+				counts.Add(nil)
+			} else if sessions != nil {
+				continue
+			} else if units != nil {
+				err = units.add(line, nil)
+			} else {
+				err = formatter.FormatRaw(line.Raw)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+				break
+			}
+			continue
+		}
+
+		for _, check := range checkList {
+			check.Observe(line.JSON, entry)
+		}
+		for _, expectation := range expectations {
+			expectation.Observe(line.JSON, entry)
+		}
+		if marks != nil {
+			marks.Observe(line, entry)
+		}
+		if alerts != nil {
+			alerts.Observe(entry)
+		}
+		for _, r := range routes {
+			if err := r.Route(line, entry); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to route: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if validation != nil && !validation.Observe(line.JSON, entry) {
+			formatter.Hidden.AddEntry(structure.HiddenInvalid, len(line.Raw))
+			continue
+		}
+
+		if rank := structure.SeverityRank(entry.Severity); rank != -1 && rank < minLevel {
+			formatter.Hidden.AddEntry(structure.HiddenBelowLevel, len(line.Raw))
+			continue
+		}
+		if !withinTime(entry, opts.since, opts.until) {
+			formatter.Hidden.AddEntry(structure.HiddenOutsideTime, len(line.Raw))
+			continue
+		}
+		if opts.followThread != "" {
+			if thread, _ := structure.Thread(line.JSON); thread != "" && thread != opts.followThread {
+				formatter.Hidden.AddEntry(structure.HiddenOtherThread, len(line.Raw))
+				continue
+			}
+		}
+		if !matchWhere(where, line.JSON, entry) || grep != nil && !grep.Match(line.Raw) {
+			formatter.Hidden.AddEntry(structure.HiddenNotMatching, len(line.Raw))
+			continue
+		}
+		if sampling != nil && !sampling.Keep(entry) {
+			formatter.Hidden.AddEntry(structure.HiddenSampled, len(line.Raw))
+			continue
 		}
 
 		// Passing entry to formatter to output:
-		prefix, suffix := split(line.Raw, line.JSON)
-		err = formatter.Format(entry, line.JSON, prefix, suffix)
+		tagStderr(line, true, opts.color)
+		if pairs != nil {
+			err = outputAll(pairs.Pair(line, entry), write)
+		} else {
+			err = write(line, entry)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
 			break
 		}
 	}
 
+	if alerts != nil {
+		alerts.Close()
+	}
+	if heartbeat != nil {
+		heartbeat.Close()
+	}
+	for _, r := range routes {
+		if err := r.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to route: %v\n", err)
+		}
+	}
+	if pairs != nil {
+		if err := outputAll(pairs.Close(), write); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+	if counts != nil {
+		if err := counts.Write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+	if sessions != nil {
+		if err := sessions.Write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+	if units != nil {
+		if err := units.close(); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+	if err := formatter.EndGroup(); err != nil {
+		fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+	}
 	if err := s.Err(); err != nil {
 		fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
 	}
-}
 
-func writeBytes(line []byte) {
-	_, err := os.Stdout.Write(line)
-	if err != nil {
+	if paused != nil {
+		if _, err := paused.resume(); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+	if page != nil {
+		if err := page.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+
+	if opts.stats {
+		writeStats(os.Stderr, parser.Formats())
+	}
+	if marks != nil {
+		if err := marks.write(opts.marksFile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write marks: %v\n", err)
+		}
+	}
+	if opts.hiddenSummary {
+		if err := structure.WriteHidden(os.Stderr, formatter.Hidden); err != nil {
+			fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+		}
+	}
+	if err := checks.WriteExpectations(os.Stderr, expectations); err != nil {
 		fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+	}
+	if err := checks.WriteValidation(os.Stderr, validation); err != nil {
+		fmt.Fprintf(os.Stderr, "broken pipe: %v\n", err)
+	}
+
+	if err := writeReport(opts.report, opts.reportFile, checkList); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
 		os.Exit(1)
 	}
+	if checks.AnyFailed(checkList) || (validation != nil && validation.Failed()) {
+		stopProfile(stopProfiling)
+		os.Exit(2)
+	}
+}
+
+// stopProfile writes the profiles, it's called before exiting.
+func stopProfile(stop func() error) {
+	if err := stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write profile: %v\n", err)
+	}
+}
+
+// levelKeys are the json keys of the level of an entry, like the djson tag
+// of structure.Entry.Severity.
+var levelKeys = []string{"severity", "level", "log\\.level"}
+
+// belowLevel reports whether a line of json has a level lower than the
+// given rank. Only the level is read, so lines that are filtered out by
+// --level skip parsing and processing the entry entirely.
+func belowLevel(data []byte, minLevel int) bool {
+	if minLevel <= 0 || len(data) == 0 {
+		return false
+	}
+	for _, key := range levelKeys {
+		if level := gjson.GetBytes(data, key); level.Exists() {
+			rank := structure.SeverityRank(level.String())
+			return rank != -1 && rank < minLevel
+		}
+	}
+	return false
+}
+
+// parser parses the lines into entries and caches the detected text format
+// of every source.
+var parser = parse.NewParser()
+
+// writeReport writes the results of the checks in the given format to the
+// file, or stderr when no file is given.
+func writeReport(format, file string, checkList []*checks.Check) error {
+	if format == "" || len(checkList) == 0 {
+		return nil
+	}
+	var w io.Writer = os.Stderr
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	switch format {
+	case "junit":
+		return checks.WriteJUnit(w, checkList)
+	case "tap":
+		return checks.WriteTAP(w, checkList)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// writeStats writes the number of lines per detected format of every source,
+// the most common format first.
+func writeStats(w io.Writer, formats map[string]map[string]int) {
+	sources := make([]string, 0, len(formats))
+	for source := range formats {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		counts := formats[source]
+		names := make([]string, 0, len(counts))
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts[names[i]] != counts[names[j]] {
+				return counts[names[i]] > counts[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%d %s", counts[name], name)
+		}
+		fmt.Fprintf(w, "%s: %s\n", source, strings.Join(parts, ", "))
+	}
+}
+
+// ownModules returns the module prefixes (and directories) of the code being
+// debugged, the given module or otherwise the one declared in ./go.mod.
+func ownModules(module, workdir string) []string {
+	if module == "" {
+		data, err := os.ReadFile("go.mod")
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if name, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					module = strings.Trim(strings.TrimSpace(name), `"`)
+					break
+				}
+			}
+		}
+	}
+	var modules []string
+	if module != "" {
+		modules = append(modules, module)
+	}
+	if workdir != "" && workdir != "/" {
+		modules = append(modules, workdir)
+	}
+	return modules
 }
 
-func split(raw, json []byte) (prefix, suffix []byte) {
-	parts := bytes.SplitN(raw, json, 2)
-	prefix = parts[0]
-	if len(parts) >= 2 {
-		suffix = parts[1]
+// loadGrokPatterns adds the grok patterns of the file to the library.
+func loadGrokPatterns(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return parsers.LoadGrokPatterns(f)
+}
+
+// loadSchema reads the JSON Schema file to validate the entries against.
+func loadSchema(file, onInvalid string) (*checks.Validation, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
 	}
-	return
+	return checks.NewValidation(file, data, onInvalid)
+}
+
+// decoder returns the function that decodes the input to lines of json.
+func decoder(opts options) func(io.Reader) (io.Reader, error) {
+	if opts.protoDesc != "" {
+		return func(r io.Reader) (io.Reader, error) {
+			if opts.protoMsg == "" {
+				return nil, errors.New("--proto-msg is required to read protobuf messages")
+			}
+			descriptorSet, err := os.ReadFile(opts.protoDesc)
+			if err != nil {
+				return nil, err
+			}
+			return input.NewProtobuf(r, descriptorSet, opts.protoMsg)
+		}
+	}
+	return func(r io.Reader) (io.Reader, error) {
+		switch opts.input {
+		case input.CSV:
+			return input.NewCSV(r, ',', opts.mapping)
+		case input.TSV:
+			return input.NewCSV(r, '\t', opts.mapping)
+		}
+		return input.NewReader(r, opts.input)
+	}
+}
+
+// mappable reports whether the input is text, which can be read from memory
+// mapped files.
+func mappable(opts options) bool {
+	return opts.protoDesc == "" && (opts.input == input.Auto || opts.input == input.JSON)
 }
 
-func openFiles(files []string) (io.Reader, error) {
+// mapFile returns a Stream of the lines of a memory mapped file, ok is false
+// when the file can't be mapped or isn't text.
+func mapFile(f *os.File, source string) (stream.Stream, bool) {
+	data, unmap, err := stream.MapFile(f)
+	if err != nil {
+		return nil, false
+	}
+	if input.Detect(data) != input.JSON {
+		unmap()
+		return nil, false
+	}
+	return stream.NewBytes(data, source, func() error {
+		return errors.Join(unmap(), f.Close())
+	}), true
+}
+
+// openFiles streams the given files, or stdin, one after the other and
+// decodes each of them to lines of json. The lines have the file as source.
+// When mapped is true, regular files of text are memory mapped instead. With
+// a selective query, only the parts of indexed files that contain the
+// entries it selects are read.
+func openFiles(files []string, decode func(io.Reader) (io.Reader, error), mapped bool, query index.Query) (stream.Stream, error) {
 	var filtered []string
 	for _, file := range files {
 		if file != "" {
@@ -110,19 +807,45 @@ func openFiles(files []string) (io.Reader, error) {
 		}
 	}
 	if len(filtered) == 0 {
-		return os.Stdin, nil
+		filtered = append(filtered, "-")
 	}
-	readers := make([]io.Reader, 0)
+	streams := make([]stream.Stream, 0)
 	for _, file := range filtered {
-		if file == "-" {
-			readers = append(readers, os.Stdin)
-		} else {
+		var r io.Reader = os.Stdin
+		source := "stdin"
+		if objects.IsURL(file) {
+			o, err := objects.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			r, source = o, file
+		} else if file != "-" {
 			f, err := os.Open(file)
 			if err != nil {
 				return nil, err
 			}
-			readers = append(readers, f)
+			if query.Selective() {
+				if s, ok := indexedStream(f, file, query); ok {
+					streams = append(streams, s)
+					continue
+				}
+			}
+			if mapped {
+				if s, ok := mapFile(f, file); ok {
+					streams = append(streams, s)
+					continue
+				}
+			}
+			r, source = f, file
+		}
+		r, err := decode(r)
+		if err != nil {
+			return nil, err
 		}
+		streams = append(streams, stream.NewSource(r, source))
+	}
+	if len(streams) == 1 {
+		return streams[0], nil
 	}
-	return io.MultiReader(readers...), nil
+	return stream.Concat(streams...), nil
 }
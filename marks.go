@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// mark is a bookmarked entry of the incident timeline.
+type mark struct {
+	Time    string          `json:"time,omitempty"`
+	Level   string          `json:"level,omitempty"`
+	Message string          `json:"message"`
+	Source  string          `json:"source,omitempty"`
+	Line    int             `json:"line,omitempty"`
+	Pattern string          `json:"pattern,omitempty"`
+	Note    string          `json:"note,omitempty"`
+	Entry   json.RawMessage `json:"entry,omitempty"`
+}
+
+// marker bookmarks the entries that match one of the patterns, and the last
+// entry when asked to through the control socket, to export them as an
+// incident timeline at the end of the session.
+type marker struct {
+	mu       sync.Mutex
+	patterns []*regexp.Regexp
+	marks    []*mark
+	last     *mark
+	lastSeen bool
+}
+
+func newMarker(patterns []string) (*marker, error) {
+	m := &marker{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// Observe bookmarks the entry when it matches one of the patterns, it has to
+// be called before the entry is formatted.
+func (m *marker) Observe(line *stream.Line, entry *structure.Entry) {
+	current := &mark{
+		Level:   structure.NormalizeSeverity(entry.Severity),
+		Message: entry.Message,
+		Source:  line.Source,
+		Line:    line.Number,
+		Entry:   append(json.RawMessage(nil), line.JSON...),
+	}
+	if entry.Timestamp != nil {
+		current.Time = entry.Timestamp.Format("2006-01-02 15:04:05")
+	} else {
+		current.Time = entry.RawTimestamp
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last, m.lastSeen = current, false
+	for _, re := range m.patterns {
+		if re.Match(line.Raw) {
+			current.Pattern = re.String()
+			m.marks = append(m.marks, current)
+			m.lastSeen = true
+			return
+		}
+	}
+}
+
+// Note bookmarks the last entry with a note, or adds the note when it's
+// bookmarked already.
+func (m *marker) Note(note string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.last == nil {
+		return fmt.Errorf("there is no entry to mark yet")
+	}
+	if m.last.Note != "" {
+		note = m.last.Note + "; " + note
+	}
+	m.last.Note = note
+	if !m.lastSeen {
+		m.marks = append(m.marks, m.last)
+		m.lastSeen = true
+	}
+	return nil
+}
+
+// write exports the timeline to the file, as a markdown table when the
+// file has the .md extension and as json otherwise.
+func (m *marker) write(file string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if ext := filepath.Ext(file); ext == ".md" || ext == ".markdown" {
+		err = writeTimeline(f, m.marks)
+	} else {
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(append([]*mark{}, m.marks...))
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeTimeline writes the marks as a markdown table.
+func writeTimeline(w io.Writer, marks []*mark) error {
+	if _, err := io.WriteString(w, "| Time | Level | Message | Note |\n|------|-------|---------|------|\n"); err != nil {
+		return err
+	}
+	for _, mark := range marks {
+		columns := []string{mark.Time, mark.Level, mark.Message, mark.Note}
+		for i, column := range columns {
+			columns[i] = structure.MarkdownEscape(column)
+		}
+		if mark.Note == "" && mark.Pattern != "" {
+			columns[3] = "matches " + structure.MarkdownCode(mark.Pattern)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(columns, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Package objects reads the objects of s3, gcs and azure blob storage through
+// the cli of the cloud (aws, gcloud and az), so archives of logs can be read
+// without downloading them first.
+package objects
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Location is an object store url like s3://bucket/prefix/*.log.gz, for azure
+// the bucket is the storage account and container: az://account/container.
+type Location struct {
+	Scheme  string
+	Bucket  string
+	Pattern string
+}
+
+// IsURL reports whether the file is an object store url.
+func IsURL(file string) bool {
+	for _, scheme := range []string{"s3://", "gs://", "az://"} {
+		if strings.HasPrefix(file, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse splits an object store url into its location.
+func Parse(url string) (Location, error) {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok || !IsURL(url) {
+		return Location{}, fmt.Errorf("%q is not an s3://, gs:// or az:// url", url)
+	}
+	parts := 1
+	if scheme == "az" {
+		parts = 2
+	}
+	elements := strings.SplitN(rest, "/", parts+1)
+	if len(elements) < parts || elements[parts-1] == "" {
+		return Location{}, fmt.Errorf("%q has no bucket", url)
+	}
+	l := Location{Scheme: scheme, Bucket: strings.Join(elements[:parts], "/")}
+	if len(elements) > parts {
+		l.Pattern = elements[parts]
+	}
+	return l, nil
+}
+
+// prefix returns the part of the pattern before its first wildcard.
+func (l Location) prefix() string {
+	if i := strings.IndexAny(l.Pattern, "*?["); i != -1 {
+		return l.Pattern[:i]
+	}
+	return l.Pattern
+}
+
+// Match reports whether the key of an object is matched by the pattern, a
+// pattern without wildcards matches all keys that start with it.
+func (l Location) Match(key string) bool {
+	if l.prefix() == l.Pattern {
+		return strings.HasPrefix(key, l.Pattern)
+	}
+	// a * in the last element also matches deeper keys, like a listing of a
+	// prefix does:
+	for pattern := l.Pattern; ; {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if !strings.HasSuffix(pattern, "*") {
+			return false
+		}
+		pattern += "/*"
+		if strings.Count(pattern, "/") > strings.Count(key, "/") {
+			return false
+		}
+	}
+}
+
+func (l Location) url(key string) string {
+	return l.Scheme + "://" + l.Bucket + "/" + key
+}
+
+// listCommand returns the command that lists the keys of the objects that
+// start with the prefix of the pattern.
+func (l Location) listCommand() []string {
+	switch l.Scheme {
+	case "gs":
+		return []string{"gcloud", "storage", "ls", l.url(l.prefix()) + "**"}
+	case "az":
+		account, container, _ := strings.Cut(l.Bucket, "/")
+		return []string{"az", "storage", "blob", "list", "--account-name", account, "--container-name", container, "--prefix", l.prefix(), "--query", "[].name", "--output", "tsv"}
+	}
+	return []string{"aws", "s3api", "list-objects-v2", "--bucket", l.Bucket, "--prefix", l.prefix(), "--query", "Contents[].Key", "--output", "json"}
+}
+
+// readCommand returns the command that writes the object to stdout.
+func (l Location) readCommand(key string) []string {
+	switch l.Scheme {
+	case "gs":
+		return []string{"gcloud", "storage", "cat", l.url(key)}
+	case "az":
+		account, container, _ := strings.Cut(l.Bucket, "/")
+		return []string{"az", "storage", "blob", "download", "--account-name", account, "--container-name", container, "--name", key, "--file", "/dev/stdout", "--no-progress", "--output", "none"}
+	}
+	return []string{"aws", "s3", "cp", "--quiet", l.url(key), "-"}
+}
+
+// parseListing returns the keys in the output of the list command. The keys
+// of s3 are listed as a json array, so no key is mangled by its spaces.
+func (l Location) parseListing(output []byte) ([]string, error) {
+	var listed []string
+	if l.Scheme == "s3" {
+		if err := json.Unmarshal(output, &listed); err != nil {
+			return nil, fmt.Errorf("invalid listing of %s: %w", l.url(l.prefix()), err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), "\r")
+			if l.Scheme == "gs" {
+				line = strings.TrimPrefix(line, l.url(""))
+			}
+			listed = append(listed, line)
+		}
+	}
+	var keys []string
+	for _, key := range listed {
+		if key != "" && !strings.HasSuffix(key, "/") {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// List returns the keys of the objects that match the pattern, in key order.
+func (l Location) List() ([]string, error) {
+	output, err := run(l.listCommand()).Output()
+	if err != nil {
+		return nil, commandError(l.listCommand(), err)
+	}
+	listed, err := l.parseListing(output)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, key := range listed {
+		if l.Match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Open returns a reader of the objects that match the url, one after
+// another in key order. Objects ending with .gz or .bz2 are decompressed.
+func Open(url string) (io.ReadCloser, error) {
+	l, err := Parse(url)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no objects match %s", url)
+	}
+	return &reader{location: l, keys: keys}, nil
+}
+
+// reader reads the objects one after another, only one object is read at a
+// time.
+type reader struct {
+	location Location
+	keys     []string
+	cmd      *exec.Cmd
+	stdout   io.ReadCloser
+	current  io.Reader
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			if err := r.next(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			if err := r.finish(); err != nil {
+				return n, err
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// next starts reading the next object.
+func (r *reader) next() error {
+	key := r.keys[0]
+	r.keys = r.keys[1:]
+	args := r.location.readCommand(key)
+	r.cmd = run(args)
+	stdout, err := r.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := r.cmd.Start(); err != nil {
+		return commandError(args, err)
+	}
+	r.stdout, r.current = stdout, stdout
+	switch {
+	case strings.HasSuffix(key, ".gz"):
+		r.current, err = gzip.NewReader(bufio.NewReader(stdout))
+	case strings.HasSuffix(key, ".bz2"):
+		r.current = bzip2.NewReader(stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.location.url(key), err)
+	}
+	return nil
+}
+
+// finish waits for the command that read the current object.
+func (r *reader) finish() error {
+	r.current = nil
+	err := r.cmd.Wait()
+	r.cmd = nil
+	return err
+}
+
+func (r *reader) Close() error {
+	r.keys = nil
+	if r.cmd == nil {
+		return nil
+	}
+	r.stdout.Close()
+	_ = r.cmd.Process.Kill()
+	_ = r.cmd.Wait()
+	r.cmd = nil
+	return nil
+}
+
+func run(args []string) *exec.Cmd {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+func commandError(args []string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("the %s command is required to read objects", args[0])
+	}
+	return fmt.Errorf("%s: %w", strings.Join(args[:3], " "), err)
+}
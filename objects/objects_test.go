@@ -0,0 +1,85 @@
+package objects
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url    string
+		expect Location
+	}{
+		{"s3://logs/api/2023-06-16/*", Location{"s3", "logs", "api/2023-06-16/*"}},
+		{"gs://logs", Location{"gs", "logs", ""}},
+		{"az://account/logs/api/", Location{"az", "account/logs", "api/"}},
+	}
+	for _, tt := range tests {
+		l, err := Parse(tt.url)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", tt.url, err)
+			continue
+		}
+		if l != tt.expect {
+			t.Errorf("\n\tnot match: %+v\n\t   expect: %+v\n", l, tt.expect)
+		}
+	}
+
+	for _, url := range []string{"s3://", "az://account", "https://logs"} {
+		if _, err := Parse(url); err == nil {
+			t.Errorf("Parse(%q) returned no error", url)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		key     string
+		expect  bool
+	}{
+		{"api/2023-06-16/*", "api/2023-06-16/00.log.gz", true},
+		{"api/2023-06-16/*", "api/2023-06-16/eu/00.log.gz", true},
+		{"api/2023-06-16/*.gz", "api/2023-06-16/00.log", false},
+		{"api/2023-06-1?/*", "api/2023-06-17/00.log", true},
+		{"api/2023-06-16/*", "api/2023-06-17/00.log", false},
+		{"api/2023-06", "api/2023-06-16/00.log", true},
+		{"", "api/2023-06-16/00.log", true},
+	}
+	for _, tt := range tests {
+		l := Location{Scheme: "s3", Bucket: "logs", Pattern: tt.pattern}
+		if got := l.Match(tt.key); got != tt.expect {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.expect)
+		}
+	}
+}
+
+func TestParseListing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme string
+		output string
+		expect string
+	}{
+		{"s3", `["api/01.log", "api/", "api/my  file.log", " api/lead.log"]` + "\n", "api/01.log,api/my  file.log, api/lead.log"},
+		{"s3", "null\n", ""},
+		{"gs", "gs://logs/api/01.log\ngs://logs/api/02.log\n", "api/01.log,api/02.log"},
+		{"az", "api/01.log\r\napi/02.log\r\n", "api/01.log,api/02.log"},
+	}
+	for _, tt := range tests {
+		l := Location{Scheme: tt.scheme, Bucket: "logs"}
+		keys, err := l.parseListing([]byte(tt.output))
+		if err != nil {
+			t.Fatalf("parseListing(%q) = %v", tt.output, err)
+		}
+		got := strings.Join(keys, ",")
+		if got != tt.expect {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, tt.expect)
+		}
+	}
+}
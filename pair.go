@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/koenbollen/jl/filter"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// pairer matches the entries that start something, like a received request,
+// with the entries that end it, by a key they share, like a request id. The
+// start is held back and written combined with its end, with the latency
+// between them. Starts without an end are written as unpaired, a potential
+// hang, once they're waiting longer than the timeout, when they're replaced
+// by a start with the same key, when they don't fit in the memory budget,
+// or at the end.
+type pairer struct {
+	key        string
+	start, end *filter.Expr
+	timeout    time.Duration
+	budget     *stream.Budget
+	open       map[string]*pendingEntry
+	order      []string
+}
+
+// pendingEntry is an entry that's held back, to be written later.
+type pendingEntry struct {
+	line    stream.Line
+	entry   structure.Entry
+	arrived time.Time
+	size    int64
+}
+
+// newPairer parses the expressions of the start and end entries.
+func newPairer(key, startExpr, endExpr string, timeout time.Duration, budget *stream.Budget) (*pairer, error) {
+	if startExpr == "" || endExpr == "" {
+		return nil, fmt.Errorf("--pair-by %s needs --pair-start and --pair-end", key)
+	}
+	start, err := filter.Parse(startExpr)
+	if err != nil {
+		return nil, err
+	}
+	end, err := filter.Parse(endExpr)
+	if err != nil {
+		return nil, err
+	}
+	return &pairer{key: key, start: start, end: end, timeout: timeout, budget: budget, open: map[string]*pendingEntry{}}, nil
+}
+
+// Pair returns the entries to write for the entry: none for a start, that's
+// held back, the combined entry for the end of a start, and the entry itself
+// otherwise. They're preceded by the starts that timed out by the time of
+// the entry, so replaying a file doesn't hold back all starts without end.
+func (p *pairer) Pair(line *stream.Line, entry *structure.Entry) []pendingEntry {
+	var written []pendingEntry
+	if entry.Timestamp != nil {
+		written = p.expire(func(started *pendingEntry) bool {
+			return started.entry.Timestamp != nil && entry.Timestamp.Sub(*started.entry.Timestamp) > p.timeout
+		})
+	}
+	key := gjson.GetBytes(line.JSON, p.key).String()
+	if key == "" {
+		return append(written, pendingEntry{line: *line, entry: *entry})
+	}
+	if p.start.Match(line.JSON, entry) {
+		unpaired := written
+		if previous, ok := p.open[key]; ok {
+			unpaired = append(unpaired, p.unpaired(previous))
+			p.forget(key)
+		}
+		started := &pendingEntry{line: *line, entry: *entry, arrived: time.Now()}
+		started.line.JSON = append(json.RawMessage(nil), line.JSON...)
+		started.line.Prefix = append([]byte(nil), line.Prefix...)
+		started.line.Suffix = append([]byte(nil), line.Suffix...)
+		// the oldest starts make room for the new one, a start larger than
+		// the whole budget is held anyway:
+		started.size = line.Size()
+		for !p.budget.Take(started.size) {
+			if len(p.order) == 0 {
+				started.size = 0
+				break
+			}
+			oldest := p.order[0]
+			unpaired = append(unpaired, p.unpaired(p.open[oldest]))
+			p.forget(oldest)
+		}
+		p.open[key] = started
+		p.order = append(p.order, key)
+		return unpaired
+	}
+	started, ok := p.open[key]
+	if !ok || !p.end.Match(line.JSON, entry) {
+		return append(written, pendingEntry{line: *line, entry: *entry})
+	}
+	p.forget(key)
+	return append(written, p.combine(started, line, entry))
+}
+
+// Expire returns the starts that arrived longer than the timeout ago, for
+// followed logs that stop while a request hangs.
+func (p *pairer) Expire() []pendingEntry {
+	return p.expire(func(started *pendingEntry) bool {
+		return time.Since(started.arrived) > p.timeout
+	})
+}
+
+// expire forgets the starts that timed out and returns them as unpaired. The
+// starts are checked in the order they arrived, up to the first one that
+// didn't time out.
+func (p *pairer) expire(timedOut func(*pendingEntry) bool) []pendingEntry {
+	var unpaired []pendingEntry
+	for len(p.order) > 0 && timedOut(p.open[p.order[0]]) {
+		key := p.order[0]
+		unpaired = append(unpaired, p.unpaired(p.open[key]))
+		p.forget(key)
+	}
+	return unpaired
+}
+
+// ticking passes on the lines, with a nil line every now and then for the
+// starts to be expired while no lines arrive.
+func (p *pairer) ticking(lines <-chan *stream.Line) <-chan *stream.Line {
+	out := make(chan *stream.Line)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(min(p.timeout, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					return
+				}
+				out <- line
+			case <-ticker.C:
+				out <- nil
+			}
+		}
+	}()
+	return out
+}
+
+// Close returns the starts that never ended, in the order they arrived.
+func (p *pairer) Close() []pendingEntry {
+	var unpaired []pendingEntry
+	for _, key := range p.order {
+		unpaired = append(unpaired, p.unpaired(p.open[key]))
+		p.budget.Release(p.open[key].size)
+	}
+	p.open, p.order = map[string]*pendingEntry{}, nil
+	return unpaired
+}
+
+func (p *pairer) forget(key string) {
+	p.budget.Release(p.open[key].size)
+	delete(p.open, key)
+	for i, k := range p.order {
+		if k == key {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// combine returns the end entry with the fields of the start it doesn't
+// have, both messages and the latency between them. The latency is taken
+// from the times of the entries, or from when they arrived without them.
+func (p *pairer) combine(started *pendingEntry, line *stream.Line, entry *structure.Entry) pendingEntry {
+	latency := time.Since(started.arrived)
+	if started.entry.Timestamp != nil && entry.Timestamp != nil {
+		latency = entry.Timestamp.Sub(*started.entry.Timestamp)
+	}
+	combined := pendingEntry{line: *line, entry: *entry}
+	combined.line.JSON = mergeFields(started.line.JSON, line.JSON, map[string]interface{}{"latency": latency.String()})
+	if started.entry.Message != "" && entry.Message != "" {
+		combined.entry.Message = started.entry.Message + " → " + entry.Message
+		combined.entry.Substitutions = nil
+	}
+	return combined
+}
+
+// unpaired returns the start marked as unpaired, at least at warning level.
+func (p *pairer) unpaired(started *pendingEntry) pendingEntry {
+	marked := *started
+	marked.line.JSON = mergeFields(nil, started.line.JSON, map[string]interface{}{"unpaired": true})
+	if structure.SeverityRank(marked.entry.Severity) < structure.SeverityRank("WARNING") {
+		marked.entry.Severity = "WARNING"
+	}
+	return marked
+}
+
+// mergeFields returns the fields of the json with the fields of base it
+// doesn't have and the extra fields. The json is returned as is when it
+// isn't an object.
+func mergeFields(base, raw json.RawMessage, extra map[string]interface{}) json.RawMessage {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return raw
+	}
+	var baseFields map[string]interface{}
+	if len(base) > 0 && json.Unmarshal(base, &baseFields) == nil {
+		for key, value := range baseFields {
+			if _, ok := fields[key]; !ok {
+				fields[key] = value
+			}
+		}
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	return data
+}
+
+// outputAll writes the entries with the output function, in order.
+func outputAll(entries []pendingEntry, output func(*stream.Line, *structure.Entry) error) error {
+	for i := range entries {
+		if err := output(&entries[i].line, &entries[i].entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package parse turns the lines of logs into structured entries without
+// formatting them, for applications that embed jl, like a dashboard.
+package parse
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/parsers"
+	"github.com/koenbollen/jl/processors"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// Parser parses lines into entries. It remembers the text format of every
+// source, so it isn't safe for concurrent use.
+type Parser struct {
+	detector *parsers.Detector
+}
+
+// NewParser returns a parser that hasn't seen any lines yet.
+func NewParser() *Parser {
+	return &Parser{detector: parsers.NewDetector()}
+}
+
+// Line loads the structured entry of a line and runs the processors on it,
+// ok is false when the line doesn't contain a valid json object or a known
+// text format.
+func (p *Parser) Line(line *stream.Line) (*structure.Entry, bool, error) {
+	entry := &structure.Entry{}
+	if len(line.JSON) == 0 {
+		data, prefix, ok := p.detector.Parse(line.Source, line.Raw)
+		if !ok {
+			return entry, false, nil
+		}
+		line.JSON, line.Prefix, line.Suffix = data, prefix, nil
+	} else {
+		p.detector.Count(line.Source, "json")
+	}
+	if !json.Valid(line.JSON) {
+		return entry, false, nil
+	}
+	djson.Unmarshal(line.JSON, entry)
+
+	if (entry.Timestamp == nil || entry.Timestamp.IsZero()) && entry.FloatTimestamp > 0 {
+		sec, dec := math.Modf(entry.FloatTimestamp)
+		t := time.Unix(int64(sec), int64(dec*(1e9))).UTC()
+		entry.Timestamp = &t
+	}
+
+	for _, processor := range processors.All {
+		if processor.Detect(line, entry) {
+			if err := processor.Process(line, entry); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return entry, true, nil
+}
+
+// Count counts a line of the source as the given format, for lines that
+// aren't parsed, like json lines that are filtered out early.
+func (p *Parser) Count(source, format string) {
+	p.detector.Count(source, format)
+}
+
+// Formats returns the number of lines per format of every source.
+func (p *Parser) Formats() map[string]map[string]int {
+	return p.detector.Formats()
+}
+
+// Entry is a parsed entry with the line it was parsed from. Lines that
+// aren't structured are passed as an empty entry that isn't Structured.
+type Entry struct {
+	*structure.Entry
+	Line       *stream.Line
+	Structured bool
+}
+
+// Stream parses the lines of r and calls fn with every entry. The next line
+// isn't read before fn returns, so a slow consumer slows down the reading of
+// r instead of buffering its lines. Stream stops when r ends, when fn returns
+// an error or when the context is canceled, and returns that error (or the
+// error of the context).
+func Stream(ctx context.Context, r io.Reader, fn func(Entry) error) error {
+	s := stream.New(r)
+	parser := NewParser()
+	for {
+		select {
+		case <-ctx.Done():
+			// the stream stops after the read it might be blocked in:
+			s.Close()
+			return ctx.Err()
+		case line, more := <-s.Lines():
+			if !more {
+				return s.Err()
+			}
+			entry, ok, err := parser.Line(line)
+			if err == nil {
+				err = fn(Entry{Entry: entry, Line: line, Structured: ok})
+			}
+			if err != nil {
+				s.Close()
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	input := `{"level":"info","msg":"started"}` + "\n" +
+		"not structured at all\n" +
+		`{"severity":"error","message":"failed","ts":1686919896}` + "\n"
+	var got []string
+	err := Stream(context.Background(), strings.NewReader(input), func(entry Entry) error {
+		if !entry.Structured {
+			got = append(got, "text: "+string(entry.Line.Raw))
+			return nil
+		}
+		if entry.Timestamp != nil {
+			got = append(got, entry.Severity+": "+entry.Message+" @ "+entry.Timestamp.Format("15:04:05"))
+			return nil
+		}
+		got = append(got, entry.Severity+": "+entry.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() = %v", err)
+	}
+	expect := "info: started|text: not structured at all|error: failed @ 12:51:36"
+	if strings.Join(got, "|") != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", strings.Join(got, "|"), expect)
+	}
+}
+
+func TestStreamStops(t *testing.T) {
+	t.Parallel()
+
+	stop := errors.New("stop")
+	calls := 0
+	err := Stream(context.Background(), strings.NewReader("{}\n{}\n{}\n"), func(Entry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Stream() = %v after %d calls, want %v after 1 call", err, calls, stop)
+	}
+
+	// a reader that never ends is abandoned when the context is canceled:
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("{\"msg\":\"first\"}\n"))
+	ctx, cancel := context.WithCancel(context.Background())
+	err = Stream(ctx, r, func(entry Entry) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Stream() = %v, want %v", err, context.Canceled)
+	}
+}
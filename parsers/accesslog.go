@@ -0,0 +1,61 @@
+package parsers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+// accessLogPattern matches the Common and Combined Log Format of Apache and
+// nginx, the referer and user agent are optional.
+var accessLogPattern = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+
+const accessLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogParser parses the access logs of web servers, the level is
+// derived from the status code.
+type AccessLogParser struct {
+}
+
+func (p *AccessLogParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	match := accessLogPattern.FindSubmatch(line)
+	if match == nil {
+		return nil, nil, false
+	}
+	fields := map[string]interface{}{}
+	set := func(key string, value []byte) {
+		if len(value) > 0 && string(value) != "-" {
+			fields[key] = strings.ReplaceAll(string(value), `\"`, `"`)
+		}
+	}
+	set("client", match[1])
+	set("user", match[3])
+	set("referer", match[8])
+	set("user_agent", match[9])
+
+	if t, err := time.Parse(accessLogTime, string(match[4])); err == nil {
+		fields["time"] = t.Format(time.RFC3339)
+	} else {
+		fields["time"] = string(match[4])
+	}
+
+	request := strings.ReplaceAll(string(match[5]), `\"`, `"`)
+	fields["msg"] = request
+	if parts := strings.Split(request, " "); len(parts) == 3 {
+		fields["method"] = parts[0]
+		fields["path"] = parts[1]
+		fields["protocol"] = parts[2]
+		fields["msg"] = parts[0] + " " + parts[1]
+	}
+
+	status, _ := strconv.Atoi(string(match[6]))
+	fields["status"] = status
+	fields["level"] = structure.StatusSeverity(status)
+	if bytes, err := strconv.Atoi(string(match[7])); err == nil {
+		fields["bytes"] = bytes
+	}
+	return fields, nil, true
+}
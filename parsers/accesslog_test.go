@@ -0,0 +1,60 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAccessLogParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		expect map[string]interface{}
+	}{
+		{
+			"combined",
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			map[string]interface{}{
+				"time": "2000-10-10T13:55:36-07:00", "level": "INFO", "msg": "GET /apache_pb.gif",
+				"client": "127.0.0.1", "user": "frank", "method": "GET", "path": "/apache_pb.gif", "protocol": "HTTP/1.0",
+				"status": 200, "bytes": 2326, "referer": "http://www.example.com/start.html", "user_agent": "Mozilla/4.08 [en] (Win98; I ;Nav)",
+			},
+		},
+		{
+			"common",
+			`10.0.0.2 - - [16/Jun/2023:12:51:37 +0000] "POST /login HTTP/1.1" 503 -`,
+			map[string]interface{}{
+				"time": "2023-06-16T12:51:37Z", "level": "ERROR", "msg": "POST /login",
+				"client": "10.0.0.2", "method": "POST", "path": "/login", "protocol": "HTTP/1.1", "status": 503,
+			},
+		},
+		{
+			"malformed request",
+			`10.0.0.2 - - [16/Jun/2023:12:51:37 +0000] "\x16\x03\x01" 400 157 "-" "-"`,
+			map[string]interface{}{
+				"time": "2023-06-16T12:51:37Z", "level": "WARNING", "msg": `\x16\x03\x01`,
+				"client": "10.0.0.2", "status": 400, "bytes": 157,
+			},
+		},
+		{
+			"not an access log",
+			`[16/Jun/2023:12:51:37 +0000] GET /login 200`,
+			nil,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, _, ok := (&AccessLogParser{}).Parse([]byte(test.line))
+			if ok != (test.expect != nil) {
+				t.Fatalf("Parse() ok = %v", ok)
+			}
+			if ok && !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+			}
+		})
+	}
+}
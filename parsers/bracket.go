@@ -0,0 +1,130 @@
+package parsers
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BracketLevels maps the markers of bracketed text logs, like [WRN] or the
+// [FAILED] of systemd's boot messages, to their level. More markers can be
+// added with AddBracketLevels.
+var BracketLevels = map[string]string{
+	"TRACE":    "TRACE",
+	"TRC":      "TRACE",
+	"DEBUG":    "DEBUG",
+	"DBG":      "DEBUG",
+	"INFO":     "INFO",
+	"INF":      "INFO",
+	"OK":       "INFO",
+	"NOTICE":   "NOTICE",
+	"WARN":     "WARNING",
+	"WARNING":  "WARNING",
+	"WRN":      "WARNING",
+	"SEC":      "WARNING",
+	"DEPEND":   "WARNING",
+	"TIME":     "WARNING",
+	"ERROR":    "ERROR",
+	"ERR":      "ERROR",
+	"FAILED":   "ERROR",
+	"CRIT":     "CRITICAL",
+	"CRITICAL": "CRITICAL",
+	"FATAL":    "FATAL",
+}
+
+// bracketTime matches the timestamps of bracketed text logs, ISO 8601 with a
+// T or a space and an optional fraction and zone.
+var bracketTime = regexp.MustCompile(`^\d{4}-\d\d-\d\d[T ]\d\d:\d\d:\d\d(?:[.,]\d+)?(?:Z|[+-]\d\d:?\d\d)?`)
+
+var bracketTimeFormats = []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999Z0700"}
+
+// maxBracketTokens is the number of words or bracketed groups that are
+// searched for the level marker.
+const maxBracketTokens = 8
+
+// BracketParser is a heuristic parser of semi-structured text logs that mark
+// the level of a line in brackets, like "[ts] [level] message" or the cluster
+// log of Ceph. The marker must be the first part of the line or follow a
+// timestamp, the words in between are stored as context.
+type BracketParser struct {
+}
+
+func (p *BracketParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	fields := map[string]interface{}{}
+	var context []string
+	rest := line
+	for i := 0; i < maxBracketTokens; i++ {
+		rest = bytes.TrimLeft(rest, " \t")
+		if len(rest) == 0 {
+			break
+		}
+		token, bracketed := rest, false
+		if rest[0] == '[' {
+			end := bytes.IndexByte(rest, ']')
+			if end == -1 {
+				break
+			}
+			token, rest, bracketed = rest[1:end], rest[end+1:], true
+		} else if match := bracketTime.Find(rest); match != nil && i == 0 {
+			token, rest = match, rest[len(match):]
+		} else {
+			end := bytes.IndexAny(rest, " \t")
+			if end == -1 {
+				break
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+
+		if level, ok := BracketLevels[strings.ToUpper(string(bytes.TrimSpace(token)))]; ok && bracketed {
+			if i > 0 && fields["time"] == nil {
+				break
+			}
+			fields["level"] = level
+			fields["msg"] = strings.TrimLeft(string(rest), " \t:")
+			if len(context) > 0 {
+				fields["context"] = strings.Trim(strings.Join(context, " "), " :")
+			}
+			return fields, nil, true
+		}
+		if t, ok := bracketTimestamp(token); ok && i == 0 {
+			fields["time"] = t
+			continue
+		}
+		context = append(context, string(token))
+	}
+	return nil, nil, false
+}
+
+// bracketTimestamp returns the timestamp as RFC 3339, timestamps without a
+// zone are in local time.
+func bracketTimestamp(token []byte) (string, bool) {
+	if !bytes.Equal(bracketTime.Find(token), token) {
+		return "", false
+	}
+	value := strings.Replace(strings.Replace(string(token), ",", ".", 1), " ", "T", 1)
+	for _, layout := range bracketTimeFormats {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339Nano), true
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", value, time.Local)
+	if err != nil {
+		return "", false
+	}
+	return t.Format(time.RFC3339Nano), true
+}
+
+// AddBracketLevels adds the markers of a mapping like DEPEND=warning,I=info
+// to the BracketLevels.
+func AddBracketLevels(mapping string) error {
+	for _, pair := range strings.Split(mapping, ",") {
+		marker, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(marker) == "" || strings.TrimSpace(level) == "" {
+			return fmt.Errorf("%q is not a MARKER=level pair", pair)
+		}
+		BracketLevels[strings.ToUpper(strings.TrimSpace(marker))] = strings.ToUpper(strings.TrimSpace(level))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBracketParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		expect map[string]interface{}
+	}{
+		{
+			"timestamp and level",
+			"[2023-06-16T12:51:36Z] [error] disk sdb failed",
+			map[string]interface{}{"time": "2023-06-16T12:51:36Z", "level": "ERROR", "msg": "disk sdb failed"},
+		},
+		{
+			"ceph",
+			"2023-06-16T12:51:36.123456+0000 mon.a (mon.0) 123 : cluster [WRN] Health check failed",
+			map[string]interface{}{"time": "2023-06-16T12:51:36.123456Z", "level": "WARNING", "context": "mon.a (mon.0) 123 : cluster", "msg": "Health check failed"},
+		},
+		{
+			"systemd",
+			"[  OK  ] Started Journal Service.",
+			map[string]interface{}{"level": "INFO", "msg": "Started Journal Service."},
+		},
+		{
+			"level in the message",
+			"retrying [ERROR] later",
+			nil,
+		},
+		{
+			"unknown marker",
+			"[2023-06-16T12:51:36Z] [main] started",
+			nil,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, _, ok := (&BracketParser{}).Parse([]byte(test.line))
+			if ok != (test.expect != nil) {
+				t.Fatalf("Parse() ok = %v", ok)
+			}
+			if ok && !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestAddBracketLevels(t *testing.T) {
+	if err := AddBracketLevels("XYZ=notice"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := BracketLevels["XYZ"], "NOTICE"; got != want {
+		t.Errorf("BracketLevels[XYZ] = %v, want %v", got, want)
+	}
+	if err := AddBracketLevels("XYZ"); err == nil {
+		t.Errorf("AddBracketLevels(XYZ) = nil, want error")
+	}
+}
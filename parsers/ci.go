@@ -0,0 +1,76 @@
+package parsers
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ciTimestamp matches the timestamp CI systems (like GitHub Actions) prefix
+// every line of a downloaded log with.
+var ciTimestamp = regexp.MustCompile(`^(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?Z) `)
+
+// ciCommands maps the ##[command] prefixes of GitHub Actions logs to a level.
+var ciCommands = map[string]string{
+	"##[error]":   "ERROR",
+	"##[warning]": "WARNING",
+	"##[notice]":  "NOTICE",
+	"##[debug]":   "DEBUG",
+	"##[command]": "",
+}
+
+// CITimestamp returns the time a line of a CI log is prefixed with, and the
+// remainder of the line.
+func CITimestamp(line []byte) (time.Time, []byte, bool) {
+	match := ciTimestamp.FindSubmatch(line)
+	if match == nil {
+		return time.Time{}, line, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(match[1]))
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return t, line[len(match[0]):], true
+}
+
+// CIGroup detects the markers of a group of lines, ##[group]title and
+// ##[endgroup] in logs of GitHub Actions or the ::group:: workflow commands.
+func CIGroup(line []byte) (title string, start, ok bool) {
+	_, line, _ = CITimestamp(line)
+	text := string(bytes.TrimSpace(line))
+	for _, marker := range []string{"##[group]", "::group::"} {
+		if title, ok := strings.CutPrefix(text, marker); ok {
+			return title, true, true
+		}
+	}
+	if text == "##[endgroup]" || text == "::endgroup::" {
+		return "", false, true
+	}
+	return "", false, false
+}
+
+// CIParser parses the timestamped lines of a CI log, the ##[error] and
+// ##[warning] commands of GitHub Actions are used as level.
+type CIParser struct {
+}
+
+func (p *CIParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	t, rest, ok := CITimestamp(line)
+	if !ok {
+		return nil, nil, false
+	}
+	fields := map[string]interface{}{
+		"time": t.Format(time.RFC3339Nano),
+		"msg":  string(rest),
+	}
+	for command, level := range ciCommands {
+		if message, ok := strings.CutPrefix(string(rest), command); ok {
+			fields["msg"] = message
+			if level != "" {
+				fields["level"] = level
+			}
+		}
+	}
+	return fields, nil, true
+}
@@ -0,0 +1,60 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCIParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line   string
+		expect map[string]interface{}
+	}{
+		{
+			"2023-06-16T12:51:36.1234567Z Run go test ./...",
+			map[string]interface{}{"time": "2023-06-16T12:51:36.1234567Z", "msg": "Run go test ./..."},
+		},
+		{
+			"2023-06-16T12:51:38Z ##[error]Process completed with exit code 1.",
+			map[string]interface{}{"time": "2023-06-16T12:51:38Z", "msg": "Process completed with exit code 1.", "level": "ERROR"},
+		},
+		{
+			"Run go test ./...",
+			nil,
+		},
+	}
+	for _, test := range tests {
+		got, _, ok := (&CIParser{}).Parse([]byte(test.line))
+		if ok != (test.expect != nil) {
+			t.Fatalf("Parse(%q) ok = %v", test.line, ok)
+		}
+		if ok && !reflect.DeepEqual(got, test.expect) {
+			t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+		}
+	}
+}
+
+func TestCIGroup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line  string
+		title string
+		start bool
+		ok    bool
+	}{
+		{"2023-06-16T12:51:36.1234567Z ##[group]Run actions/checkout@v3", "Run actions/checkout@v3", true, true},
+		{"::group::Build", "Build", true, true},
+		{"2023-06-16T12:51:36.1234567Z ##[endgroup]", "", false, true},
+		{"::endgroup::", "", false, true},
+		{"2023-06-16T12:51:36.1234567Z group", "", false, false},
+	}
+	for _, test := range tests {
+		title, start, ok := CIGroup([]byte(test.line))
+		if title != test.title || start != test.start || ok != test.ok {
+			t.Errorf("CIGroup(%q) = %q, %v, %v, want %q, %v, %v", test.line, title, start, ok, test.title, test.start, test.ok)
+		}
+	}
+}
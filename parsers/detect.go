@@ -0,0 +1,72 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Detector detects the format of every line and counts the lines per format
+// of every source. The parsers are always tried in order, so the patterns of
+// --pattern keep their precedence over the built-in formats.
+type Detector struct {
+	formats map[string]map[string]int
+	own     map[Parser]SourceParser
+}
+
+// NewDetector returns a Detector without any counted lines.
+func NewDetector() *Detector {
+	return &Detector{
+		formats: map[string]map[string]int{},
+		own:     map[Parser]SourceParser{},
+	}
+}
+
+// Parse runs the parsers on the line and returns the fields of the first
+// match as json.
+func (d *Detector) Parse(source string, line []byte) (json.RawMessage, []byte, bool) {
+	for _, parser := range All {
+		if template, ok := parser.(SourceParser); ok {
+			if d.own[parser] == nil {
+				d.own[parser] = template.New()
+			}
+			if fields, prefix, ok := d.own[parser].ParseSource(source, line); ok {
+				return d.result(source, parser, fields, prefix)
+			}
+			continue
+		}
+		if fields, prefix, ok := parser.Parse(line); ok {
+			return d.result(source, parser, fields, prefix)
+		}
+	}
+	d.Count(source, "text")
+	return nil, nil, false
+}
+
+func (d *Detector) result(source string, parser Parser, fields map[string]interface{}, prefix []byte) (json.RawMessage, []byte, bool) {
+	d.Count(source, FormatName(parser))
+	data, err := json.Marshal(fields)
+	return data, prefix, err == nil
+}
+
+// Count adds a line of the format to the statistics of the source, this is
+// used for the lines that don't need a parser, like json.
+func (d *Detector) Count(source, format string) {
+	if d.formats[source] == nil {
+		d.formats[source] = map[string]int{}
+	}
+	d.formats[source][format]++
+}
+
+// Formats returns the number of lines per format of every source.
+func (d *Detector) Formats() map[string]map[string]int {
+	return d.formats
+}
+
+// FormatName returns the name of the format of a parser, like accesslog for
+// the AccessLogParser.
+func FormatName(parser Parser) string {
+	name := fmt.Sprintf("%T", parser)
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.ToLower(strings.TrimSuffix(name, "Parser"))
+}
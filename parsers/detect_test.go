@@ -0,0 +1,42 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	d := NewDetector()
+	lines := []struct {
+		source string
+		line   string
+	}{
+		{"broker.log", "[2023-06-16 12:51:36,123] INFO started (kafka.server.KafkaServer)"},
+		{"ceph.log", "[2023-06-16T12:51:36Z] [WRN] slow request"},
+		{"broker.log", "[2023-06-16 12:51:37,123] WARN retrying (kafka.server.KafkaServer)"},
+		{"broker.log", "\tat kafka.Main.main(Main.java:42)"},
+		{"ceph.log", "[2023-06-16T12:51:37Z] [ERR] osd down"},
+	}
+	for _, l := range lines {
+		d.Parse(l.source, []byte(l.line))
+	}
+	d.Count("ceph.log", "json")
+
+	expect := map[string]map[string]int{
+		"broker.log": {"log4j": 2, "text": 1},
+		"ceph.log":   {"bracket": 2, "json": 1},
+	}
+	if got := d.Formats(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, expect)
+	}
+}
+
+func TestFormatName(t *testing.T) {
+	t.Parallel()
+
+	if got, want := FormatName(&AccessLogParser{}), "accesslog"; got != want {
+		t.Errorf("FormatName() = %v, want %v", got, want)
+	}
+}
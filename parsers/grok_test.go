@@ -0,0 +1,82 @@
+package parsers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGrokPatternsCompile(t *testing.T) {
+	t.Parallel()
+
+	for name := range GrokPatterns {
+		if _, err := NewPatternParser("%{" + name + ":value}"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestGrok(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+		line    string
+		expect  map[string]interface{}
+	}{
+		{
+			"timestamp and level",
+			`%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} %{GREEDYDATA:msg}`,
+			"2023-06-16T12:51:36.123Z WARN disk almost full",
+			map[string]interface{}{"time": "2023-06-16T12:51:36.123Z", "level": "WARN", "msg": "disk almost full"},
+		},
+		{
+			"types and nested fields",
+			`%{IP:[client][ip]} %{WORD:[http][method]} %{URIPATHPARAM:path} %{NUMBER:bytes:int} %{NUMBER:duration:float}`,
+			"10.0.0.1 GET /search?q=jl 1024 0.25",
+			map[string]interface{}{"client.ip": "10.0.0.1", "http.method": "GET", "path": "/search?q=jl", "bytes": int64(1024), "duration": 0.25},
+		},
+		{
+			"mixed with regex",
+			`^%{SYSLOGTIMESTAMP:time} (?P<host>\S+) %{SYSLOGPROG}: %{GREEDYDATA:msg}`,
+			"Jun 16 12:51:36 web-1 sshd[4242]: Accepted publickey for alice",
+			map[string]interface{}{"time": "Jun 16 12:51:36", "host": "web-1", "program": "sshd", "pid": "4242", "msg": "Accepted publickey for alice"},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			parser, err := NewPatternParser(test.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, ok := parser.Parse([]byte(test.line))
+			if !ok {
+				t.Fatalf("Parse() ok = %v", ok)
+			}
+			if !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestGrokErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewPatternParser(`%{NOPE:x}`); err == nil || !strings.Contains(err.Error(), "NOPE") {
+		t.Errorf("NewPatternParser() = %v, want unknown pattern error", err)
+	}
+}
+
+func TestLoadGrokPatterns(t *testing.T) {
+	err := LoadGrokPatterns(strings.NewReader("# custom patterns\n\nORDER_ID ORD-[0-9]+\nBROKEN\n"))
+	if err == nil {
+		t.Errorf("LoadGrokPatterns() = nil, want error")
+	}
+	if got, want := GrokPatterns["ORDER_ID"], "ORD-[0-9]+"; got != want {
+		t.Errorf("GrokPatterns[ORDER_ID] = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package parsers
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lambdaRuntimeLine matches the text logs of the Lambda runtimes, like
+// 2023-06-16T12:51:36.123Z	8f5a…	INFO	message
+var lambdaRuntimeLine = regexp.MustCompile(`^(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?Z)\t([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\t([A-Z]+)\t(.*)$`)
+
+// lambdaPlatformKey matches the keys of a platform line, like RequestId or
+// Max Memory Used.
+var lambdaPlatformKey = regexp.MustCompile(`(?:^|\s)([A-Z][A-Za-z]*(?: [A-Z][A-Za-z]*)*): `)
+
+var lambdaPlatformEvents = []string{"START", "END", "REPORT", "INIT_START"}
+
+// lambdaReportFields maps the metrics of a REPORT line to their field names.
+var lambdaReportFields = map[string]string{
+	"Duration":        "duration_ms",
+	"Billed Duration": "billed_duration_ms",
+	"Init Duration":   "init_duration_ms",
+	"Memory Size":     "memory_size_mb",
+	"Max Memory Used": "max_memory_used_mb",
+}
+
+// LambdaParser parses the platform lines of AWS Lambda (START, END and
+// REPORT) and the text logs of its runtimes, the request id is stored as
+// requestId, like in Lambda's own json log format.
+type LambdaParser struct {
+}
+
+func (p *LambdaParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	if match := lambdaRuntimeLine.FindSubmatch(line); match != nil {
+		return map[string]interface{}{
+			"time":      string(match[1]),
+			"requestId": string(match[2]),
+			"level":     string(match[3]),
+			"msg":       string(match[4]),
+		}, nil, true
+	}
+
+	for _, event := range lambdaPlatformEvents {
+		start := bytes.Index(line, []byte(event+" "))
+		if start == -1 || (start > 0 && line[start-1] != ' ' && line[start-1] != '\t') {
+			continue
+		}
+		fields := lambdaPlatformFields(string(line[start+len(event)+1:]))
+		if event != "INIT_START" && fields["requestId"] == nil {
+			continue
+		}
+		fields["msg"] = event
+		fields["level"] = "INFO"
+		if status, ok := fields["status"].(string); ok && status != "success" {
+			fields["level"] = "ERROR"
+		}
+		return fields, prefix(line, start), true
+	}
+	return nil, nil, false
+}
+
+// lambdaPlatformFields parses the "Key: value" pairs of a platform line.
+func lambdaPlatformFields(text string) map[string]interface{} {
+	fields := map[string]interface{}{}
+	matches := lambdaPlatformKey.FindAllStringSubmatchIndex(text, -1)
+	for i, match := range matches {
+		key := text[match[2]:match[3]]
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		value := strings.TrimSpace(text[match[1]:end])
+		switch key {
+		case "RequestId":
+			fields["requestId"] = value
+		case "Version", "Runtime Version":
+			fields["version"] = value
+		case "Status":
+			fields["status"] = value
+		default:
+			if name, ok := lambdaReportFields[key]; ok {
+				number, _, _ := strings.Cut(value, " ")
+				if f, err := strconv.ParseFloat(number, 64); err == nil {
+					fields[name] = f
+					continue
+				}
+			}
+			fields[strings.ToLower(strings.ReplaceAll(key, " ", "_"))] = value
+		}
+	}
+	return fields
+}
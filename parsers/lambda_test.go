@@ -0,0 +1,68 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLambdaParser(t *testing.T) {
+	t.Parallel()
+
+	const id = "8f5a3e1c-2b7d-4c1e-9f3a-1d2e3f4a5b6c"
+	tests := []struct {
+		name   string
+		line   string
+		expect map[string]interface{}
+		prefix string
+	}{
+		{
+			"start",
+			"START RequestId: " + id + " Version: $LATEST",
+			map[string]interface{}{"msg": "START", "level": "INFO", "requestId": id, "version": "$LATEST"},
+			"",
+		},
+		{
+			"report",
+			"2023-06-16T12:51:36.123Z REPORT RequestId: " + id + "\tDuration: 2.33 ms\tBilled Duration: 3 ms\tMemory Size: 128 MB\tMax Memory Used: 67 MB\tInit Duration: 150.12 ms\t",
+			map[string]interface{}{
+				"msg": "REPORT", "level": "INFO", "requestId": id,
+				"duration_ms": 2.33, "billed_duration_ms": 3.0, "memory_size_mb": 128.0, "max_memory_used_mb": 67.0, "init_duration_ms": 150.12,
+			},
+			"2023-06-16T12:51:36.123Z ",
+		},
+		{
+			"timeout",
+			"REPORT RequestId: " + id + "\tDuration: 3000.00 ms\tStatus: timeout",
+			map[string]interface{}{"msg": "REPORT", "level": "ERROR", "requestId": id, "duration_ms": 3000.0, "status": "timeout"},
+			"",
+		},
+		{
+			"runtime",
+			"2023-06-16T12:51:36.123Z\t" + id + "\tWARN\tretrying: connection reset",
+			map[string]interface{}{"time": "2023-06-16T12:51:36.123Z", "level": "WARN", "requestId": id, "msg": "retrying: connection reset"},
+			"",
+		},
+		{
+			"no request id",
+			"END of the line",
+			nil,
+			"",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, prefix, ok := (&LambdaParser{}).Parse([]byte(test.line))
+			if ok != (test.expect != nil) {
+				t.Fatalf("Parse() ok = %v", ok)
+			}
+			if ok && !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+			}
+			if string(prefix) != test.prefix {
+				t.Errorf("prefix = %q, want %q", prefix, test.prefix)
+			}
+		})
+	}
+}
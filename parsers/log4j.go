@@ -0,0 +1,64 @@
+package parsers
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// kafkaLine matches the log4j layout of Kafka brokers, "[%d] %p %m (%c)",
+// like [2023-06-16 12:51:36,123] INFO [Controller id=1] message (kafka.controller)
+var kafkaLine = regexp.MustCompile(`^\[(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d,\d{3})\] ([A-Z]+) (.*?)(?: \(([\w.$]+)\))?$`)
+
+// zookeeperLine matches the log4j layout of ZooKeeper,
+// "%d{ISO8601} [myid:%X{myid}] - %-5p [%t:%C{1}@%L] - %m", like
+// 2023-06-16 12:51:36,123 [myid:1] - INFO  [main:QuorumPeerConfig@174] - message
+var zookeeperLine = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d,\d{3}) \[myid:(\d*)\] - ([A-Z]+) +\[(.*?):([\w$]+)@(\d+)\] - (.*)$`)
+
+// log4jTimeFormat is the ISO8601 date format of log4j, which is written in
+// local time.
+const log4jTimeFormat = "2006-01-02 15:04:05,000"
+
+// Log4jParser parses the text logs of Kafka brokers and ZooKeeper, the logger
+// (or class) of an entry is stored as logger.
+type Log4jParser struct {
+}
+
+func (p *Log4jParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	if match := kafkaLine.FindSubmatch(line); match != nil {
+		fields := map[string]interface{}{
+			"time":  log4jTime(string(match[1])),
+			"level": string(match[2]),
+			"msg":   string(match[3]),
+		}
+		if len(match[4]) > 0 {
+			fields["logger"] = string(match[4])
+		}
+		return fields, nil, true
+	}
+	if match := zookeeperLine.FindSubmatch(line); match != nil {
+		fields := map[string]interface{}{
+			"time":   log4jTime(string(match[1])),
+			"level":  string(match[3]),
+			"thread": string(match[4]),
+			"logger": string(match[5]),
+			"line":   string(match[6]),
+			"msg":    strings.TrimSpace(string(match[7])),
+		}
+		if len(match[2]) > 0 {
+			fields["myid"] = string(match[2])
+		}
+		return fields, nil, true
+	}
+	return nil, nil, false
+}
+
+// log4jTime converts the local time of log4j to RFC 3339, unknown times are
+// returned as is.
+func log4jTime(value string) string {
+	t, err := time.ParseInLocation(log4jTimeFormat, value, time.Local)
+	if err != nil {
+		return value
+	}
+	return t.Format(time.RFC3339Nano)
+}
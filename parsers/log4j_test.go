@@ -0,0 +1,57 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLog4jParser(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2023, 6, 16, 12, 51, 36, 123000000, time.Local).Format(time.RFC3339Nano)
+	tests := []struct {
+		name   string
+		line   string
+		expect map[string]interface{}
+	}{
+		{
+			"kafka",
+			"[2023-06-16 12:51:36,123] INFO [Controller id=1] Starting up (kafka.controller.KafkaController)",
+			map[string]interface{}{"time": ts, "level": "INFO", "msg": "[Controller id=1] Starting up", "logger": "kafka.controller.KafkaController"},
+		},
+		{
+			"kafka without logger",
+			"[2023-06-16 12:51:36,123] ERROR Fatal error during startup",
+			map[string]interface{}{"time": ts, "level": "ERROR", "msg": "Fatal error during startup"},
+		},
+		{
+			"zookeeper",
+			"2023-06-16 12:51:36,123 [myid:1] - WARN  [QuorumPeer[myid=1](plain=0.0.0.0:2181):Follower@129] - Exception when following the leader",
+			map[string]interface{}{"time": ts, "level": "WARN", "thread": "QuorumPeer[myid=1](plain=0.0.0.0:2181)", "logger": "Follower", "line": "129", "myid": "1", "msg": "Exception when following the leader"},
+		},
+		{
+			"zookeeper without id",
+			"2023-06-16 12:51:36,123 [myid:] - INFO  [main:QuorumPeerConfig@174] - Reading configuration",
+			map[string]interface{}{"time": ts, "level": "INFO", "thread": "main", "logger": "QuorumPeerConfig", "line": "174", "msg": "Reading configuration"},
+		},
+		{
+			"other",
+			"[2023-06-16] INFO something",
+			nil,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fields, _, ok := (&Log4jParser{}).Parse([]byte(test.line))
+			if ok != (test.expect != nil) {
+				t.Fatalf("ok = %v, want %v", ok, test.expect != nil)
+			}
+			if ok && !reflect.DeepEqual(fields, test.expect) {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", fields, test.expect)
+			}
+		})
+	}
+}
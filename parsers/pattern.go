@@ -0,0 +1,89 @@
+package parsers
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// PatternParser parses the lines of a custom text format with a regular
+// expression, its named capture groups become the fields of the entry. The
+// expression can use grok patterns, like %{LOGLEVEL:level}.
+type PatternParser struct {
+	pattern *regexp.Regexp
+	fields  []grokField
+}
+
+// NewPatternParser compiles the regular expression, which must have at least
+// one named capture group or named grok pattern.
+func NewPatternParser(expr string) (*PatternParser, error) {
+	expanded, grokFields, err := expandGrok(expr)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, err
+	}
+	p := &PatternParser{pattern: pattern}
+	named := false
+	for _, name := range pattern.SubexpNames() {
+		field, ok := grokFields[name]
+		if !ok {
+			field = grokField{name: name}
+		}
+		named = named || field.name != ""
+		p.fields = append(p.fields, field)
+	}
+	if !named {
+		return nil, errors.New("pattern has no named capture groups, like (?P<msg>.*)")
+	}
+	return p, nil
+}
+
+// Parse returns the non-empty named groups of the first match, the text
+// before the match is returned as prefix.
+func (p *PatternParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	match := p.pattern.FindSubmatchIndex(line)
+	if match == nil {
+		return nil, nil, false
+	}
+	fields := map[string]interface{}{}
+	for i, field := range p.fields {
+		if field.name != "" && match[2*i] >= 0 && match[2*i] < match[2*i+1] {
+			fields[field.name] = field.value(string(line[match[2*i]:match[2*i+1]]))
+		}
+	}
+	return fields, prefix(line, match[0]), true
+}
+
+// value converts the captured text to the type of the field, text that isn't
+// a valid number is kept as is.
+func (f grokField) value(text string) interface{} {
+	switch f.kind {
+	case "int":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "float":
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return n
+		}
+	}
+	return text
+}
+
+// AddPatterns compiles the patterns and adds them in front of the built-in
+// parsers, so custom formats take precedence.
+func AddPatterns(exprs []string) error {
+	var custom []Parser
+	for _, expr := range exprs {
+		parser, err := NewPatternParser(expr)
+		if err != nil {
+			return err
+		}
+		custom = append(custom, parser)
+	}
+	All = append(custom, All...)
+	return nil
+}
@@ -0,0 +1,64 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPatternParser(t *testing.T) {
+	t.Parallel()
+
+	parser, err := NewPatternParser(`(?P<time>\S+) (?P<level>\w+) (?:\[(?P<thread>\w+)\] )?(?P<msg>.*)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		line   string
+		expect map[string]interface{}
+		prefix string
+	}{
+		{
+			"all groups",
+			"12:51:36 INFO [main] started",
+			map[string]interface{}{"time": "12:51:36", "level": "INFO", "thread": "main", "msg": "started"},
+			"",
+		},
+		{
+			"optional group",
+			"12:51:36 WARN disk low",
+			map[string]interface{}{"time": "12:51:36", "level": "WARN", "msg": "disk low"},
+			"",
+		},
+		{
+			"no match",
+			"started",
+			nil,
+			"",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, prefix, ok := parser.Parse([]byte(test.line))
+			if ok != (test.expect != nil) {
+				t.Fatalf("Parse() ok = %v", ok)
+			}
+			if ok && !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+			}
+			if string(prefix) != test.prefix {
+				t.Errorf("prefix = %q, want %q", prefix, test.prefix)
+			}
+		})
+	}
+}
+
+func TestNewPatternParserWithoutGroups(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewPatternParser(`\S+ (\w+)`); err == nil {
+		t.Errorf("NewPatternParser() = nil, want error")
+	}
+}
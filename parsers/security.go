@@ -0,0 +1,203 @@
+package parsers
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cefExtensionKey matches the keys of the key=value pairs in the extension
+// of a CEF record, an escaped \= is part of a value.
+var cefExtensionKey = regexp.MustCompile(`(?:^|\s)([\w.\[\]-]+)=`)
+
+// CEFParser parses ArcSight Common Event Format records, like
+// CEF:0|Vendor|Product|1.0|100|Worm stopped|10|src=10.0.0.1 act=blocked
+type CEFParser struct {
+}
+
+func (p *CEFParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	start := bytes.Index(line, []byte("CEF:"))
+	if start == -1 {
+		return nil, nil, false
+	}
+	header := splitEscaped(string(line[start+len("CEF:"):]), '|', 8)
+	if len(header) != 8 {
+		return nil, nil, false
+	}
+
+	fields := map[string]interface{}{}
+	for key, value := range cefExtension(header[7]) {
+		if key == "msg" {
+			key = "details"
+		}
+		fields[key] = value
+	}
+	fields["device_vendor"] = header[1]
+	fields["device_product"] = header[2]
+	fields["device_version"] = header[3]
+	fields["signature_id"] = header[4]
+	fields["msg"] = header[5]
+	fields["level"] = securityLevel(header[6])
+	if t, ok := parseDeviceTime(fields["rt"]); ok {
+		fields["time"] = t
+		delete(fields, "rt")
+	}
+	return fields, prefix(line, start), true
+}
+
+// LEEFParser parses IBM QRadar Log Event Extended Format records, in
+// version 1.0 (tab delimited attributes) and 2.0 (with the delimiter in the
+// header).
+type LEEFParser struct {
+}
+
+func (p *LEEFParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	start := bytes.Index(line, []byte("LEEF:"))
+	if start == -1 {
+		return nil, nil, false
+	}
+	header := splitEscaped(string(line[start+len("LEEF:"):]), '|', 7)
+	if len(header) < 6 {
+		return nil, nil, false
+	}
+
+	delimiter, attributes := "\t", strings.Join(header[5:], "|")
+	if header[0] != "1.0" && len(header) == 7 {
+		delimiter, attributes = header[5], header[6]
+		if hex, ok := strings.CutPrefix(strings.ToLower(delimiter), "x"); ok && len(hex) > 0 {
+			if code, err := strconv.ParseUint(hex, 16, 8); err == nil {
+				delimiter = string(rune(code))
+			}
+		}
+	}
+
+	fields := map[string]interface{}{}
+	for _, attribute := range strings.Split(attributes, delimiter) {
+		if key, value, ok := strings.Cut(attribute, "="); ok && key != "" {
+			fields[key] = value
+		}
+	}
+	fields["device_vendor"] = header[1]
+	fields["device_product"] = header[2]
+	fields["device_version"] = header[3]
+	fields["msg"] = header[4]
+	if sev, ok := fields["sev"].(string); ok {
+		fields["level"] = securityLevel(sev)
+		delete(fields, "sev")
+	}
+	if t, ok := parseDeviceTime(fields["devTime"]); ok {
+		fields["time"] = t
+		delete(fields, "devTime")
+		delete(fields, "devTimeFormat")
+	}
+	return fields, prefix(line, start), true
+}
+
+// splitEscaped splits text into at most n parts on the separator, which may
+// be escaped with a backslash.
+func splitEscaped(text string, separator byte, n int) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch {
+		case len(parts) == n-1:
+			part.WriteString(text[i:])
+			i = len(text)
+		case text[i] == '\\' && i+1 < len(text) && (text[i+1] == separator || text[i+1] == '\\'):
+			part.WriteByte(text[i+1])
+			i++
+		case text[i] == separator:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(text[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// cefExtension parses the space separated key=value pairs, values may
+// contain spaces and escaped characters.
+func cefExtension(extension string) map[string]string {
+	result := map[string]string{}
+	matches := cefExtensionKey.FindAllStringSubmatchIndex(extension, -1)
+	unescape := strings.NewReplacer(`\=`, `=`, `\\`, `\`, `\n`, "\n", `\r`, "\r")
+	for i := 0; i < len(matches); i++ {
+		// keys following an escaped \= are part of the previous value
+		if matches[i][2] > 0 && extension[matches[i][2]-1] == '\\' {
+			continue
+		}
+		key := extension[matches[i][2]:matches[i][3]]
+		end := len(extension)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		result[key] = unescape.Replace(strings.TrimSpace(extension[matches[i][1]:end]))
+	}
+	return result
+}
+
+// securityLevel maps the severity of a security event, a score from 0 to 10
+// or a name like Low or Very-High, to a log level.
+func securityLevel(severity string) string {
+	score, err := strconv.Atoi(severity)
+	if err != nil {
+		switch strings.ToLower(severity) {
+		case "low":
+			score = 3
+		case "medium":
+			score = 6
+		case "high":
+			score = 8
+		case "very-high":
+			score = 10
+		default:
+			return severity
+		}
+	}
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "ERROR"
+	case score >= 4:
+		return "WARNING"
+	}
+	return "INFO"
+}
+
+// deviceTimeFormats are the common formats of the time of the device
+// (rt and devTime), besides milliseconds since the epoch.
+var deviceTimeFormats = []string{
+	"Jan 02 2006 15:04:05",
+	"Jan 02 2006 15:04:05 MST",
+	"Jan 02 2006 15:04:05.000",
+	"Jan 02 2006 15:04:05.000 MST",
+	time.RFC3339Nano,
+}
+
+func parseDeviceTime(value interface{}) (string, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return "", false
+	}
+	if millis, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return time.UnixMilli(millis).UTC().Format(time.RFC3339Nano), true
+	}
+	for _, layout := range deviceTimeFormats {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.Format(time.RFC3339Nano), true
+		}
+	}
+	return "", false
+}
+
+// prefix returns the part of the line before start, if any.
+func prefix(line []byte, start int) []byte {
+	if start == 0 {
+		return nil
+	}
+	return line[:start]
+}
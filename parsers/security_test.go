@@ -0,0 +1,71 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCEFParser(t *testing.T) {
+	t.Parallel()
+
+	line := `Jun 16 12:51:36 fw01 CEF:0|Security|threat\|manager|1.0|100|worm stopped|Very-High|src=10.0.0.1 msg=Detected a threat\=worm. No action needed. rt=1686919896000`
+	got, prefix, ok := (&CEFParser{}).Parse([]byte(line))
+	if !ok {
+		t.Fatalf("Parse() ok = false")
+	}
+	expect := map[string]interface{}{
+		"time": "2023-06-16T12:51:36Z", "level": "CRITICAL", "msg": "worm stopped",
+		"device_vendor": "Security", "device_product": "threat|manager", "device_version": "1.0", "signature_id": "100",
+		"src": "10.0.0.1", "details": "Detected a threat=worm. No action needed.",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, expect)
+	}
+	if string(prefix) != "Jun 16 12:51:36 fw01 " {
+		t.Errorf("prefix = %q", prefix)
+	}
+
+	if _, _, ok := (&CEFParser{}).Parse([]byte("CEF:0|too|few")); ok {
+		t.Errorf("Parse() of an incomplete header succeeded")
+	}
+}
+
+func TestLEEFParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		expect map[string]interface{}
+	}{
+		{
+			"version 1.0",
+			"LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tsev=5\tdevTime=Jun 16 2023 12:51:37",
+			map[string]interface{}{
+				"time": "2023-06-16T12:51:37Z", "level": "WARNING", "msg": "15345",
+				"device_vendor": "Microsoft", "device_product": "MSExchange", "device_version": "4.0 SP1", "src": "192.0.2.0",
+			},
+		},
+		{
+			"version 2.0",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|x5E|src=10.0.1.8^sev=2^cat=scan",
+			map[string]interface{}{
+				"level": "INFO", "msg": "41", "cat": "scan",
+				"device_vendor": "Lancope", "device_product": "StealthWatch", "device_version": "1.0", "src": "10.0.1.8",
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, _, ok := (&LEEFParser{}).Parse([]byte(test.line))
+			if !ok {
+				t.Fatalf("Parse() ok = false")
+			}
+			if !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, test.expect)
+			}
+		})
+	}
+}
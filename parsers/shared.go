@@ -0,0 +1,48 @@
+// Package parsers turns well known plain text log formats into structured
+// entries, by converting the line to json before it is processed.
+package parsers
+
+import "encoding/json"
+
+// Parser extracts the fields of a line of text, ok is false when the line
+// isn't in the format of the parser. The prefix is the part of the line that
+// precedes the parsed log record, like a syslog header.
+type Parser interface {
+	Parse(line []byte) (fields map[string]interface{}, prefix []byte, ok bool)
+}
+
+// SourceParser is a Parser that keeps a state per source, like the header of
+// a file. The instances in All are only templates: every Detector parses
+// with its own instance, made by New.
+type SourceParser interface {
+	Parser
+	ParseSource(source string, line []byte) (fields map[string]interface{}, prefix []byte, ok bool)
+	New() SourceParser
+}
+
+var All = []Parser{
+	&AccessLogParser{},
+	&CEFParser{},
+	&LEEFParser{},
+	&LambdaParser{},
+	&VPCFlowLogParser{},
+	&Log4jParser{},
+	&CIParser{},
+	&BracketParser{},
+}
+
+// Parse runs the parsers on the line and returns the fields of the first
+// match as json. The line is parsed on its own, without the state of the
+// lines before it.
+func Parse(line []byte) (json.RawMessage, []byte, bool) {
+	for _, parser := range All {
+		if source, ok := parser.(SourceParser); ok {
+			parser = source.New()
+		}
+		if fields, prefix, ok := parser.Parse(line); ok {
+			data, err := json.Marshal(fields)
+			return data, prefix, err == nil
+		}
+	}
+	return nil, nil, false
+}
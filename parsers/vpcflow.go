@@ -0,0 +1,104 @@
+package parsers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vpcFlowLogFields are the fields of the default (version 2) format.
+var vpcFlowLogFields = strings.Fields("version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status")
+
+var ipProtocols = map[string]string{
+	"1":  "ICMP",
+	"6":  "TCP",
+	"17": "UDP",
+	"58": "ICMPv6",
+}
+
+// VPCFlowLogParser parses the space separated records of AWS VPC Flow Logs.
+// Records in a custom format are parsed after the header line of their
+// source, which names the fields, has been read.
+type VPCFlowLogParser struct {
+	headers map[string][]string
+}
+
+// New returns a parser that hasn't read any headers.
+func (p *VPCFlowLogParser) New() SourceParser {
+	return &VPCFlowLogParser{}
+}
+
+func (p *VPCFlowLogParser) Parse(line []byte) (map[string]interface{}, []byte, bool) {
+	return p.ParseSource("", line)
+}
+
+func (p *VPCFlowLogParser) ParseSource(source string, line []byte) (map[string]interface{}, []byte, bool) {
+	values := strings.Fields(string(line))
+	if len(values) > 0 && values[0] == "version" {
+		for _, value := range values {
+			if value == "srcaddr" {
+				if p.headers == nil {
+					p.headers = map[string][]string{}
+				}
+				p.headers[source] = values
+				break
+			}
+		}
+		return nil, nil, false
+	}
+
+	header := p.headers[source]
+	if header == nil {
+		header = vpcFlowLogFields
+	}
+	if len(values) != len(header) {
+		return nil, nil, false
+	}
+	record := map[string]string{}
+	for i, name := range header {
+		record[name] = values[i]
+	}
+	action, status := record["action"], record["log-status"]
+	if action != "ACCEPT" && action != "REJECT" && action != "-" || status != "OK" && status != "NODATA" && status != "SKIPDATA" {
+		return nil, nil, false
+	}
+
+	fields := map[string]interface{}{}
+	for name, value := range record {
+		if value != "-" {
+			fields[name] = value
+		}
+	}
+	for _, name := range []string{"version", "srcaddr", "srcport", "dstaddr", "dstport", "protocol", "action", "log-status", "start", "end"} {
+		delete(fields, name)
+	}
+	for _, name := range []string{"packets", "bytes"} {
+		if n, err := strconv.Atoi(record[name]); err == nil {
+			fields[name] = n
+		}
+	}
+
+	start, err := strconv.ParseInt(record["start"], 10, 64)
+	if err == nil {
+		fields["time"] = time.Unix(start, 0).UTC().Format(time.RFC3339)
+		if end, err := strconv.ParseInt(record["end"], 10, 64); err == nil {
+			fields["duration"] = (time.Duration(end-start) * time.Second).String()
+		}
+	}
+
+	protocol := record["protocol"]
+	if name, ok := ipProtocols[protocol]; ok {
+		protocol = name
+	}
+	fields["msg"] = strings.Join([]string{action, record["srcaddr"] + ":" + record["srcport"], "→", record["dstaddr"] + ":" + record["dstport"], protocol}, " ")
+	switch {
+	case status != "OK":
+		fields["level"] = "DEBUG"
+		fields["msg"] = status
+	case action == "REJECT":
+		fields["level"] = "WARN"
+	default:
+		fields["level"] = "INFO"
+	}
+	return fields, nil, true
+}
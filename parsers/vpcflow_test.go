@@ -0,0 +1,51 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVPCFlowLogParser(t *testing.T) {
+	t.Parallel()
+
+	p := &VPCFlowLogParser{}
+	got, _, ok := p.Parse([]byte("2 123456789010 eni-1235b8ca123456789 172.31.9.69 172.31.9.12 49761 3389 6 20 4249 1418530010 1418530070 REJECT OK"))
+	if !ok {
+		t.Fatalf("Parse() ok = false")
+	}
+	expect := map[string]interface{}{
+		"time": "2014-12-14T04:06:50Z", "level": "WARN", "msg": "REJECT 172.31.9.69:49761 → 172.31.9.12:3389 TCP",
+		"account-id": "123456789010", "interface-id": "eni-1235b8ca123456789", "packets": 20, "bytes": 4249, "duration": "1m0s",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("\n\tnot match: %v\n\t   expect: %v\n", got, expect)
+	}
+
+	// a custom format is read from the header:
+	if _, _, ok := p.Parse([]byte("version srcaddr dstaddr srcport dstport protocol action log-status")); ok {
+		t.Errorf("Parse() of the header succeeded")
+	}
+	got, _, ok = p.Parse([]byte("5 10.0.0.1 10.0.0.2 443 50000 17 ACCEPT OK"))
+	if !ok {
+		t.Fatalf("Parse() of a custom format ok = false")
+	}
+	if got, want := got["msg"], "ACCEPT 10.0.0.1:443 → 10.0.0.2:50000 UDP"; got != want {
+		t.Errorf("msg = %v, want %v", got, want)
+	}
+}
+
+func TestVPCFlowLogHeaderPerSource(t *testing.T) {
+	t.Parallel()
+
+	d := NewDetector()
+	d.Parse("custom.log", []byte("version srcaddr dstaddr srcport dstport protocol action log-status"))
+	if _, _, ok := d.Parse("custom.log", []byte("5 10.0.0.1 10.0.0.2 443 50000 17 ACCEPT OK")); !ok {
+		t.Errorf("Parse() of a custom format ok = false")
+	}
+	if _, _, ok := d.Parse("other.log", []byte("5 10.0.0.1 10.0.0.2 443 50000 17 ACCEPT OK")); ok {
+		t.Errorf("Parse() used the header of another source")
+	}
+	if _, _, ok := NewDetector().Parse("custom.log", []byte("5 10.0.0.1 10.0.0.2 443 50000 17 ACCEPT OK")); ok {
+		t.Errorf("Parse() used the header of another detector")
+	}
+}
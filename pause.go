@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/koenbollen/jl/stream"
+)
+
+// pausedWriter holds back the output of a session while it's paused through
+// the control socket. The output is spooled, within the memory budget, and
+// written when the session is resumed.
+type pausedWriter struct {
+	mu     sync.Mutex
+	output io.Writer
+	budget *stream.Budget
+	spool  *stream.Spool
+	lines  int
+}
+
+func newPausedWriter(output io.Writer, budget *stream.Budget) *pausedWriter {
+	return &pausedWriter{output: output, budget: budget}
+}
+
+func (w *pausedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.spool == nil {
+		return w.output.Write(p)
+	}
+	w.lines += bytes.Count(p, []byte("\n"))
+	if err := w.spool.Add(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// pause holds back the output until resume is called.
+func (w *pausedWriter) pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.spool == nil {
+		w.spool = stream.NewSpool(w.budget)
+		w.lines = 0
+	}
+}
+
+// resume writes the output that was held back, after a line with the number
+// of lines that arrived while paused, and returns that number.
+func (w *pausedWriter) resume() (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.spool == nil {
+		return 0, nil
+	}
+	spool, lines := w.spool, w.lines
+	w.spool = nil
+	defer spool.Close()
+	if _, err := fmt.Fprintf(w.output, "── resumed, %d %s arrived while paused ──\n", lines, plural(lines, "line", "lines")); err != nil {
+		return lines, err
+	}
+	return lines, spool.Each(func(p []byte) error {
+		_, err := w.output.Write(p)
+		return err
+	})
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
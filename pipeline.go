@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pipelineSections are the sections of a pipeline file. The items of inputs
+// are files (or urls), the items of the other sections are options.
+var pipelineSections = []string{"inputs", "parsers", "filters", "transforms", "outputs"}
+
+// pipelineFile returns the file given with --pipeline, if any.
+func pipelineFile(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if file, ok := strings.CutPrefix(arg, "--pipeline="); ok {
+			return file
+		}
+		if arg == "--pipeline" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// readPipeline returns the arguments of a pipeline file, a small subset of
+// yaml with a list of items per section:
+//
+//	inputs:
+//	  - api.log
+//	filters:
+//	  - level: warn
+//	  - group-by-unit: true
+//
+// An item like level: warn is the option --level=warn, an option that is
+// true is a flag.
+func readPipeline(file string) (options, files []string, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			name, rest, ok := strings.Cut(trimmed, ":")
+			if !ok || strings.TrimSpace(rest) != "" || !slices.Contains(pipelineSections, name) {
+				return nil, nil, fmt.Errorf("%s:%d: expected one of the sections %s", file, n, strings.Join(pipelineSections, ", "))
+			}
+			section = name
+			continue
+		}
+		item, ok := strings.CutPrefix(trimmed, "- ")
+		if !ok || section == "" {
+			return nil, nil, fmt.Errorf("%s:%d: expected a list item of a section", file, n)
+		}
+		key, value, isOption := strings.Cut(item, ": ")
+		if strings.HasSuffix(item, ":") {
+			key, value, isOption = strings.TrimSuffix(item, ":"), "", true
+		}
+		if !isOption && section == "inputs" {
+			value, err := pipelineValue(item)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s:%d: %v", file, n, err)
+			}
+			files = append(files, value)
+			continue
+		}
+		if !isOption {
+			key, value = item, "true"
+		}
+		value, err := pipelineValue(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %v", file, n, err)
+		}
+		switch value {
+		case "true":
+			options = append(options, "--"+key)
+		case "false":
+		default:
+			options = append(options, "--"+key+"="+value)
+		}
+	}
+	return options, files, scanner.Err()
+}
+
+// pipelineValue unquotes a single or double quoted value, or strips the
+// comment of a plain one.
+func pipelineValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("unterminated quote in %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	if i := strings.Index(value, " #"); i != -1 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
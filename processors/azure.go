@@ -0,0 +1,35 @@
+package processors
+
+import (
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// azureSeverities maps the numeric severityLevel of Azure Monitor and
+// Application Insights.
+var azureSeverities = map[int64]string{
+	0: "DEBUG", // Verbose
+	1: "INFO",
+	2: "WARNING",
+	3: "ERROR",
+	4: "CRITICAL",
+}
+
+// AzureProcessor handles the NDJSON exports of Azure Monitor and Application
+// Insights, the customDimensions are shown as regular fields.
+type AzureProcessor struct {
+}
+
+func (p *AzureProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "severityLevel").Type == gjson.Number
+}
+
+func (p *AzureProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if severity, ok := azureSeverities[gjson.GetBytes(line.JSON, "severityLevel").Int()]; ok {
+		entry.Severity = severity
+		entry.ExcludeFields = append(entry.ExcludeFields, "severityLevel")
+	}
+	entry.FlattenFields = append(entry.FlattenFields, "customDimensions")
+	return nil
+}
@@ -0,0 +1,20 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestAzure(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &AzureProcessor{}, `{"time":"2023-06-16T12:51:36Z","severityLevel":0,"message":"cache miss","customDimensions":{"Key":"user:42"}}`)
+	if got, want := entry.Severity, "DEBUG"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "severityLevel"), true; got != want {
+		t.Errorf("entry.ExcludeFields['severityLevel'] = %v, want %v", got, want)
+	}
+	if got, want := has(entry.FlattenFields, "customDimensions"), true; got != want {
+		t.Errorf("entry.FlattenFields['customDimensions'] = %v, want %v", got, want)
+	}
+}
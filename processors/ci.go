@@ -0,0 +1,26 @@
+package processors
+
+import (
+	"github.com/koenbollen/jl/parsers"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// CIProcessor strips the timestamp a CI system prefixes every line of its
+// log with, which is used when the entry has no time itself.
+type CIProcessor struct {
+}
+
+func (p *CIProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	_, rest, ok := parsers.CITimestamp(line.Prefix)
+	return ok && len(rest) == 0
+}
+
+func (p *CIProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	t, _, _ := parsers.CITimestamp(line.Prefix)
+	if entry.Timestamp == nil || entry.Timestamp.IsZero() {
+		entry.Timestamp = &t
+	}
+	line.Prefix = nil
+	return nil
+}
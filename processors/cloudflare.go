@@ -0,0 +1,65 @@
+package processors
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var cloudflareExcludes = []string{
+	"EdgeStartTimestamp", "EdgeEndTimestamp", "EdgeResponseStatus",
+	"ClientRequestMethod", "ClientRequestHost", "ClientRequestURI",
+}
+
+// CloudflareProcessor turns the http requests of Cloudflare Logpush into an
+// access log line, like "GET example.com/api 200 12ms", with the level
+// derived from the status.
+type CloudflareProcessor struct {
+}
+
+func (p *CloudflareProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "EdgeStartTimestamp").Exists() && gjson.GetBytes(line.JSON, "EdgeResponseStatus").Exists()
+}
+
+func (p *CloudflareProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	start, ok := cloudflareTime(gjson.GetBytes(line.JSON, "EdgeStartTimestamp"))
+	if ok {
+		entry.Timestamp = &start
+	}
+	status := int(gjson.GetBytes(line.JSON, "EdgeResponseStatus").Int())
+	entry.Severity = structure.StatusSeverity(status)
+	entry.Message = fmt.Sprintf("%s %s%s %d",
+		gjson.GetBytes(line.JSON, "ClientRequestMethod").String(),
+		gjson.GetBytes(line.JSON, "ClientRequestHost").String(),
+		gjson.GetBytes(line.JSON, "ClientRequestURI").String(),
+		status)
+	if end, ok := cloudflareTime(gjson.GetBytes(line.JSON, "EdgeEndTimestamp")); ok && start.Before(end) {
+		entry.Message += " " + end.Sub(start).String()
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, cloudflareExcludes...)
+	return nil
+}
+
+// cloudflareTime parses a timestamp in any of the formats of Logpush: unix
+// nanoseconds (the default), unix seconds or RFC 3339.
+func cloudflareTime(value gjson.Result) (time.Time, bool) {
+	switch value.Type {
+	case gjson.Number:
+		n := value.Int()
+		if n > 1e12 {
+			return time.Unix(0, n).UTC(), true
+		}
+		return time.Unix(n, 0).UTC(), true
+	case gjson.String:
+		if n, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+			return time.Unix(0, n).UTC(), true
+		}
+		t, err := time.Parse(time.RFC3339Nano, value.String())
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
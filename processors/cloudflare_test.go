@@ -0,0 +1,48 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestCloudflare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		in        string
+		message   string
+		severity  string
+		timestamp string
+	}{
+		{
+			"nanoseconds",
+			`{"EdgeStartTimestamp":1686919896123000000,"EdgeEndTimestamp":1686919896135000000,"EdgeResponseStatus":502,"ClientRequestMethod":"GET","ClientRequestHost":"example.com","ClientRequestURI":"/api/users","RayID":"7d8f"}`,
+			"GET example.com/api/users 502 12ms",
+			"ERROR",
+			"2023-06-16 12:51:36.123 +0000 UTC",
+		},
+		{
+			"rfc3339",
+			`{"EdgeStartTimestamp":"2023-06-16T12:51:36Z","EdgeResponseStatus":404,"ClientRequestMethod":"POST","ClientRequestHost":"example.com","ClientRequestURI":"/login"}`,
+			"POST example.com/login 404",
+			"WARNING",
+			"2023-06-16 12:51:36 +0000 UTC",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := run(t, &CloudflareProcessor{}, test.in)
+			if got, want := entry.Message, test.message; got != want {
+				t.Errorf("entry.Message = %v, want %v", got, want)
+			}
+			if got, want := entry.Severity, test.severity; got != want {
+				t.Errorf("entry.Severity = %v, want %v", got, want)
+			}
+			if got, want := entry.Timestamp.String(), test.timestamp; got != want {
+				t.Errorf("entry.Timestamp = %v, want %v", got, want)
+			}
+		})
+	}
+}
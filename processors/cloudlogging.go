@@ -0,0 +1,104 @@
+package processors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// cloudLoggingExcludes are the keys of a LogEntry that are shown as the
+// message, logger or trace, or only useful when inspecting the raw entry.
+var cloudLoggingExcludes = []string{
+	"insertId", "logName", "receiveTimestamp", "textPayload", "resource", "labels",
+	"sourceLocation", "trace", "spanId", "traceSampled",
+	"logging.googleapis.com/trace", "logging.googleapis.com/spanId", "logging.googleapis.com/trace_sampled",
+}
+
+// CloudLoggingProcessor handles the LogEntry of Google Cloud Logging, as
+// written by jl gcloud or gcloud logging read, and the structured logs that
+// applications on GCP write to stdout. The text or the message of the json
+// payload is the message, the container (or service) is the logger and the
+// trace is shown in front of the message to group the entries of a request.
+// An httpRequest is shown as a short access log, like "GET /cart 200 12ms".
+type CloudLoggingProcessor struct {
+}
+
+func (p *CloudLoggingProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return (gjson.GetBytes(line.JSON, "logName").Exists() && gjson.GetBytes(line.JSON, "insertId").Exists()) ||
+		gjson.GetBytes(line.JSON, "httpRequest.requestMethod").Exists() ||
+		gjson.GetBytes(line.JSON, `logging\.googleapis\.com/trace`).Exists()
+}
+
+func (p *CloudLoggingProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if entry.Severity == "DEFAULT" {
+		entry.Severity = ""
+	}
+	payload := gjson.GetBytes(line.JSON, "jsonPayload")
+	switch {
+	case gjson.GetBytes(line.JSON, "textPayload").Exists():
+		entry.Message = gjson.GetBytes(line.JSON, "textPayload").String()
+	case payload.Get("message").Exists():
+		entry.Message = payload.Get("message").String()
+	case payload.Get("msg").Exists():
+		entry.Message = payload.Get("msg").String()
+	}
+	labels := gjson.GetBytes(line.JSON, "resource.labels")
+	for _, name := range []string{"container_name", "service_name", "function_name"} {
+		if logger := labels.Get(name).String(); logger != "" {
+			entry.Logger = logger
+			break
+		}
+	}
+	trace := gjson.GetBytes(line.JSON, "trace").String()
+	if trace == "" {
+		trace = gjson.GetBytes(line.JSON, `logging\.googleapis\.com/trace`).String()
+	}
+	if trace != "" && entry.Thread == "" {
+		entry.Thread = trace[strings.LastIndex(trace, "/")+1:]
+	}
+	if request := gjson.GetBytes(line.JSON, "httpRequest"); request.IsObject() {
+		if fragment := httpRequestFragment(request); entry.Message == "" {
+			entry.Message = fragment
+		} else {
+			entry.Message += " (" + fragment + ")"
+		}
+		if status := int(request.Get("status").Int()); status > 0 && entry.Severity == "" {
+			entry.Severity = structure.StatusSeverity(status)
+		}
+		request.ForEach(func(key, _ gjson.Result) bool {
+			if k := key.String(); k != "remoteIp" && k != "userAgent" {
+				entry.ExcludeFields = append(entry.ExcludeFields, "httpRequest."+k)
+			}
+			return true
+		})
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, cloudLoggingExcludes...)
+	entry.FlattenFields = append(entry.FlattenFields, "jsonPayload")
+	return nil
+}
+
+// httpRequestFragment returns the method, path, status and latency of the
+// httpRequest of a LogEntry.
+func httpRequestFragment(request gjson.Result) string {
+	path := request.Get("requestUrl").String()
+	if u, err := url.Parse(path); err == nil && u.Host != "" {
+		path = u.RequestURI()
+	}
+	var b strings.Builder
+	if path == "" {
+		path = "-"
+	}
+	fmt.Fprintf(&b, "%s %s", envoyValue(request.Get("requestMethod")), path)
+	if status := request.Get("status"); status.Exists() {
+		fmt.Fprintf(&b, " %d", status.Int())
+	}
+	if latency, err := time.ParseDuration(request.Get("latency").String()); err == nil {
+		fmt.Fprintf(&b, " %dms", latency.Milliseconds())
+	}
+	return b.String()
+}
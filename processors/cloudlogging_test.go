@@ -0,0 +1,52 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestCloudLogging(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &CloudLoggingProcessor{}, `{"insertId":"x1","logName":"projects/shop/logs/stdout","severity":"WARNING","timestamp":"2023-06-16T12:51:36Z","resource":{"type":"k8s_container","labels":{"container_name":"api","namespace_name":"default"}},"jsonPayload":{"message":"slow query","duration":1.2}}`)
+	if got, want := entry.Severity, "WARNING"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Message, "slow query"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Logger, "api"; got != want {
+		t.Errorf("entry.Logger = %v, want %v", got, want)
+	}
+	if got, want := has(entry.FlattenFields, "jsonPayload"), true; got != want {
+		t.Errorf("entry.FlattenFields['jsonPayload'] = %v, want %v", got, want)
+	}
+
+	entry = run(t, &CloudLoggingProcessor{}, `{"insertId":"x2","logName":"projects/shop/logs/stderr","severity":"DEFAULT","textPayload":"panic: oops"}`)
+	if got, want := entry.Severity, ""; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Message, "panic: oops"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+}
+
+func TestCloudLogging_HTTPRequest(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &CloudLoggingProcessor{}, `{"message":"checkout done","logging.googleapis.com/trace":"projects/shop/traces/4bf92f35","httpRequest":{"requestMethod":"POST","requestUrl":"https://shop.example/cart?id=1","status":502,"latency":"0.012s","remoteIp":"10.0.0.1"}}`)
+	if got, want := entry.Message, "checkout done (POST /cart?id=1 502 12ms)"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "ERROR"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Thread, "4bf92f35"; got != want {
+		t.Errorf("entry.Thread = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "httpRequest.latency"), true; got != want {
+		t.Errorf("entry.ExcludeFields['httpRequest.latency'] = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "httpRequest.remoteIp"), false; got != want {
+		t.Errorf("entry.ExcludeFields['httpRequest.remoteIp'] = %v, want %v", got, want)
+	}
+}
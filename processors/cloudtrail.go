@@ -0,0 +1,62 @@
+package processors
+
+import (
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// cloudTrailExcludes are the keys of a CloudTrail event that are part of
+// the message or only useful when inspecting the raw event.
+var cloudTrailExcludes = []string{
+	"eventVersion", "eventTime", "eventSource", "eventName", "eventID", "eventType",
+	"eventCategory", "requestID", "readOnly", "managementEvent", "recipientAccountId",
+	"sessionCredentialFromConsole", "tlsDetails", "userAgent", "responseElements",
+}
+
+// CloudTrailProcessor turns AWS CloudTrail events into a one-liner like
+// "s3.GetObject by alice", failed calls are shown as errors with their error
+// code.
+type CloudTrailProcessor struct {
+}
+
+func (p *CloudTrailProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "eventVersion").Exists() && gjson.GetBytes(line.JSON, "eventSource").Exists() && gjson.GetBytes(line.JSON, "eventName").Exists()
+}
+
+func (p *CloudTrailProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	source := gjson.GetBytes(line.JSON, "eventSource").String()
+	service, _, _ := strings.Cut(source, ".")
+	entry.Message = service + "." + gjson.GetBytes(line.JSON, "eventName").String()
+	if identity := cloudTrailIdentity(gjson.GetBytes(line.JSON, "userIdentity")); identity != "" {
+		entry.Message += " by " + identity
+	}
+
+	if t, err := time.Parse(time.RFC3339, gjson.GetBytes(line.JSON, "eventTime").String()); err == nil {
+		entry.Timestamp = &t
+	}
+	entry.Severity = "INFO"
+	if gjson.GetBytes(line.JSON, "errorCode").Exists() {
+		entry.Severity = "ERROR"
+		entry.IncludeFields = append(entry.IncludeFields, "errorCode", "errorMessage")
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, cloudTrailExcludes...)
+	return nil
+}
+
+// cloudTrailIdentity returns a short name of the caller of an event.
+func cloudTrailIdentity(identity gjson.Result) string {
+	if name := identity.Get("userName").String(); name != "" {
+		return name
+	}
+	if arn := identity.Get("arn").String(); arn != "" {
+		return arn[strings.LastIndex(arn, "/")+1:]
+	}
+	if service := identity.Get("invokedBy").String(); service != "" {
+		return service
+	}
+	return identity.Get("type").String()
+}
@@ -0,0 +1,71 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/koenbollen/jl/djson"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+func TestCloudTrail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		in       string
+		message  string
+		severity string
+	}{
+		{
+			"user",
+			`{"eventVersion":"1.08","userIdentity":{"type":"IAMUser","arn":"arn:aws:iam::123456789012:user/alice","userName":"alice"},"eventTime":"2023-06-16T12:51:36Z","eventSource":"s3.amazonaws.com","eventName":"GetObject","errorCode":"AccessDenied"}`,
+			"s3.GetObject by alice",
+			"ERROR",
+		},
+		{
+			"assumed role",
+			`{"eventVersion":"1.08","userIdentity":{"type":"AssumedRole","arn":"arn:aws:sts::123456789012:assumed-role/deploy/ci-session"},"eventTime":"2023-06-16T12:51:36Z","eventSource":"ec2.amazonaws.com","eventName":"RunInstances"}`,
+			"ec2.RunInstances by ci-session",
+			"INFO",
+		},
+		{
+			"service",
+			`{"eventVersion":"1.08","userIdentity":{"type":"AWSService","invokedBy":"lambda.amazonaws.com"},"eventTime":"2023-06-16T12:51:36Z","eventSource":"sts.amazonaws.com","eventName":"AssumeRole"}`,
+			"sts.AssumeRole by lambda.amazonaws.com",
+			"INFO",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := run(t, &CloudTrailProcessor{}, test.in)
+			if got, want := entry.Message, test.message; got != want {
+				t.Errorf("entry.Message = %v, want %v", got, want)
+			}
+			if got, want := entry.Severity, test.severity; got != want {
+				t.Errorf("entry.Severity = %v, want %v", got, want)
+			}
+			if got, want := entry.Timestamp.String(), "2023-06-16 12:51:36 +0000 UTC"; got != want {
+				t.Errorf("entry.Timestamp = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// run detects and processes the json line with the given processor.
+func run(t *testing.T, p Processor, input string) *structure.Entry {
+	t.Helper()
+
+	line := &stream.Line{Raw: []byte(input), JSON: []byte(input)}
+	entry := &structure.Entry{}
+	djson.Unmarshal(line.JSON, entry)
+	if got, want := p.Detect(line, entry), true; got != want {
+		t.Fatalf("Detect() = %v, want %v", got, want)
+	}
+	if err := p.Process(line, entry); err != nil {
+		t.Fatalf("Process() = %v, want nil", err)
+	}
+	return entry
+}
@@ -0,0 +1,36 @@
+package processors
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var cockroachExcludes = []string{
+	"channel", "channel_numeric", "severity_numeric", "entry_counter", "redactable",
+}
+
+// CockroachProcessor handles the json logs of CockroachDB, the channel of an
+// entry is used as logger and the timestamp is a string of decimal seconds.
+type CockroachProcessor struct {
+}
+
+func (p *CockroachProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "channel_numeric").Exists() && gjson.GetBytes(line.JSON, "severity_numeric").Exists()
+}
+
+func (p *CockroachProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if seconds, err := strconv.ParseFloat(gjson.GetBytes(line.JSON, "timestamp").String(), 64); err == nil {
+		sec, dec := math.Modf(seconds)
+		t := time.Unix(int64(sec), int64(dec*1e9)).UTC()
+		entry.Timestamp = &t
+	}
+	entry.Logger = gjson.GetBytes(line.JSON, "channel").String()
+	entry.FlattenFields = append(entry.FlattenFields, "tags")
+	entry.ExcludeFields = append(entry.ExcludeFields, cockroachExcludes...)
+	return nil
+}
@@ -0,0 +1,20 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestCockroach(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &CockroachProcessor{}, `{"channel_numeric":1,"channel":"OPS","timestamp":"1686919896.123456789","severity_numeric":1,"severity":"INFO","tags":{"n":"1"},"message":"node connected"}`)
+	if got, want := entry.Logger, "OPS"; got != want {
+		t.Errorf("entry.Logger = %v, want %v", got, want)
+	}
+	if got, want := entry.Timestamp.Format("2006-01-02 15:04:05.000"), "2023-06-16 12:51:36.123"; got != want {
+		t.Errorf("entry.Timestamp = %v, want %v", got, want)
+	}
+	if got, want := has(entry.FlattenFields, "tags"), true; got != want {
+		t.Errorf("entry.FlattenFields['tags'] = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// AddFieldProcessor adds fields computed from the other fields of an entry,
+// either with arithmetic, like duration_ms / 1000, or with a go template,
+// like {{.method}} {{.path}}. Fields that can't be computed for an entry,
+// because a field is missing or isn't a number, are left out.
+type AddFieldProcessor struct {
+	fields []computedField
+}
+
+type computedField struct {
+	name     string
+	expr     node
+	template *template.Template
+}
+
+// NewAddFieldProcessor parses the fields in the form name = expression.
+func NewAddFieldProcessor(specs []string) (*AddFieldProcessor, error) {
+	p := &AddFieldProcessor{}
+	for _, spec := range specs {
+		name, text, ok := strings.Cut(spec, "=")
+		name, text = strings.TrimSpace(name), strings.TrimSpace(text)
+		if !ok || name == "" || text == "" {
+			return nil, fmt.Errorf("%q is not a name = expression pair", spec)
+		}
+		field := computedField{name: name}
+		var err error
+		if strings.Contains(text, "{{") {
+			field.template, err = template.New(name).Option("missingkey=error").Parse(text)
+		} else {
+			field.expr, err = parseArithmetic(text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", spec, err)
+		}
+		p.fields = append(p.fields, field)
+	}
+	return p, nil
+}
+
+func (p *AddFieldProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return len(p.fields) > 0
+}
+
+func (p *AddFieldProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(line.JSON, &fields); err != nil {
+		return nil
+	}
+	changed := false
+	for _, field := range p.fields {
+		if field.template != nil {
+			var buf bytes.Buffer
+			if err := field.template.Execute(&buf, fields); err == nil {
+				fields[field.name], changed = buf.String(), true
+			}
+			continue
+		}
+		if value, ok := field.expr.eval(line.JSON); ok {
+			fields[field.name], changed = value, true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	line.JSON = data
+	return nil
+}
+
+// node is a part of an arithmetic expression, evaluated against the json of
+// an entry.
+type node interface {
+	eval(raw []byte) (float64, bool)
+}
+
+type number float64
+
+func (n number) eval(raw []byte) (float64, bool) {
+	return float64(n), true
+}
+
+type field string
+
+func (f field) eval(raw []byte) (float64, bool) {
+	value := gjson.GetBytes(raw, string(f))
+	switch value.Type {
+	case gjson.Number:
+		return value.Float(), true
+	case gjson.String:
+		n, err := strconv.ParseFloat(value.String(), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(raw []byte) (float64, bool) {
+	v, ok := n.operand.eval(raw)
+	return -v, ok
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(raw []byte) (float64, bool) {
+	left, ok := b.left.eval(raw)
+	if !ok {
+		return 0, false
+	}
+	right, ok := b.right.eval(raw)
+	if !ok {
+		return 0, false
+	}
+	switch b.op {
+	case '+':
+		return left + right, true
+	case '-':
+		return left - right, true
+	case '*':
+		return left * right, true
+	case '/':
+		return left / right, right != 0
+	case '%':
+		return math.Mod(left, right), right != 0
+	}
+	return 0, false
+}
+
+// arithmetic is a recursive descent parser of expressions with numbers,
+// fields, parentheses and the + - * / % operators.
+type arithmetic struct {
+	text string
+	pos  int
+}
+
+func parseArithmetic(text string) (node, error) {
+	p := &arithmetic{text: text}
+	n, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.text) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.text[p.pos:], p.pos)
+	}
+	return n, nil
+}
+
+func (p *arithmetic) skipSpace() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// operator consumes the next character when it is one of the operators.
+func (p *arithmetic) operator(operators string) (byte, bool) {
+	p.skipSpace()
+	if p.pos < len(p.text) && strings.IndexByte(operators, p.text[p.pos]) != -1 {
+		p.pos++
+		return p.text[p.pos-1], true
+	}
+	return 0, false
+}
+
+func (p *arithmetic) sum() (node, error) {
+	left, err := p.product()
+	for err == nil {
+		op, ok := p.operator("+-")
+		if !ok {
+			break
+		}
+		var right node
+		right, err = p.product()
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *arithmetic) product() (node, error) {
+	left, err := p.unary()
+	for err == nil {
+		op, ok := p.operator("*/%")
+		if !ok {
+			break
+		}
+		var right node
+		right, err = p.unary()
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *arithmetic) unary() (node, error) {
+	if _, ok := p.operator("-"); ok {
+		operand, err := p.unary()
+		return negate{operand: operand}, err
+	}
+	if _, ok := p.operator("("); ok {
+		n, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.operator(")"); !ok {
+			return nil, fmt.Errorf("missing ) at position %d", p.pos)
+		}
+		return n, nil
+	}
+	start := p.pos
+	for p.pos < len(p.text) && isOperand(p.text[p.pos]) {
+		p.pos++
+	}
+	token := p.text[start:p.pos]
+	if token == "" {
+		return nil, fmt.Errorf("expected a number or field at position %d", start)
+	}
+	if n, err := strconv.ParseFloat(token, 64); err == nil {
+		return number(n), nil
+	}
+	return field(token), nil
+}
+
+// isOperand reports whether the character is part of a number or a field
+// name, like http.duration_ms.
+func isOperand(c byte) bool {
+	return c == '_' || c == '.' || c == '@' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
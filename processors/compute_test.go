@@ -0,0 +1,58 @@
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+func TestAddField(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec   string
+		expect string
+	}{
+		{"latency_s = duration_ms / 1000", `1.5`},
+		{"total = (a + b) * 2 - -1", `11`},
+		{"rest = b % a", `1`},
+		{"nested = http.bytes / 1024", `2`},
+		{"numeric = text * 2", `84`},
+		{"route = {{.method}} {{.path}}", `"GET /users"`},
+		{"missing = nope + 1", ``},
+		{"ratio = a / zero", ``},
+		{"template = {{.nope}}", ``},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.spec, func(t *testing.T) {
+			t.Parallel()
+			p, err := NewAddFieldProcessor([]string{test.spec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := `{"duration_ms":1500,"a":2,"b":3,"zero":0,"text":"42","http":{"bytes":2048},"method":"GET","path":"/users"}`
+			line := &stream.Line{Raw: []byte(in), JSON: []byte(in)}
+			if err := p.Process(line, &structure.Entry{}); err != nil {
+				t.Fatal(err)
+			}
+			name, _, _ := strings.Cut(test.spec, " ")
+			if got, want := gjson.GetBytes(line.JSON, name).Raw, test.expect; got != want {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, want)
+			}
+		})
+	}
+}
+
+func TestNewAddFieldProcessorErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{"nameless", "x = ", "x = (a + 1", "x = a +", "x = a $ b", "x = {{.a"} {
+		if _, err := NewAddFieldProcessor([]string{spec}); err == nil {
+			t.Errorf("NewAddFieldProcessor(%q) = nil, want error", spec)
+		}
+	}
+}
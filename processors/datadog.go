@@ -0,0 +1,48 @@
+package processors
+
+import (
+	"strings"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var datadogExcludes = []string{"_id", "id", "date", "status", "service", "tags"}
+
+// datadogStatuses maps the statuses of Datadog that aren't a well known
+// severity.
+var datadogStatuses = map[string]string{
+	"emerg": "EMERGENCY",
+	"crit":  "CRITICAL",
+	"err":   "ERROR",
+	"ok":    "INFO",
+}
+
+// DatadogProcessor handles the NDJSON of Datadog log archives, the status is
+// the level, the service is the logger and the attributes are shown as
+// regular fields.
+type DatadogProcessor struct {
+}
+
+func (p *DatadogProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "attributes").IsObject() &&
+		gjson.GetBytes(line.JSON, "status").Type == gjson.String &&
+		gjson.GetBytes(line.JSON, "service").Exists()
+}
+
+func (p *DatadogProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	status := strings.ToLower(gjson.GetBytes(line.JSON, "status").String())
+	if severity, ok := datadogStatuses[status]; ok {
+		entry.Severity = severity
+	} else if entry.Severity == "" {
+		entry.Severity = status
+	}
+	if entry.Message == "" {
+		entry.Message = gjson.GetBytes(line.JSON, "attributes.message").String()
+	}
+	entry.Logger = gjson.GetBytes(line.JSON, "service").String()
+	entry.ExcludeFields = append(entry.ExcludeFields, datadogExcludes...)
+	entry.FlattenFields = append(entry.FlattenFields, "attributes")
+	return nil
+}
@@ -0,0 +1,23 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestDatadog(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &DatadogProcessor{}, `{"_id":"AQAAAYjE","date":"2023-06-16T12:51:36.123Z","host":"web-1","service":"checkout","status":"emerg","message":"payment provider down","attributes":{"attempt":2},"tags":["env:prod"]}`)
+	if got, want := entry.Severity, "EMERGENCY"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Logger, "checkout"; got != want {
+		t.Errorf("entry.Logger = %v, want %v", got, want)
+	}
+	if got, want := has(entry.FlattenFields, "attributes"), true; got != want {
+		t.Errorf("entry.FlattenFields['attributes'] = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "status"), true; got != want {
+		t.Errorf("entry.ExcludeFields['status'] = %v, want %v", got, want)
+	}
+}
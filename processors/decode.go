@@ -0,0 +1,181 @@
+package processors
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// decoders are the encodings that the DecodeProcessor can decode.
+var decoders = map[string]func(string) ([]byte, error){
+	"base64": decodeBase64,
+	"hex":    hex.DecodeString,
+}
+
+// DecodeProcessor decodes the base64 or hex encoded payloads of fields, so
+// json or text in these fields is shown instead of an opaque blob. Gzipped
+// payloads are decompressed.
+type DecodeProcessor struct {
+	fields []decodeField
+}
+
+type decodeField struct {
+	path   string
+	decode func(string) ([]byte, error)
+	// json only replaces the value when it decodes to json
+	json bool
+}
+
+// NewDecodeProcessor returns a DecodeProcessor for the given fields, in the
+// form field:encoding, like payload:base64 or data:hex. A json suffix, like
+// payload:base64json, only decodes payloads that contain json.
+func NewDecodeProcessor(specs []string) (*DecodeProcessor, error) {
+	p := &DecodeProcessor{}
+	for _, spec := range specs {
+		path, encoding, ok := strings.Cut(spec, ":")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("%q is not a field:encoding pair", spec)
+		}
+		field := decodeField{path: path}
+		encoding, field.json = strings.CutSuffix(encoding, "json")
+		encoding = strings.TrimSuffix(strings.TrimSuffix(encoding, "gzip"), "+")
+		if field.decode, ok = decoders[encoding]; !ok {
+			return nil, fmt.Errorf("unknown encoding %q, expected base64, base64gzip or hex", encoding)
+		}
+		p.fields = append(p.fields, field)
+	}
+	return p, nil
+}
+
+func (p *DecodeProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	for _, field := range p.fields {
+		if gjson.GetBytes(line.JSON, field.path).Type == gjson.String {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *DecodeProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line.JSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil
+	}
+	changed := false
+	for _, field := range p.fields {
+		value := gjson.GetBytes(line.JSON, field.path)
+		if value.Type != gjson.String {
+			continue
+		}
+		decoded, ok := field.value(value.String())
+		if !ok || !setPath(fields, field.path, decoded) {
+			continue
+		}
+		top, _, _ := strings.Cut(field.path, ".")
+		entry.IncludeFields = append(entry.IncludeFields, top)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	line.JSON = data
+	return nil
+}
+
+// value decodes the payload and returns it as json value or text, ok is
+// false when the payload can't be decoded or is binary.
+func (f decodeField) value(payload string) (interface{}, bool) {
+	data, err := f.decode(strings.TrimSpace(payload))
+	if err != nil {
+		return nil, false
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		if data, err = gunzip(data); err != nil {
+			return nil, false
+		}
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		if _, ok := value.(string); !ok {
+			return value, true
+		}
+	}
+	if f.json || !utf8.Valid(data) || bytes.ContainsFunc(data, isBinary) {
+		return nil, false
+	}
+	return string(data), true
+}
+
+// isBinary reports whether the rune is a control character that isn't used
+// in text.
+func isBinary(r rune) bool {
+	return r < 0x20 && r != '\n' && r != '\r' && r != '\t'
+}
+
+// decodeBase64 decodes standard and url safe base64, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	var err error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		var data []byte
+		if data, err = encoding.DecodeString(s); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// setPath replaces the value at the dotted path of the fields, it returns
+// false when the path doesn't exist.
+func setPath(fields map[string]interface{}, path string, value interface{}) bool {
+	key, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		if _, ok := fields[key]; !ok {
+			return false
+		}
+		fields[key] = value
+		return true
+	}
+	child, ok := fields[key].(map[string]interface{})
+	return ok && setPath(child, rest, value)
+}
+
+// AddDecoders adds a DecodeProcessor for the given fields in front of the
+// other processors, so these can use the decoded payloads.
+func AddDecoders(specs []string) error {
+	if len(specs) == 0 {
+		return nil
+	}
+	p, err := NewDecodeProcessor(specs)
+	if err != nil {
+		return err
+	}
+	All = append([]Processor{p}, All...)
+	return nil
+}
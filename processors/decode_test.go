@@ -0,0 +1,57 @@
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+func TestDecodeProcessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		spec   string
+		in     string
+		expect string
+	}{
+		{"base64 json", "payload:base64", `{"payload":"eyJ1c2VyIjoiYWxpY2UifQ=="}`, `{"user":"alice"}`},
+		{"base64 text", "payload:base64", `{"payload":"aGVsbG8gd29ybGQ="}`, `"hello world"`},
+		{"base64 gzip", "blob:base64gzip", `{"blob":"H4sIAAAAAAAAA8tIzcnJV0ivyiwAABlq0t8KAAAA"}`, `"hello gzip"`},
+		{"hex", "data:hex", `{"data":"68656c6c6f"}`, `"hello"`},
+		{"nested", "event.data:hex", `{"event":{"data":"7b2261223a317d"}}`, `{"a":1}`},
+		{"binary", "data:hex", `{"data":"00ff10"}`, `"00ff10"`},
+		{"json only", "payload:base64json", `{"payload":"aGVsbG8gd29ybGQ="}`, `"aGVsbG8gd29ybGQ="`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := NewDecodeProcessor([]string{test.spec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			line := &stream.Line{Raw: []byte(test.in), JSON: []byte(test.in)}
+			if err := p.Process(line, &structure.Entry{}); err != nil {
+				t.Fatal(err)
+			}
+			path, _, _ := strings.Cut(test.spec, ":")
+			if got, want := gjson.GetBytes(line.JSON, path).Raw, test.expect; got != want {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, want)
+			}
+		})
+	}
+}
+
+func TestNewDecodeProcessorErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{"payload", "payload:rot13", ":hex"} {
+		if _, err := NewDecodeProcessor([]string{spec}); err == nil {
+			t.Errorf("NewDecodeProcessor(%q) = nil, want error", spec)
+		}
+	}
+}
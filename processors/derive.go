@@ -0,0 +1,57 @@
+package processors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/koenbollen/jl/filter"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// DeriveLevelProcessor overrides the level of entries that match a rule, for
+// services that log with the wrong level. The first matching rule wins.
+type DeriveLevelProcessor struct {
+	rules []levelRule
+}
+
+type levelRule struct {
+	expr  *filter.Expr
+	level string
+}
+
+// NewDeriveLevelProcessor parses rules in the form expression:level, like
+// status>=500:error or msg=~"timeout":warn.
+func NewDeriveLevelProcessor(rules []string) (*DeriveLevelProcessor, error) {
+	p := &DeriveLevelProcessor{}
+	for _, rule := range rules {
+		i := strings.LastIndex(rule, ":")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid rule %q, expected expression:level", rule)
+		}
+		level := structure.NormalizeSeverity(rule[i+1:])
+		if structure.SeverityRank(level) < 0 {
+			return nil, fmt.Errorf("unknown level %q in rule %q", rule[i+1:], rule)
+		}
+		expr, err := filter.Parse(rule[:i])
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, levelRule{expr: expr, level: level})
+	}
+	return p, nil
+}
+
+func (p *DeriveLevelProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return len(p.rules) > 0
+}
+
+func (p *DeriveLevelProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	for _, rule := range p.rules {
+		if rule.expr.Match(line.JSON, entry) {
+			entry.Severity = rule.level
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestDeriveLevel(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewDeriveLevelProcessor([]string{"status>=500:error", `msg=~"time(out)?":warn`, "user=bot:debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		in       string
+		severity string
+	}{
+		{"status", `{"level":"info","msg":"upstream timeout","status":503}`, "ERROR"},
+		{"regexp", `{"level":"error","msg":"upstream timeout"}`, "WARNING"},
+		{"no match", `{"level":"info","msg":"ok","status":200}`, "info"},
+		{"without level", `{"msg":"crawl","user":"bot"}`, "DEBUG"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := run(t, p, test.in)
+			if got, want := entry.Severity, test.severity; got != want {
+				t.Errorf("entry.Severity = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNewDeriveLevelProcessorErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []string{"status>=500", "status>=500:loud", "msg=~(:error"} {
+		if _, err := NewDeriveLevelProcessor([]string{rule}); err == nil {
+			t.Errorf("NewDeriveLevelProcessor(%q) = nil, want error", rule)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package processors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var envoyExcludes = []string{
+	"start_time", "method", "path", "response_code", "duration",
+	"upstream_cluster", "grpc_status",
+}
+
+// EnvoyProcessor turns the default json access log of Envoy (and Istio's
+// sidecars) into an access log line, like "POST /pkg.Service/Method 200(OK)
+// 14ms → cluster", with the level derived from the http and gRPC status.
+type EnvoyProcessor struct {
+}
+
+func (p *EnvoyProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "response_code").Exists() &&
+		gjson.GetBytes(line.JSON, "duration").Exists() &&
+		(gjson.GetBytes(line.JSON, "upstream_cluster").Exists() || gjson.GetBytes(line.JSON, "x_forwarded_for").Exists())
+}
+
+func (p *EnvoyProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if start, err := time.Parse(time.RFC3339Nano, gjson.GetBytes(line.JSON, "start_time").String()); err == nil {
+		entry.Timestamp = &start
+	}
+	status := int(gjson.GetBytes(line.JSON, "response_code").Int())
+	entry.Severity = structure.StatusSeverity(status)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %d",
+		envoyValue(gjson.GetBytes(line.JSON, "method")),
+		envoyValue(gjson.GetBytes(line.JSON, "path")),
+		status)
+	if code := grpcStatus(gjson.GetBytes(line.JSON, "grpc_status")); code != "" {
+		fmt.Fprintf(&b, "(%s)", code)
+		if severity := structure.GRPCSeverity(code); structure.SeverityRank(severity) > structure.SeverityRank(entry.Severity) {
+			entry.Severity = severity
+		}
+	}
+	fmt.Fprintf(&b, " %dms", gjson.GetBytes(line.JSON, "duration").Int())
+	if cluster := envoyValue(gjson.GetBytes(line.JSON, "upstream_cluster")); cluster != "-" {
+		fmt.Fprintf(&b, " → %s", cluster)
+	}
+	entry.Message = b.String()
+	entry.ExcludeFields = append(entry.ExcludeFields, envoyExcludes...)
+	// Envoy logs every key of its format, hide the ones without a value:
+	gjson.ParseBytes(line.JSON).ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.Null || value.String() == "-" {
+			entry.ExcludeFields = append(entry.ExcludeFields, key.String())
+		}
+		return true
+	})
+	return nil
+}
+
+// envoyValue returns the string of the value, with "-" for missing ones like
+// Envoy itself does.
+func envoyValue(value gjson.Result) string {
+	if s := value.String(); s != "" {
+		return s
+	}
+	return "-"
+}
+
+// grpcStatus returns the name of the gRPC status, which Envoy logs either as
+// number or as name.
+func grpcStatus(value gjson.Result) string {
+	switch value.Type {
+	case gjson.Number:
+		if name := structure.GRPCCodeName(int(value.Int())); name != "" {
+			return name
+		}
+		return value.Raw
+	case gjson.String:
+		if s := value.String(); s != "-" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestEnvoy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		in       string
+		message  string
+		severity string
+	}{
+		{
+			"grpc",
+			`{"start_time":"2023-06-16T12:51:36.123Z","method":"POST","path":"/pkg.Service/Method","response_code":200,"grpc_status":"OK","duration":14,"upstream_cluster":"outbound|9000||svc","x_forwarded_for":null}`,
+			"POST /pkg.Service/Method 200(OK) 14ms → outbound|9000||svc",
+			"INFO",
+		},
+		{
+			"grpc numeric",
+			`{"method":"POST","path":"/pkg.Service/Method","response_code":200,"grpc_status":14,"duration":3,"upstream_cluster":"svc"}`,
+			"POST /pkg.Service/Method 200(Unavailable) 3ms → svc",
+			"ERROR",
+		},
+		{
+			"grpc client error",
+			`{"method":"POST","path":"/pkg.Service/Method","response_code":200,"grpc_status":"NotFound","duration":3,"upstream_cluster":"svc"}`,
+			"POST /pkg.Service/Method 200(NotFound) 3ms → svc",
+			"WARNING",
+		},
+		{
+			"http without upstream",
+			`{"method":"GET","path":"/healthz","response_code":503,"duration":"0","upstream_cluster":null,"x_forwarded_for":"10.0.0.1"}`,
+			"GET /healthz 503 0ms",
+			"ERROR",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := run(t, &EnvoyProcessor{}, test.in)
+			if got, want := entry.Message, test.message; got != want {
+				t.Errorf("entry.Message = %v, want %v", got, want)
+			}
+			if got, want := entry.Severity, test.severity; got != want {
+				t.Errorf("entry.Severity = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package processors
+
+import (
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var hashicorpExcludes = []string{"@level", "@message", "@module"}
+
+// HashiCorpProcessor handles the json logs of Vault, Consul, Nomad and other
+// tools using hclog, which prefixes its own keys with an @.
+type HashiCorpProcessor struct {
+}
+
+func (p *HashiCorpProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "@message").Exists() && gjson.GetBytes(line.JSON, "@level").Exists()
+}
+
+func (p *HashiCorpProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if entry.Message == "" {
+		entry.Message = gjson.GetBytes(line.JSON, "@message").String()
+	}
+	if entry.Severity == "" {
+		entry.Severity = gjson.GetBytes(line.JSON, "@level").String()
+	}
+	entry.Logger = gjson.GetBytes(line.JSON, "@module").String()
+	entry.ExcludeFields = append(entry.ExcludeFields, hashicorpExcludes...)
+	return nil
+}
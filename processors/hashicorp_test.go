@@ -0,0 +1,23 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestHashiCorp(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &HashiCorpProcessor{}, `{"@level":"warn","@message":"no tls config found","@module":"agent.server","@timestamp":"2023-06-16T12:51:37.000000Z"}`)
+	if got, want := entry.Message, "no tls config found"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "warn"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Logger, "agent.server"; got != want {
+		t.Errorf("entry.Logger = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "@module"), true; got != want {
+		t.Errorf("entry.ExcludeFields['@module'] = %v, want %v", got, want)
+	}
+}
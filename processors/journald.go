@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,72 @@ var priorityMapping = map[int64]string{
 	7: "DEBUG",
 }
 
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron",
+	"authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// JournaldFacilities shows the name of the syslog facility of journald
+// entries as their logger, instead of its number as a field.
+var JournaldFacilities = false
+
+// AddPriorityLevels overrides the levels of journald priorities with a
+// mapping like notice=info,7=trace, the priorities are given by name or
+// number.
+func AddPriorityLevels(mapping string) error {
+	for _, pair := range strings.Split(mapping, ",") {
+		priority, level, ok := strings.Cut(pair, "=")
+		priority, level = strings.ToUpper(strings.TrimSpace(priority)), strings.ToUpper(strings.TrimSpace(level))
+		if !ok || priority == "" || level == "" {
+			return fmt.Errorf("%q is not a PRIORITY=level pair", pair)
+		}
+		number, err := strconv.ParseInt(priority, 10, 64)
+		if err != nil {
+			number = priorityNumber(priority)
+		}
+		if _, ok := priorityMapping[number]; !ok {
+			return fmt.Errorf("%q is not a syslog priority", priority)
+		}
+		if structure.SeverityRank(level) == -1 {
+			return fmt.Errorf("%q is not a level", level)
+		}
+		priorityLevels[number] = level
+	}
+	return nil
+}
+
+// priorityLevels are the levels of priorities that are overridden.
+var priorityLevels = map[int64]string{}
+
+func priorityLevel(priority int64) string {
+	if level, ok := priorityLevels[priority]; ok {
+		return level
+	}
+	return priorityMapping[priority]
+}
+
+// priorityAliases are the short names of priorities, as used by journalctl.
+var priorityAliases = map[string]string{
+	"EMERG": "EMERGENCY",
+	"CRIT":  "CRITICAL",
+	"ERR":   "ERROR",
+	"WARN":  "WARNING",
+}
+
+// priorityNumber returns the number of the priority name, or -1.
+func priorityNumber(name string) int64 {
+	if alias, ok := priorityAliases[name]; ok {
+		name = alias
+	}
+	for number, priority := range priorityMapping {
+		if priority == name {
+			return number
+		}
+	}
+	return -1
+}
+
 type JournaldProcessor struct {
 }
 
@@ -46,16 +113,19 @@ func (p *JournaldProcessor) Process(line *stream.Line, entry *structure.Entry) e
 	switch prioField.Type {
 	case gjson.Number:
 		priority := prioField.Int()
-		entry.Severity = priorityMapping[priority]
+		entry.Severity = priorityLevel(priority)
 		entry.ExcludeFields = append(entry.ExcludeFields, "PRIORITY")
 	case gjson.String:
 		priority := prioField.String()
 		if i, err := strconv.ParseInt(priority, 10, 64); err == nil {
-			entry.Severity = priorityMapping[i]
+			entry.Severity = priorityLevel(i)
 			entry.ExcludeFields = append(entry.ExcludeFields, "PRIORITY")
 			break
 		} else if len(priority) < 12 {
 			entry.Severity = strings.ToUpper(priority)
+			if number := priorityNumber(entry.Severity); number != -1 {
+				entry.Severity = priorityLevel(number)
+			}
 			entry.ExcludeFields = append(entry.ExcludeFields, "PRIORITY")
 			break
 		}
@@ -64,5 +134,15 @@ func (p *JournaldProcessor) Process(line *stream.Line, entry *structure.Entry) e
 		entry.Severity = "UNKNOWN"
 	}
 
+	if facility := gjson.GetBytes(line.JSON, "SYSLOG_FACILITY"); JournaldFacilities && facility.Exists() {
+		if number := facility.Int(); number >= 0 && number < int64(len(facilityNames)) && entry.Logger == "" {
+			entry.Logger = facilityNames[number]
+			entry.ExcludeFields = append(entry.ExcludeFields, "SYSLOG_FACILITY")
+		}
+	}
+
+	entry.Boot = gjson.GetBytes(line.JSON, "_BOOT_ID").String()
+	entry.ExcludeFields = append(entry.ExcludeFields, "_BOOT_ID")
+
 	return nil
 }
@@ -82,6 +82,10 @@ func TestJournald_Happypath(t *testing.T) {
 	if got, want := has(entry.ExcludeFields, "PRIORITY"), true; got != want {
 		t.Errorf("entry.SkipFields['PRIORITY'] = %v, want %v", got, want)
 	}
+
+	if got, want := entry.Boot, "4cef257cf46b4818a75a0f463024e90d"; got != want {
+		t.Errorf("entry.Boot = %v, want %v", got, want)
+	}
 }
 
 func TestJournald_InvalidTimestamp(t *testing.T) {
@@ -160,6 +164,25 @@ func TestJournald_InvalidPriority(t *testing.T) {
 	}
 }
 
+func TestAddPriorityLevels(t *testing.T) {
+	if err := AddPriorityLevels("7=trace,alert=critical"); err != nil {
+		t.Fatal(err)
+	}
+	entry := process(t, `{"__REALTIME_TIMESTAMP": "1686919896987169", "PRIORITY": "7", "MESSAGE": "tick", "SYSLOG_IDENTIFIER": "cron"}`)
+	if got, want := entry.Severity, "TRACE"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	entry = process(t, `{"__REALTIME_TIMESTAMP": "1686919896987169", "PRIORITY": "alert", "MESSAGE": "disk", "SYSLOG_IDENTIFIER": "smartd"}`)
+	if got, want := entry.Severity, "CRITICAL"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	for _, mapping := range []string{"7", "8=info", "verbose=info", "notice=nfo"} {
+		if err := AddPriorityLevels(mapping); err == nil {
+			t.Errorf("AddPriorityLevels(%q) = nil, want error", mapping)
+		}
+	}
+}
+
 func process(t *testing.T, input string) *structure.Entry {
 	t.Helper()
 
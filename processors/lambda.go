@@ -0,0 +1,26 @@
+package processors
+
+import (
+	"regexp"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// LambdaProcessor always shows the request id of AWS Lambda logs, which
+// would otherwise be hidden by the field length limit, to group the entries
+// of an invocation.
+type LambdaProcessor struct {
+}
+
+func (p *LambdaProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return requestIDPattern.MatchString(gjson.GetBytes(line.JSON, "requestId").String())
+}
+
+func (p *LambdaProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	entry.IncludeFields = append(entry.IncludeFields, "requestId")
+	return nil
+}
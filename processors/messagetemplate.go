@@ -0,0 +1,104 @@
+package processors
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var clefExcludes = []string{"@t", "@mt", "@m", "@l", "@i", "@r"}
+var serilogExcludes = []string{"Timestamp", "Level", "MessageTemplate", "RenderedMessage", "Properties"}
+
+// messageHole matches a property of a message template, like {UserId},
+// {@Order}, {Elapsed:0.00} or {Name,-10}.
+var messageHole = regexp.MustCompile(`\{[@$]?([A-Za-z0-9_.]+)(,-?[0-9]+)?(:[^{}]+)?\}`)
+
+// MessageTemplateProcessor substitutes the properties of an entry into its
+// message template, like "user {UserId} logged in". It handles the compact
+// json of Serilog (CLEF, with @mt), the json of its JsonFormatter (with
+// MessageTemplate and Properties) and messages of other loggers that refer
+// to their fields the same way.
+type MessageTemplateProcessor struct {
+}
+
+func (p *MessageTemplateProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	if gjson.GetBytes(line.JSON, "@mt").Exists() || gjson.GetBytes(line.JSON, "MessageTemplate").Exists() {
+		return true
+	}
+	for _, hole := range messageHole.FindAllStringSubmatch(entry.Message, -1) {
+		if gjson.GetBytes(line.JSON, hole[1]).Exists() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *MessageTemplateProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	template, properties := entry.Message, gjson.ParseBytes(line.JSON)
+	switch {
+	case gjson.GetBytes(line.JSON, "@mt").Exists():
+		template = gjson.GetBytes(line.JSON, "@mt").String()
+		entry.Severity = gjson.GetBytes(line.JSON, "@l").String()
+		if entry.Severity == "" {
+			// CLEF leaves out the level of information events:
+			entry.Severity = "Information"
+		}
+		p.timestamp(entry, gjson.GetBytes(line.JSON, "@t"))
+		entry.ExcludeFields = append(entry.ExcludeFields, clefExcludes...)
+	case gjson.GetBytes(line.JSON, "MessageTemplate").Exists():
+		template = gjson.GetBytes(line.JSON, "MessageTemplate").String()
+		properties = gjson.GetBytes(line.JSON, "Properties")
+		entry.Severity = gjson.GetBytes(line.JSON, "Level").String()
+		p.timestamp(entry, gjson.GetBytes(line.JSON, "Timestamp"))
+		entry.ExcludeFields = append(entry.ExcludeFields, serilogExcludes...)
+		entry.FlattenFields = append(entry.FlattenFields, "Properties")
+	}
+	entry.Message, entry.Substitutions = p.render(entry, template, properties)
+	return nil
+}
+
+// render substitutes the properties into the template and returns the
+// message with the byte ranges of the substituted values. The substituted
+// properties are left out of the fields, holes without a property are kept
+// as they are.
+func (p *MessageTemplateProcessor) render(entry *structure.Entry, template string, properties gjson.Result) (string, [][2]int) {
+	var b strings.Builder
+	var substitutions [][2]int
+	last := 0
+	for _, hole := range messageHole.FindAllStringSubmatchIndex(template, -1) {
+		// {{ and }} are escaped braces:
+		if hole[0] > 0 && template[hole[0]-1] == '{' {
+			continue
+		}
+		name := template[hole[2]:hole[3]]
+		value := properties.Get(name)
+		if !value.Exists() {
+			continue
+		}
+		b.WriteString(unescapeBraces(template[last:hole[0]]))
+		start := b.Len()
+		b.WriteString(value.String())
+		substitutions = append(substitutions, [2]int{start, b.Len()})
+		last = hole[1]
+		entry.ExcludeFields = append(entry.ExcludeFields, name)
+	}
+	b.WriteString(unescapeBraces(template[last:]))
+	return b.String(), substitutions
+}
+
+func (p *MessageTemplateProcessor) timestamp(entry *structure.Entry, value gjson.Result) {
+	if entry.Timestamp != nil || !value.Exists() {
+		return
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value.String()); err == nil {
+		entry.Timestamp = &t
+	}
+}
+
+func unescapeBraces(text string) string {
+	return strings.NewReplacer("{{", "{", "}}", "}").Replace(text)
+}
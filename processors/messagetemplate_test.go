@@ -0,0 +1,50 @@
+package processors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &MessageTemplateProcessor{}, `{"@t":"2023-06-16T12:51:36.123Z","@mt":"User {UserId} logged in from {Ip} in {Elapsed:0.00} ms","UserId":42,"Ip":"10.0.0.1","Elapsed":12.5}`)
+	if got, want := entry.Message, "User 42 logged in from 10.0.0.1 in 12.5 ms"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "Information"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if entry.Timestamp == nil || entry.Timestamp.Second() != 36 {
+		t.Errorf("entry.Timestamp = %v, want 2023-06-16T12:51:36.123Z", entry.Timestamp)
+	}
+	if got, want := entry.Substitutions, [][2]int{{5, 7}, {23, 31}, {35, 39}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entry.Substitutions = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "UserId"), true; got != want {
+		t.Errorf("entry.ExcludeFields['UserId'] = %v, want %v", got, want)
+	}
+
+	entry = run(t, &MessageTemplateProcessor{}, `{"Timestamp":"2023-06-16T12:51:37Z","Level":"Warning","MessageTemplate":"Disk {{Drive}} {Drive} at {Usage}%","Properties":{"Drive":"C:","Usage":91}}`)
+	if got, want := entry.Message, "Disk {Drive} C: at 91%"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "Warning"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := has(entry.FlattenFields, "Properties"), true; got != want {
+		t.Errorf("entry.FlattenFields['Properties'] = %v, want %v", got, want)
+	}
+}
+
+func TestMessageTemplate_Fields(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &MessageTemplateProcessor{}, `{"level":"info","msg":"order {order_id} shipped by {carrier}","order_id":"A-7"}`)
+	if got, want := entry.Message, "order A-7 shipped by {carrier}"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "order_id"), true; got != want {
+		t.Errorf("entry.ExcludeFields['order_id'] = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package processors
+
+import (
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// asctimeFormats are the formats of the asctime of Python's logging module,
+// with and without milliseconds.
+var asctimeFormats = []string{"2006-01-02 15:04:05,000", "2006-01-02 15:04:05"}
+
+// StructlogProcessor uses the event of Python's structlog as message.
+type StructlogProcessor struct {
+}
+
+func (p *StructlogProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return entry.Message == "" && gjson.GetBytes(line.JSON, "event").Type == gjson.String
+}
+
+func (p *StructlogProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	entry.Message = gjson.GetBytes(line.JSON, "event").String()
+	entry.ExcludeFields = append(entry.ExcludeFields, "event")
+	return nil
+}
+
+// PythonJSONLoggerProcessor maps the attributes of the log records of
+// python-json-logger, like levelname and asctime.
+type PythonJSONLoggerProcessor struct {
+}
+
+func (p *PythonJSONLoggerProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "levelname").Exists() || gjson.GetBytes(line.JSON, "asctime").Exists()
+}
+
+func (p *PythonJSONLoggerProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if levelname := gjson.GetBytes(line.JSON, "levelname").String(); levelname != "" && entry.Severity == "" {
+		entry.Severity = levelname
+	}
+	asctime := gjson.GetBytes(line.JSON, "asctime").String()
+	for _, layout := range asctimeFormats {
+		if t, err := time.ParseInLocation(layout, asctime, time.Local); err == nil && entry.Timestamp == nil {
+			entry.Timestamp = &t
+			break
+		}
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, "levelname", "levelno", "asctime", "taskName")
+	return nil
+}
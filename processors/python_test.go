@@ -0,0 +1,29 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestStructlog(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &StructlogProcessor{}, `{"event":"user logged in","level":"info","logger":"app.auth"}`)
+	if got, want := entry.Message, "user logged in"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "event"), true; got != want {
+		t.Errorf("entry.ExcludeFields['event'] = %v, want %v", got, want)
+	}
+}
+
+func TestPythonJSONLogger(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &PythonJSONLoggerProcessor{}, `{"asctime":"2023-06-16 12:51:38,789","levelname":"WARNING","name":"app","message":"disk almost full"}`)
+	if got, want := entry.Severity, "WARNING"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Timestamp.Format("2006-01-02 15:04:05.000"), "2023-06-16 12:51:38.789"; got != want {
+		t.Errorf("entry.Timestamp = %v, want %v", got, want)
+	}
+}
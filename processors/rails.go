@@ -0,0 +1,67 @@
+package processors
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// semanticLoggerLevels maps the level_index of semantic_logger.
+var semanticLoggerLevels = map[int64]string{
+	0: "TRACE",
+	1: "DEBUG",
+	2: "INFO",
+	3: "WARNING",
+	4: "ERROR",
+	5: "FATAL",
+}
+
+// LogrageProcessor turns the request logs of lograge into a request line,
+// like "GET /users 200 58.33ms", with the level derived from the status.
+type LogrageProcessor struct {
+}
+
+func (p *LogrageProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	for _, key := range []string{"method", "path", "status", "duration", "controller"} {
+		if !gjson.GetBytes(line.JSON, key).Exists() {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *LogrageProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	status := gjson.GetBytes(line.JSON, "status").Int()
+	duration := gjson.GetBytes(line.JSON, "duration").Float()
+	entry.Message = fmt.Sprintf("%s %s %d %sms",
+		gjson.GetBytes(line.JSON, "method").String(),
+		gjson.GetBytes(line.JSON, "path").String(),
+		status,
+		strconv.FormatFloat(duration, 'f', -1, 64))
+	if entry.Severity == "" {
+		entry.Severity = structure.StatusSeverity(int(status))
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, "method", "path", "status", "duration")
+	return nil
+}
+
+// SemanticLoggerProcessor handles the json format of Ruby's semantic_logger,
+// the payload and named tags are shown as regular fields.
+type SemanticLoggerProcessor struct {
+}
+
+func (p *SemanticLoggerProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "level_index").Type == gjson.Number
+}
+
+func (p *SemanticLoggerProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if entry.Severity == "" {
+		entry.Severity = semanticLoggerLevels[gjson.GetBytes(line.JSON, "level_index").Int()]
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, "level_index", "application", "environment", "thread", "duration_ms")
+	entry.FlattenFields = append(entry.FlattenFields, "payload", "named_tags")
+	return nil
+}
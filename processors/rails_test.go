@@ -0,0 +1,31 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestLograge(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &LogrageProcessor{}, `{"method":"GET","path":"/users","format":"html","controller":"UsersController","action":"index","status":503,"duration":58.33}`)
+	if got, want := entry.Message, "GET /users 503 58.33ms"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "ERROR"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+}
+
+func TestSemanticLogger(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &SemanticLoggerProcessor{}, `{"timestamp":"2023-06-16T12:51:36Z","level_index":3,"message":"slow","payload":{"ms":1200},"named_tags":{"request_id":"a1"}}`)
+	if got, want := entry.Severity, "WARNING"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	for _, field := range []string{"payload", "named_tags"} {
+		if got, want := has(entry.FlattenFields, field), true; got != want {
+			t.Errorf("entry.FlattenFields[%q] = %v, want %v", field, got, want)
+		}
+	}
+}
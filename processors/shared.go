@@ -13,4 +13,23 @@ type Processor interface {
 var All = []Processor{
 	&NestedProcessor{},
 	&JournaldProcessor{},
+	&LambdaProcessor{},
+	&CloudTrailProcessor{},
+	&AzureProcessor{},
+	&CloudLoggingProcessor{},
+	&DatadogProcessor{},
+	&SplunkProcessor{},
+	&CloudflareProcessor{},
+	&EnvoyProcessor{},
+	&CIProcessor{},
+	&SpringProcessor{},
+	&LogrageProcessor{},
+	&SemanticLoggerProcessor{},
+	&StructlogProcessor{},
+	&PythonJSONLoggerProcessor{},
+	&HashiCorpProcessor{},
+	&ZerologProcessor{},
+	&SlogProcessor{},
+	&CockroachProcessor{},
+	&MessageTemplateProcessor{},
 }
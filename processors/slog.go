@@ -0,0 +1,36 @@
+package processors
+
+import (
+	"regexp"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// slogLevel matches the levels of slog, which are written relative to the
+// nearest named level, like INFO or WARN+2.
+var slogLevel = regexp.MustCompile(`^(DEBUG|INFO|WARN|ERROR)([+-][0-9]+)?$`)
+
+// SlogProcessor handles the json of Go's log/slog. Its groups are nested
+// objects, their members are shown with dotted keys like req.method.
+type SlogProcessor struct {
+}
+
+func (p *SlogProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	level := gjson.GetBytes(line.JSON, "level")
+	return level.Type == gjson.String && slogLevel.MatchString(level.String()) &&
+		gjson.GetBytes(line.JSON, "time").Exists() && gjson.GetBytes(line.JSON, "msg").Exists()
+}
+
+func (p *SlogProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	// the offset of custom levels, like the +2 of INFO+2, is left out:
+	entry.Severity = slogLevel.FindStringSubmatch(gjson.GetBytes(line.JSON, "level").String())[1]
+	gjson.ParseBytes(line.JSON).ForEach(func(key, value gjson.Result) bool {
+		if value.IsObject() && key.String() != "source" {
+			entry.IncludeFields = append(entry.IncludeFields, key.String())
+		}
+		return true
+	})
+	return nil
+}
@@ -0,0 +1,20 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestSlog(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &SlogProcessor{}, `{"time":"2023-06-16T12:51:36Z","level":"WARN+2","source":{"function":"main.handle","file":"server/handler.go","line":42},"msg":"slow request","req":{"method":"GET","path":"/cart"},"status":200}`)
+	if got, want := entry.Severity, "WARN"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := has(entry.IncludeFields, "req"), true; got != want {
+		t.Errorf("entry.IncludeFields['req'] = %v, want %v", got, want)
+	}
+	if got, want := has(entry.IncludeFields, "source"), false; got != want {
+		t.Errorf("entry.IncludeFields['source'] = %v, want %v", got, want)
+	}
+}
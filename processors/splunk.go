@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var splunkExcludes = []string{"event", "sourcetype", "index"}
+
+// SplunkProcessor handles the events of the Splunk HTTP Event Collector. An
+// event is either text, which is the message, or json, which is shown like
+// any other entry. The time is in decimal seconds and the sourcetype is the
+// logger, unless it's one of the builtin ones like _json.
+type SplunkProcessor struct {
+}
+
+func (p *SplunkProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	if !gjson.GetBytes(line.JSON, "event").Exists() {
+		return false
+	}
+	return gjson.GetBytes(line.JSON, "sourcetype").Exists() || gjson.GetBytes(line.JSON, "index").Exists() || gjson.GetBytes(line.JSON, "fields").IsObject()
+}
+
+func (p *SplunkProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if seconds, err := strconv.ParseFloat(gjson.GetBytes(line.JSON, "time").String(), 64); err == nil {
+		sec, dec := math.Modf(seconds)
+		t := time.Unix(int64(sec), int64(dec*1e9)).UTC()
+		entry.Timestamp = &t
+	}
+	event := gjson.GetBytes(line.JSON, "event")
+	if event.Type == gjson.String && strings.HasPrefix(event.Str, "{") && gjson.Valid(event.Str) {
+		event = gjson.Parse(event.Str)
+	}
+	if event.IsObject() {
+		for _, key := range []string{"message", "msg"} {
+			if message := event.Get(key); message.Exists() && entry.Message == "" {
+				entry.Message = message.String()
+				entry.ExcludeFields = append(entry.ExcludeFields, key)
+			}
+		}
+		for _, key := range []string{"severity", "level", "log_level"} {
+			if level := event.Get(key); level.Exists() && entry.Severity == "" {
+				entry.Severity = level.String()
+				entry.ExcludeFields = append(entry.ExcludeFields, key)
+			}
+		}
+		entry.FlattenFields = append(entry.FlattenFields, "event")
+	} else {
+		entry.Message = event.String()
+	}
+	if sourcetype := gjson.GetBytes(line.JSON, "sourcetype").String(); !strings.HasPrefix(sourcetype, "_") {
+		entry.Logger = sourcetype
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, splunkExcludes...)
+	entry.FlattenFields = append(entry.FlattenFields, "fields")
+	return nil
+}
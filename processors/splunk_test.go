@@ -0,0 +1,34 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestSplunk(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &SplunkProcessor{}, `{"time":"1686919896.5","host":"web-1","sourcetype":"nginx","index":"web","event":"GET /cart 200","fields":{"region":"eu"}}`)
+	if got, want := entry.Message, "GET /cart 200"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Timestamp.Format("15:04:05.000"), "12:51:36.500"; got != want {
+		t.Errorf("entry.Timestamp = %v, want %v", got, want)
+	}
+	if got, want := entry.Logger, "nginx"; got != want {
+		t.Errorf("entry.Logger = %v, want %v", got, want)
+	}
+
+	entry = run(t, &SplunkProcessor{}, `{"time":1686919897,"sourcetype":"_json","event":"{\"level\":\"error\",\"msg\":\"order failed\"}"}`)
+	if got, want := entry.Message, "order failed"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "error"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Logger, ""; got != want {
+		t.Errorf("entry.Logger = %v, want %v", got, want)
+	}
+	if got, want := has(entry.FlattenFields, "event"), true; got != want {
+		t.Errorf("entry.FlattenFields['event'] = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package processors
+
+import (
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// springExcludes are the keys of the logstash encoder that are hidden by
+// default, these can still be shown with --include-fields.
+var springExcludes = []string{"@version", "thread_name", "level_value"}
+
+// SpringProcessor handles the json logs of Spring Boot and other Java
+// services using the logstash-logback-encoder.
+type SpringProcessor struct {
+}
+
+func (p *SpringProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return gjson.GetBytes(line.JSON, "@version").Exists() && gjson.GetBytes(line.JSON, "logger_name").Exists()
+}
+
+func (p *SpringProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	entry.ExcludeFields = append(entry.ExcludeFields, springExcludes...)
+	return nil
+}
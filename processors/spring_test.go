@@ -0,0 +1,16 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestSpring(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &SpringProcessor{}, `{"@timestamp":"2023-06-16T12:51:36.123+02:00","@version":"1","message":"Started","logger_name":"c.e.Application","thread_name":"main","level":"INFO","level_value":20000}`)
+	for _, field := range []string{"@version", "thread_name", "level_value"} {
+		if got, want := has(entry.ExcludeFields, field), true; got != want {
+			t.Errorf("entry.ExcludeFields[%q] = %v, want %v", field, got, want)
+		}
+	}
+}
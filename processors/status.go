@@ -0,0 +1,31 @@
+package processors
+
+import (
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// StatusLevelProcessor derives the level of entries that are logged at info
+// (or without a level) from their http or gRPC status, for producers that
+// log every request at the same level.
+type StatusLevelProcessor struct {
+}
+
+func (p *StatusLevelProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	return structure.SeverityRank(entry.Severity) <= structure.SeverityRank("INFO")
+}
+
+func (p *StatusLevelProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	for _, key := range append(structure.StatusFields, structure.GRPCFields...) {
+		value := gjson.GetBytes(line.JSON, key)
+		if !value.Exists() {
+			continue
+		}
+		severity := structure.FieldStatusSeverity(key, value.Value())
+		if structure.SeverityRank(severity) > structure.SeverityRank(entry.Severity) {
+			entry.Severity = severity
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestStatusLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		in       string
+		severity string
+	}{
+		{"server error", `{"level":"info","status":503}`, "ERROR"},
+		{"client error", `{"status_code":"404"}`, "WARNING"},
+		{"grpc", `{"level":"debug","grpc_code":"Unavailable"}`, "ERROR"},
+		{"success", `{"level":"info","status":200}`, "info"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := run(t, &StatusLevelProcessor{}, test.in)
+			if got, want := entry.Severity, test.severity; got != want {
+				t.Errorf("entry.Severity = %v, want %v", got, want)
+			}
+		})
+	}
+}
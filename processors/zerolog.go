@@ -0,0 +1,67 @@
+package processors
+
+import (
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+var zerologExcludes = []string{"l", "m", "t"}
+
+// ZerologProcessor handles zerolog configured with the short keys l, m and t
+// instead of level, message and time. The default keys of zerolog, and its
+// caller and error fields, need no processor.
+type ZerologProcessor struct {
+}
+
+func (p *ZerologProcessor) Detect(line *stream.Line, entry *structure.Entry) bool {
+	level := gjson.GetBytes(line.JSON, "l")
+	if level.Type != gjson.String || structure.SeverityRank(level.String()) == -1 {
+		return false
+	}
+	return gjson.GetBytes(line.JSON, "m").Exists() || gjson.GetBytes(line.JSON, "t").Exists()
+}
+
+func (p *ZerologProcessor) Process(line *stream.Line, entry *structure.Entry) error {
+	if entry.Severity == "" {
+		entry.Severity = gjson.GetBytes(line.JSON, "l").String()
+	}
+	if entry.Message == "" {
+		entry.Message = gjson.GetBytes(line.JSON, "m").String()
+	}
+	if entry.Timestamp == nil {
+		entry.Timestamp = zerologTime(gjson.GetBytes(line.JSON, "t"))
+	}
+	entry.ExcludeFields = append(entry.ExcludeFields, zerologExcludes...)
+	return nil
+}
+
+// zerologTime parses the time of zerolog: RFC 3339 by default, or a unix
+// time in seconds, milliseconds or microseconds.
+func zerologTime(value gjson.Result) *time.Time {
+	var t time.Time
+	switch value.Type {
+	case gjson.String:
+		parsed, err := time.Parse(time.RFC3339Nano, value.String())
+		if err != nil {
+			return nil
+		}
+		t = parsed
+	case gjson.Number:
+		n := value.Int()
+		switch {
+		case n > 1e15:
+			t = time.UnixMicro(n)
+		case n > 1e12:
+			t = time.UnixMilli(n)
+		default:
+			t = time.Unix(n, 0)
+		}
+		t = t.UTC()
+	default:
+		return nil
+	}
+	return &t
+}
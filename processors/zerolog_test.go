@@ -0,0 +1,30 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestZerolog(t *testing.T) {
+	t.Parallel()
+
+	entry := run(t, &ZerologProcessor{}, `{"l":"warn","t":"2023-06-16T12:51:36Z","m":"cache miss","key":"user:42"}`)
+	if got, want := entry.Message, "cache miss"; got != want {
+		t.Errorf("entry.Message = %v, want %v", got, want)
+	}
+	if got, want := entry.Severity, "warn"; got != want {
+		t.Errorf("entry.Severity = %v, want %v", got, want)
+	}
+	if got, want := entry.Timestamp.Format("2006-01-02 15:04:05"), "2023-06-16 12:51:36"; got != want {
+		t.Errorf("entry.Timestamp = %v, want %v", got, want)
+	}
+	if got, want := has(entry.ExcludeFields, "m"), true; got != want {
+		t.Errorf("entry.ExcludeFields['m'] = %v, want %v", got, want)
+	}
+
+	for _, input := range []string{`{"l":"info","t":1686919896,"m":"started"}`, `{"l":"info","t":1686919896000,"m":"started"}`} {
+		entry = run(t, &ZerologProcessor{}, input)
+		if got, want := entry.Timestamp.Format("2006-01-02 15:04:05"), "2023-06-16 12:51:36"; got != want {
+			t.Errorf("entry.Timestamp = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	_ "net/http/pprof" // registers the /debug/pprof handlers
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+)
+
+// profiling contains the hidden flags to debug the performance of jl on the
+// logs of a user, these are left out of the usage.
+type profiling struct {
+	pprof      string
+	cpuProfile string
+	memProfile string
+}
+
+// profilingFlags removes the profiling flags from the arguments before a --,
+// in the form --flag value or --flag=value.
+func profilingFlags(argv []string) ([]string, profiling, error) {
+	var p profiling
+	flags := map[string]*string{
+		"--pprof":      &p.pprof,
+		"--cpuprofile": &p.cpuProfile,
+		"--memprofile": &p.memProfile,
+	}
+	rest := make([]string, 0, len(argv))
+	for i := 0; i < len(argv); i++ {
+		// the arguments after -- belong to a command, like the remote
+		// command of jl ssh:
+		if argv[i] == "--" {
+			rest = append(rest, argv[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(argv[i], "=")
+		target, ok := flags[name]
+		if !ok {
+			rest = append(rest, argv[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(argv) {
+				return nil, p, fmt.Errorf("%s requires an argument", name)
+			}
+			i++
+			value = argv[i]
+		}
+		*target = value
+	}
+	return rest, p, nil
+}
+
+// start serves pprof and starts the cpu profile, the returned function
+// stops the cpu profile and writes the memory profile.
+func (p profiling) start() (func() error, error) {
+	if p.pprof != "" {
+		go func() {
+			if err := http.ListenAndServe(p.pprof, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to serve pprof: %v\n", err)
+			}
+		}()
+	}
+	var cpu *os.File
+	if p.cpuProfile != "" {
+		var err error
+		cpu, err = os.Create(p.cpuProfile)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, err
+		}
+	}
+	return func() error {
+		var err error
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			err = cpu.Close()
+		}
+		if p.memProfile != "" {
+			err = errors.Join(err, writeHeapProfile(p.memProfile))
+		}
+		return err
+	}, nil
+}
+
+func writeHeapProfile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	return errors.Join(pprof.WriteHeapProfile(f), f.Close())
+}
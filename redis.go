@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/koenbollen/jl/redis"
+	"github.com/koenbollen/jl/stream"
+)
+
+// openRedis reads the entries that are added to the Redis Stream, as a
+// consumer of the group when one is given. Without the password of
+// --password-file, REDISCLI_AUTH is used, like redis-cli does.
+func openRedis(addr, key, group, password string) (stream.Stream, error) {
+	conn, err := redis.Dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	if password == "" {
+		password = os.Getenv("REDISCLI_AUTH")
+	}
+	if password != "" {
+		if _, err := conn.Do("AUTH", password); err != nil {
+			return nil, err
+		}
+	}
+	consumer, _ := os.Hostname()
+	consumer = fmt.Sprintf("jl-%s-%d", consumer, os.Getpid())
+	reader, err := redis.NewStreamReader(conn, key, group, consumer)
+	if err != nil {
+		return nil, err
+	}
+	r, w := io.Pipe()
+	go func() {
+		for {
+			entries, err := reader.Read()
+			if err != nil {
+				w.CloseWithError(err)
+				return
+			}
+			for _, entry := range entries {
+				if _, err := w.Write(append(entry.JSON(), '\n')); err != nil {
+					return
+				}
+			}
+			if err := reader.Ack(entries); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return stream.NewSource(r, key), nil
+}
@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// block is how long (in milliseconds) a read waits for new entries.
+const block = "5000"
+
+// Entry is an entry of a stream with its fields and values in order.
+type Entry struct {
+	ID     string
+	Fields []string
+}
+
+// JSON returns the entry as json. An entry with a single field that holds a
+// json object is that object, other entries are an object of their fields.
+func (e Entry) JSON() []byte {
+	if len(e.Fields) == 2 {
+		value := bytes.TrimSpace([]byte(e.Fields[1]))
+		if bytes.HasPrefix(value, []byte("{")) && json.Valid(value) {
+			buf := &bytes.Buffer{}
+			if json.Compact(buf, value) == nil {
+				return buf.Bytes()
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i := 0; i+1 < len(e.Fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.Fields[i])
+		value, _ := json.Marshal(e.Fields[i+1])
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// StreamReader reads the new entries of a stream, as a consumer of a
+// consumer group when a group is given.
+type StreamReader struct {
+	conn     *Conn
+	key      string
+	group    string
+	consumer string
+	last     string
+}
+
+// NewStreamReader returns a reader of the entries that are added to the
+// stream from now on. The consumer group is created when it doesn't exist,
+// its consumers read the entries that weren't read by the group yet.
+func NewStreamReader(conn *Conn, key, group, consumer string) (*StreamReader, error) {
+	if group != "" {
+		_, err := conn.Do("XGROUP", "CREATE", key, group, "$", "MKSTREAM")
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return nil, err
+		}
+	}
+	return &StreamReader{conn: conn, key: key, group: group, consumer: consumer, last: "$"}, nil
+}
+
+// Read waits for new entries and returns them, or none when no entries were
+// added for a while.
+func (r *StreamReader) Read() ([]Entry, error) {
+	args := []string{"XREAD", "COUNT", "100", "BLOCK", block, "STREAMS", r.key, r.last}
+	if r.group != "" {
+		args = []string{"XREADGROUP", "GROUP", r.group, r.consumer, "COUNT", "100", "BLOCK", block, "STREAMS", r.key, ">"}
+	}
+	reply, err := r.conn.Do(args...)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+	entries, err := parseEntries(reply)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		r.last = entries[len(entries)-1].ID
+	}
+	return entries, nil
+}
+
+// Ack acknowledges the entries to the consumer group, so they aren't pending
+// anymore.
+func (r *StreamReader) Ack(entries []Entry) error {
+	if r.group == "" || len(entries) == 0 {
+		return nil
+	}
+	args := []string{"XACK", r.key, r.group}
+	for _, entry := range entries {
+		args = append(args, entry.ID)
+	}
+	_, err := r.conn.Do(args...)
+	return err
+}
+
+// parseEntries returns the entries of the reply of XREAD, a list of streams
+// with their entries: [[key, [[id, [field, value, ...]], ...]]].
+func parseEntries(reply interface{}) ([]Entry, error) {
+	var entries []Entry
+	streams, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply: %v", reply)
+	}
+	for _, s := range streams {
+		stream, ok := s.([]interface{})
+		if !ok || len(stream) != 2 {
+			return nil, fmt.Errorf("unexpected stream: %v", s)
+		}
+		items, _ := stream[1].([]interface{})
+		for _, i := range items {
+			item, ok := i.([]interface{})
+			if !ok || len(item) != 2 {
+				return nil, fmt.Errorf("unexpected entry: %v", i)
+			}
+			entry := Entry{}
+			entry.ID, _ = item[0].(string)
+			values, _ := item[1].([]interface{})
+			for _, value := range values {
+				field, _ := value.(string)
+				entry.Fields = append(entry.Fields, field)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
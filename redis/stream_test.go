@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// serve answers the commands of the connection with the replies, in order,
+// and sends the commands it received on the channel.
+func serve(t *testing.T, conn net.Conn, replies []string, commands chan<- string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for _, reply := range replies {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		var args []string
+		for i := 0; i < n; i++ {
+			_, _ = reader.ReadString('\n')
+			arg, _ := reader.ReadString('\n')
+			args = append(args, strings.TrimSpace(arg))
+		}
+		commands <- strings.Join(args, " ")
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+func TestStreamReader(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	commands := make(chan string, 10)
+	go serve(t, server, []string{
+		"-BUSYGROUP Consumer Group name already exists\r\n",
+		"*-1\r\n",
+		"*1\r\n*2\r\n$8\r\napp:logs\r\n*2\r\n" +
+			"*2\r\n$3\r\n1-0\r\n*2\r\n$4\r\ndata\r\n$28\r\n{\"level\":\"info\",\"msg\":\"hi!\"}\r\n" +
+			"*2\r\n$3\r\n2-0\r\n*4\r\n$5\r\nlevel\r\n$4\r\nwarn\r\n$3\r\nmsg\r\n$4\r\nslow\r\n",
+		":2\r\n",
+	}, commands)
+
+	reader, err := NewStreamReader(NewConn(client), "app:logs", "jl", "laptop")
+	if err != nil {
+		t.Fatalf("NewStreamReader() = %v", err)
+	}
+	entries, err := reader.Read()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Read() = %v, %v, want no entries", entries, err)
+	}
+	entries, err = reader.Read()
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, string(entry.JSON()))
+	}
+	expect := `{"level":"info","msg":"hi!"},{"level":"warn","msg":"slow"}`
+	if got := strings.Join(lines, ","); got != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, expect)
+	}
+	if err := reader.Ack(entries); err != nil {
+		t.Fatalf("Ack() = %v", err)
+	}
+
+	for _, expect := range []string{
+		"XGROUP CREATE app:logs jl $ MKSTREAM",
+		"XREADGROUP GROUP jl laptop COUNT 100 BLOCK 5000 STREAMS app:logs >",
+		"XREADGROUP GROUP jl laptop COUNT 100 BLOCK 5000 STREAMS app:logs >",
+		"XACK app:logs jl 1-0 2-0",
+	} {
+		if got := <-commands; got != expect {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, expect)
+		}
+	}
+}
+
+func TestEntryJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fields []string
+		expect string
+	}{
+		{[]string{"json", "{\n  \"msg\": \"hi\"\n}"}, `{"msg":"hi"}`},
+		{[]string{"msg", "not {json"}, `{"msg":"not {json"}`},
+		{[]string{"msg", "quote \"me\"", "n", "1"}, `{"msg":"quote \"me\"","n":"1"}`},
+	}
+	for _, tt := range tests {
+		if got := string(Entry{ID: "1-0", Fields: tt.fields}.JSON()); got != tt.expect {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, tt.expect)
+		}
+	}
+}
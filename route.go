@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/koenbollen/jl/filter"
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/mattn/go-isatty"
+)
+
+// route duplicates the entries that match its expression into a file, or
+// another terminal, while all entries keep going to the main output.
+type route struct {
+	expr      *filter.Expr
+	file      *os.File
+	formatter *structure.Formatter
+}
+
+// newRoutes parses rules in the form expression:>file, like
+// tenant=acme:>acme.log, or expression:>>file to append to the file. The
+// entries are formatted like the main output, colored when the file is a
+// terminal.
+func newRoutes(rules []string, formatter *structure.Formatter) ([]*route, error) {
+	var routes []*route
+	for _, rule := range rules {
+		i := strings.LastIndex(rule, ":>")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid route %q, expected expression:>file", rule)
+		}
+		expr, err := filter.Parse(rule[:i])
+		if err != nil {
+			return nil, err
+		}
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		path := rule[i+2:]
+		if strings.HasPrefix(path, ">") {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+			path = path[1:]
+		}
+		file, err := os.OpenFile(path, flags, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		r := &route{expr: expr, file: file, formatter: formatter.Clone(file)}
+		r.formatter.Colorize = isatty.IsTerminal(file.Fd())
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// Route writes the entry to the file when it matches. The entry is copied,
+// as formatting it changes it.
+func (r *route) Route(line *stream.Line, entry *structure.Entry) error {
+	if !r.expr.Match(line.JSON, entry) {
+		return nil
+	}
+	copied := *entry
+	return r.formatter.Format(&copied, line.JSON, line.Prefix, line.Suffix)
+}
+
+func (r *route) Close() error {
+	if err := r.formatter.EndGroup(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
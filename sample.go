@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+// sampler keeps n of every m entries below a level, entries at or above it
+// are always kept. Warnings and errors are always kept, so the level can
+// only take in more levels, like info, and never lets errors be sampled
+// away.
+type sampler struct {
+	n, m     int
+	keepRank int
+	seen     int
+}
+
+// newSampler parses a rate in the form n/m, like 1/100, and the level from
+// which entries are always kept.
+func newSampler(rate, keepLevel string) (*sampler, error) {
+	before, after, ok := strings.Cut(rate, "/")
+	n, err := strconv.Atoi(before)
+	if !ok || err != nil {
+		return nil, fmt.Errorf("%q is not a rate like 1/100", rate)
+	}
+	m, err := strconv.Atoi(after)
+	if err != nil || n < 1 || m < n {
+		return nil, fmt.Errorf("%q is not a rate like 1/100", rate)
+	}
+	keepRank := structure.SeverityRank(keepLevel)
+	if keepRank == -1 {
+		return nil, fmt.Errorf("%q is not a known level", keepLevel)
+	}
+	if warning := structure.SeverityRank("WARNING"); keepRank > warning {
+		keepRank = warning
+	}
+	return &sampler{n: n, m: m, keepRank: keepRank}, nil
+}
+
+// Keep reports whether the entry is kept: when its level is at least the
+// keep level, or when it's one of the first n of every m other entries.
+// Entries without a level are sampled too.
+func (s *sampler) Keep(entry *structure.Entry) bool {
+	if structure.SeverityRank(entry.Severity) >= s.keepRank {
+		return true
+	}
+	kept := s.seen%s.m < s.n
+	s.seen++
+	return kept
+}
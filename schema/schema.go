@@ -0,0 +1,331 @@
+// Package schema validates log entries against a JSON Schema, supporting
+// the keywords that are useful to describe a logging contract: types,
+// required and additional properties, enums, ranges, patterns, composition
+// and local references.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root     map[string]interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile parses a JSON Schema document, compiling its patterns.
+func Compile(data []byte) (*Schema, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	s := &Schema{root: root, patterns: map[string]*regexp.Regexp{}}
+	if err := s.compilePatterns(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) compilePatterns(value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if pattern, ok := child.(string); ok && key == "pattern" {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+				s.patterns[pattern] = re
+			}
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate returns the violations of the schema by the json document, as
+// messages prefixed with the path of the offending value.
+func (s *Schema) Validate(raw []byte) []string {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return []string{"invalid json: " + err.Error()}
+	}
+	var errs []string
+	s.validate(s.root, doc, "", &errs, 0)
+	return errs
+}
+
+// maxDepth limits the nesting of references, to stop on cycles.
+const maxDepth = 64
+
+func (s *Schema) validate(schema map[string]interface{}, value interface{}, path string, errs *[]string, depth int) {
+	report := func(format string, args ...interface{}) {
+		at := path
+		if at == "" {
+			at = "entry"
+		}
+		*errs = append(*errs, at+": "+fmt.Sprintf(format, args...))
+	}
+	if depth > maxDepth {
+		report("schema nested too deep")
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		if err != nil {
+			report("%v", err)
+			return
+		}
+		s.validate(target, value, path, errs, depth+1)
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		report("must be %s, not %s", typeNames(t), typeOf(value))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		report("must be one of %s", formatValues(enum))
+	}
+	if c, ok := schema["const"]; ok && !equal(c, value) {
+		report("must be %s", formatValues([]interface{}{c}))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(schema, v, path, errs, depth, report)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				s.validate(items, item, join(path, strconv.Itoa(i)), errs, depth+1)
+			}
+		}
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			report("must have at least %v items", n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			report("must have at most %v items", n)
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			report("must be at least %v characters", n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			report("must be at most %v characters", n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !s.patterns[pattern].MatchString(v) {
+			report("must match %s", pattern)
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && v < n {
+			report("must be >= %v", n)
+		}
+		if n, ok := number(schema["maximum"]); ok && v > n {
+			report("must be <= %v", n)
+		}
+		if n, ok := number(schema["exclusiveMinimum"]); ok && v <= n {
+			report("must be > %v", n)
+		}
+		if n, ok := number(schema["exclusiveMaximum"]); ok && v >= n {
+			report("must be < %v", n)
+		}
+		if n, ok := number(schema["multipleOf"]); ok && n != 0 && math.Abs(math.Remainder(v, n)) > 1e-9 {
+			report("must be a multiple of %v", n)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				s.validate(sub, value, path, errs, depth+1)
+			}
+		}
+	}
+	if any, ok := schema["anyOf"].([]interface{}); ok && s.matching(any, value, depth) == 0 {
+		report("must match any of the schemas")
+	}
+	if one, ok := schema["oneOf"].([]interface{}); ok {
+		if n := s.matching(one, value, depth); n != 1 {
+			report("must match exactly one of the schemas, matches %d", n)
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok && s.valid(not, value, depth) {
+		report("must not match the schema")
+	}
+}
+
+func (s *Schema) validateObject(schema map[string]interface{}, object map[string]interface{}, path string, errs *[]string, depth int, report func(string, ...interface{})) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, key := range required {
+			if key, ok := key.(string); ok {
+				if _, exists := object[key]; !exists {
+					report("missing required %s", key)
+				}
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			s.validate(property, object[key], join(path, key), errs, depth+1)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				report("unexpected property %s", key)
+			}
+		case map[string]interface{}:
+			s.validate(additional, object[key], join(path, key), errs, depth+1)
+		}
+	}
+}
+
+// matching returns the number of schemas the value is valid against.
+func (s *Schema) matching(schemas []interface{}, value interface{}, depth int) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub, ok := sub.(map[string]interface{}); ok && s.valid(sub, value, depth) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Schema) valid(schema map[string]interface{}, value interface{}, depth int) bool {
+	var errs []string
+	s.validate(schema, value, "", &errs, depth+1)
+	return len(errs) == 0
+}
+
+// resolve returns the schema of a local reference, like #/$defs/level.
+func (s *Schema) resolve(ref string) (map[string]interface{}, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference %s, only local references are supported", ref)
+	}
+	var current interface{} = s.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %s", ref)
+		}
+		if current, ok = object[part]; !ok {
+			return nil, fmt.Errorf("unresolvable reference %s", ref)
+		}
+	}
+	target, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolvable reference %s", ref)
+	}
+	return target, nil
+}
+
+func matchesType(t interface{}, value interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, value)
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok && isType(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(name string, value interface{}) bool {
+	actual := typeOf(value)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func typeNames(t interface{}) string {
+	if names, ok := t.([]interface{}); ok {
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprint(name)
+		}
+		return strings.Join(parts, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func number(value interface{}) (float64, bool) {
+	n, ok := value.(float64)
+	return n, ok
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if equal(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+func formatValues(values []interface{}) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
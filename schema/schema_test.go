@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+const contract = `{
+	"type": "object",
+	"required": ["level", "msg"],
+	"properties": {
+		"level": {"$ref": "#/$defs/level"},
+		"msg": {"type": "string", "minLength": 1},
+		"duration_ms": {"type": "number", "minimum": 0},
+		"request_id": {"type": "string", "pattern": "^[0-9a-f]{8}$"},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+		"user": {
+			"type": "object",
+			"properties": {"id": {"type": ["integer", "string"]}},
+			"additionalProperties": false
+		}
+	},
+	"$defs": {
+		"level": {"enum": ["debug", "info", "warn", "error"]}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile([]byte(contract))
+	if err != nil {
+		t.Fatalf("Compile() = %v", err)
+	}
+	tests := []struct {
+		name   string
+		input  string
+		expect []string
+	}{
+		{"valid", `{"level": "info", "msg": "hello", "duration_ms": 1.5, "request_id": "0123abcd", "tags": ["a"], "user": {"id": 3}}`, nil},
+		{"missing", `{"level": "info"}`, []string{"entry: missing required msg"}},
+		{"enum", `{"level": "INFO", "msg": "hello"}`, []string{`level: must be one of ["debug","info","warn","error"]`}},
+		{"type", `{"level": "info", "msg": "hello", "duration_ms": "12ms"}`, []string{"duration_ms: must be number, not string"}},
+		{"minimum", `{"level": "info", "msg": "hello", "duration_ms": -1}`, []string{"duration_ms: must be >= 0"}},
+		{"pattern", `{"level": "info", "msg": "hello", "request_id": "nope"}`, []string{"request_id: must match ^[0-9a-f]{8}$"}},
+		{"items", `{"level": "info", "msg": "", "tags": ["a", 1, "c"]}`, []string{"msg: must be at least 1 characters", "tags.1: must be string, not integer", "tags: must have at most 2 items"}},
+		{"nested", `{"level": "info", "msg": "hello", "user": {"id": 1.5, "name": "koen"}}`, []string{"user.id: must be integer or string, not number", "user: unexpected property name"}},
+		{"not an object", `"hello"`, []string{"entry: must be object, not string"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := s.Validate([]byte(tt.input))
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestComposition(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile([]byte(`{
+		"anyOf": [{"required": ["msg"]}, {"required": ["message"]}],
+		"oneOf": [{"properties": {"code": {"type": "integer"}}}, {"properties": {"code": {"type": "string"}}}],
+		"not": {"required": ["password"]}
+	}`))
+	if err != nil {
+		t.Fatalf("Compile() = %v", err)
+	}
+	tests := []struct {
+		input  string
+		expect []string
+	}{
+		{`{"msg": "a", "code": 1}`, nil},
+		{`{"code": 1}`, []string{"entry: must match any of the schemas"}},
+		{`{"msg": "a", "code": true}`, []string{"entry: must match exactly one of the schemas, matches 0"}},
+		{`{"msg": "a", "password": "hunter2"}`, []string{"entry: must match exactly one of the schemas, matches 2", "entry: must not match the schema"}},
+	}
+	for _, tt := range tests {
+		if got := s.Validate([]byte(tt.input)); !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", got, tt.expect)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, schema := range []string{`{`, `[]`, `{"properties": {"a": {"pattern": "("}}}`} {
+		if _, err := Compile([]byte(schema)); err == nil {
+			t.Errorf("Compile(%q) = nil, want error", schema)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/koenbollen/jl/input"
+	"github.com/koenbollen/jl/stream"
+)
+
+// openOTLP receives the logs of OpenTelemetry exporters with OTLP/HTTP on
+// the http address, in both the protobuf and json encoding, and with OTLP
+// over gRPC on the grpc address. Either address may be empty. Every log
+// record becomes a line of json.
+func openOTLP(httpAddr, grpcAddr string) (stream.Stream, error) {
+	r, w := io.Pipe()
+	var servers []func() error
+	if httpAddr != "" {
+		listener, err := net.Listen("tcp", httpAddr)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, func() error { return http.Serve(listener, otlpHTTP(w)) })
+	}
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return nil, err
+		}
+		// gRPC clients talk HTTP/2 without TLS, with prior knowledge:
+		protocols := &http.Protocols{}
+		protocols.SetUnencryptedHTTP2(true)
+		server := &http.Server{Handler: otlpGRPC(w), Protocols: protocols}
+		servers = append(servers, func() error { return server.Serve(listener) })
+	}
+	for _, serve := range servers {
+		go func() {
+			w.CloseWithError(serve())
+		}()
+	}
+	return stream.NewSource(r, "otlp"), nil
+}
+
+// otlpHTTP handles the OTLP/HTTP requests, writing their log records to w.
+// All records of a request are written at once, so the records of concurrent
+// requests aren't interleaved.
+func otlpHTTP(w io.Writer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/logs", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body := io.Reader(req.Body)
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(req.Body)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		isJSON := contentType == "application/json"
+		lines := &bytes.Buffer{}
+		if err := input.WriteOTLPLogs(lines, data, isJSON); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := w.Write(lines.Bytes()); err != nil {
+			http.Error(rw, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if isJSON {
+			rw.Header().Set("Content-Type", "application/json")
+			_, _ = rw.Write([]byte("{}"))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/x-protobuf")
+	})
+	var grpcOnce sync.Once
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == "PRI" && req.ProtoMajor == 2 {
+			grpcOnce.Do(func() {
+				fmt.Fprintf(os.Stderr, "ignoring OTLP over gRPC from %s on the OTLP/HTTP address, use --otlp-grpc\n", req.RemoteAddr)
+			})
+			rw.Header().Set("Connection", "close")
+			http.Error(rw, "OTLP over gRPC is received on the --otlp-grpc address", http.StatusHTTPVersionNotSupported)
+			return
+		}
+		mux.ServeHTTP(rw, req)
+	})
+}
+
+// grpcExport is the method of the gRPC logs service of OTLP.
+const grpcExport = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// gRPC status codes, as sent in the grpc-status trailer.
+const (
+	grpcOK              = 0
+	grpcInvalidArgument = 3
+	grpcUnimplemented   = 12
+	grpcUnavailable     = 14
+)
+
+// otlpGRPC handles the Export calls of the gRPC logs service, writing their
+// log records to w. A call carries length prefixed protobuf messages, which
+// are answered with an empty response and the status in the trailers.
+func otlpGRPC(w io.Writer) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.ProtoMajor != 2 || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+			http.Error(rw, "expected a gRPC request", http.StatusUnsupportedMediaType)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/grpc")
+		status := func(code int, message string) {
+			rw.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+			if message != "" {
+				rw.Header().Set(http.TrailerPrefix+"Grpc-Message", url.PathEscape(message))
+			}
+		}
+		if req.URL.Path != grpcExport {
+			status(grpcUnimplemented, "unknown method "+req.URL.Path)
+			return
+		}
+		lines := &bytes.Buffer{}
+		for {
+			message, err := readGRPCMessage(req.Body, req.Header.Get("Grpc-Encoding"))
+			if err == io.EOF {
+				break
+			}
+			if err == errGRPCEncoding {
+				status(grpcUnimplemented, err.Error())
+				return
+			}
+			if err == nil {
+				err = input.WriteOTLPLogs(lines, message, false)
+			}
+			if err != nil {
+				status(grpcInvalidArgument, err.Error())
+				return
+			}
+		}
+		if _, err := w.Write(lines.Bytes()); err != nil {
+			status(grpcUnavailable, err.Error())
+			return
+		}
+		// an empty ExportLogsServiceResponse, uncompressed:
+		_, _ = rw.Write([]byte{0, 0, 0, 0, 0})
+		status(grpcOK, "")
+	})
+}
+
+// errGRPCEncoding is returned for a compressed message of another encoding
+// than gzip.
+var errGRPCEncoding = errors.New("only the gzip grpc-encoding is supported")
+
+// readGRPCMessage reads a length prefixed gRPC message, which is compressed
+// with the encoding when its flag is set.
+func readGRPCMessage(r io.Reader, encoding string) ([]byte, error) {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated grpc message")
+		}
+		return nil, err
+	}
+	message := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, errors.New("truncated grpc message")
+	}
+	if prefix[0] == 0 {
+		return message, nil
+	}
+	if encoding != "gzip" {
+		return nil, errGRPCEncoding
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(gz)
+}
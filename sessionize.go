@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// sessionizer groups the entries of every value of a key, like a user id,
+// into sessions: runs of entries without a gap of inactivity between them
+// longer than the given gap.
+type sessionizer struct {
+	key  string
+	gap  time.Duration
+	open map[string]*keySession
+	done []*keySession
+}
+
+// keySession is a session of the entries of a value of the key.
+type keySession struct {
+	value       string
+	first, last time.Time
+	entries     int
+}
+
+// newSessionizer parses a rule in the form key:gap, like user_id:30m.
+func newSessionizer(rule string) (*sessionizer, error) {
+	i := strings.LastIndex(rule, ":")
+	if i <= 0 {
+		return nil, fmt.Errorf("%q is not a key:gap pair, like user_id:30m", rule)
+	}
+	gap, err := time.ParseDuration(rule[i+1:])
+	if err != nil || gap <= 0 {
+		return nil, fmt.Errorf("%q has an invalid gap, like 30m", rule)
+	}
+	return &sessionizer{key: rule[:i], gap: gap, open: map[string]*keySession{}}, nil
+}
+
+// Add adds the entry to the session of its value of the key, starting a new
+// one after a gap, before or after the session as entries can be out of
+// order. Entries without the key or a time are left out.
+func (s *sessionizer) Add(raw []byte, entry *structure.Entry) {
+	value := gjson.GetBytes(raw, s.key).String()
+	if value == "" || entry.Timestamp == nil || entry.Timestamp.IsZero() {
+		return
+	}
+	t := *entry.Timestamp
+	current, ok := s.open[value]
+	if ok && (t.Sub(current.last) > s.gap || current.first.Sub(t) > s.gap) {
+		s.done = append(s.done, current)
+		ok = false
+	}
+	if !ok {
+		s.open[value] = &keySession{value: value, first: t, last: t, entries: 1}
+		return
+	}
+	if t.Before(current.first) {
+		current.first = t
+	}
+	if t.After(current.last) {
+		current.last = t
+	}
+	current.entries++
+}
+
+// Write writes a table of the sessions in the order they started, with
+// their duration and number of entries.
+func (s *sessionizer) Write(w io.Writer) error {
+	sessions := append([]*keySession(nil), s.done...)
+	for _, session := range s.open {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].first.Equal(sessions[j].first) {
+			return sessions[i].first.Before(sessions[j].first)
+		}
+		return sessions[i].value < sessions[j].value
+	})
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tstart\tend\tduration\tentries\n", s.key)
+	for _, session := range sessions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", session.value, session.first.Format("2006-01-02 15:04:05"),
+			session.last.Format("2006-01-02 15:04:05"), session.last.Sub(session.first), session.entries)
+	}
+	return tw.Flush()
+}
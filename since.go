@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+// timeLayouts are the layouts of --since and --until, times without a zone
+// are UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTimeBound parses the time of --since or --until, or a duration before
+// now, like 1h.
+func parseTimeBound(text string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(text); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time, like 2024-01-01 10:00, or a duration, like 1h", text)
+}
+
+// withinTime reports whether the entry is between since and until, a zero
+// time leaves that side open. Entries without a time are always within.
+func withinTime(entry *structure.Entry, since, until time.Time) bool {
+	if entry.Timestamp == nil || entry.Timestamp.IsZero() {
+		return true
+	}
+	if !since.IsZero() && entry.Timestamp.Before(since) {
+		return false
+	}
+	return until.IsZero() || !entry.Timestamp.After(until)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// openSSH runs the command on every host over ssh at the same time, the
+// lines of all hosts are merged and every line is prefixed with its host and
+// tagged with the stream it was written to.
+func openSSH(hosts, command []string, colorize bool) (stream.Stream, error) {
+	width := 0
+	for _, host := range hosts {
+		width = max(width, len(host))
+	}
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		prefix := structure.ColorName(host+strings.Repeat(" ", width-len(host)), colorize) + " | "
+		cmd := exec.Command("ssh", append([]string{"-T", host, "--"}, command...)...)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return nil, errors.New("the ssh command is required to run remote commands")
+			}
+			return nil, err
+		}
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			var copies sync.WaitGroup
+			copies.Add(2)
+			go copyPrefixed(&copies, stdoutW, stdout, prefix)
+			go copyPrefixed(&copies, stderrW, stderr, prefix)
+			copies.Wait()
+			if err := cmd.Wait(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", host, err)
+			}
+		}(host)
+	}
+	go func() {
+		wg.Wait()
+		stdoutW.Close()
+		stderrW.Close()
+	}()
+	source := strings.Join(hosts, ",")
+	return stream.MergeOutput(stream.NewSource(stdoutR, source), stream.NewSource(stderrR, source)), nil
+}
+
+// copyPrefixed copies the lines of r to w, every line prefixed with the
+// prefix and written at once.
+func copyPrefixed(wg *sync.WaitGroup, w io.Writer, r io.Reader, prefix string) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if _, err := w.Write([]byte(prefix + scanner.Text() + "\n")); err != nil {
+			break
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+)
+
+// stderrEntry gives the lines a program wrote to stderr without a level the
+// given level, like many runtimes do. Text lines become an entry with the
+// line as message.
+func stderrEntry(line *stream.Line, entry *structure.Entry, ok bool, level string) (*structure.Entry, bool) {
+	if line.Stream != "stderr" || level == "" {
+		return entry, ok
+	}
+	if !ok {
+		return &structure.Entry{Severity: level, Message: string(line.Raw)}, true
+	}
+	if entry.Severity == "" {
+		entry.Severity = level
+	}
+	return entry, ok
+}
+
+// tagStderr starts the lines a program wrote to stderr with a tag, to tell
+// them apart from the lines of stdout. The tag of an entry is added to its
+// prefix, the tag of a line that isn't parsed to the line itself.
+func tagStderr(line *stream.Line, parsed, colorize bool) {
+	if line.Stream != "stderr" {
+		return
+	}
+	tag := structure.ColorName("stderr", colorize) + " "
+	if !parsed {
+		line.Raw = append([]byte(tag), line.Raw...)
+	} else {
+		line.Prefix = append([]byte(tag), line.Prefix...)
+	}
+}
@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// errNotMappable is returned by MapFile for files that can't be mapped into
+// memory, like pipes and empty files.
+var errNotMappable = errors.New("file can't be mapped into memory")
+
+// bytesStream is a Stream of the lines in a slice of bytes, like a memory
+// mapped file. Lines are found with bytes.IndexByte, which is a lot faster
+// than scanning a reader and has no limit on the length of a line.
+type bytesStream struct {
+	data    []byte
+	source  string
+	release func() error
+	result  chan *Line
+	stop    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// NewBytes constructs a new Stream of the lines in data, of which every line
+// has the given source, and starts it. The release function is called when
+// all lines are read, to unmap the data.
+func NewBytes(data []byte, source string, release func() error) Stream {
+	b := &bytesStream{
+		data:    data,
+		source:  source,
+		release: release,
+		result:  make(chan *Line),
+		stop:    make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *bytesStream) run() {
+	defer close(b.result)
+	defer func() {
+		if b.release != nil {
+			b.err = b.release()
+		}
+	}()
+	number := 0
+	offset := 0
+	for offset < len(b.data) {
+		end := bytes.IndexByte(b.data[offset:], '\n')
+		next := offset + end + 1
+		if end == -1 {
+			end = len(b.data) - offset
+			next = len(b.data)
+		}
+		raw := b.data[offset : offset+end]
+		if len(raw) > 0 && raw[len(raw)-1] == '\r' {
+			raw = raw[:len(raw)-1]
+		}
+		number++
+		line := newLine(raw, b.source, number, int64(offset))
+		offset = next
+		if !send(b.result, b.stop, line) {
+			return
+		}
+	}
+}
+
+func (b *bytesStream) Close() {
+	b.once.Do(func() { close(b.stop) })
+}
+
+func (b *bytesStream) Lines() <-chan *Line {
+	return b.result
+}
+
+func (b *bytesStream) Err() error {
+	return b.err
+}
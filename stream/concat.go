@@ -0,0 +1,51 @@
+package stream
+
+import "sync"
+
+// concat is a Stream of the lines of other streams, one after the other.
+type concat struct {
+	streams []Stream
+	result  chan *Line
+	stop    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// Concat returns a Stream of the lines of the given streams, in order. It
+// stops at the first stream that fails.
+func Concat(streams ...Stream) Stream {
+	c := &concat{
+		streams: streams,
+		result:  make(chan *Line),
+		stop:    make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+func (c *concat) run() {
+	defer close(c.result)
+	for _, s := range c.streams {
+		for line := range s.Lines() {
+			if !send(c.result, c.stop, line) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			c.err = err
+			return
+		}
+	}
+}
+
+func (c *concat) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func (c *concat) Lines() <-chan *Line {
+	return c.result
+}
+
+func (c *concat) Err() error {
+	return c.err
+}
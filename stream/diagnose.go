@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// snippetLength is the number of bytes around the error position that are
+// included in a Diagnostic.
+const snippetLength = 24
+
+// Diagnostic describes why a line that looks like json couldn't be parsed,
+// so the producer of the line can be fixed.
+type Diagnostic struct {
+	Source string `json:"source,omitempty"`
+	Line   int    `json:"line"`
+	Offset int64  `json:"offset"`
+	// Column is the position of the first error in the line, starting at 1
+	Column  int    `json:"column"`
+	Error   string `json:"error"`
+	Snippet string `json:"snippet"`
+}
+
+// Diagnose returns a Diagnostic for a line that contains the start of a
+// json object but no valid one, ok is false for lines of valid json and for
+// text without json.
+func Diagnose(line *Line) (*Diagnostic, bool) {
+	if len(line.JSON) > 0 {
+		return nil, false
+	}
+	start := bytes.Index(line.Raw, []byte(`{"`))
+	if start == -1 {
+		return nil, false
+	}
+	var v interface{}
+	err := json.Unmarshal(line.Raw[start:], &v)
+	if err == nil {
+		return nil, false
+	}
+	position := len(line.Raw)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		position = start + int(syntaxErr.Offset)
+	}
+	if position < 1 {
+		position = 1
+	}
+	from := position - snippetLength/2
+	if from < 0 {
+		from = 0
+	}
+	to := from + snippetLength
+	if to > len(line.Raw) {
+		to = len(line.Raw)
+	}
+	return &Diagnostic{
+		Source:  line.Source,
+		Line:    line.Number,
+		Offset:  line.Offset,
+		Column:  position,
+		Error:   err.Error(),
+		Snippet: string(line.Raw[from:to]),
+	}, true
+}
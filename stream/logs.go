@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"sync"
 	"text/scanner"
 )
 
@@ -16,6 +17,23 @@ type Line struct {
 
 	Prefix []byte
 	Suffix []byte
+
+	// Source is the name of the input the line was read from, like a file
+	Source string
+
+	// Number is the line number in the source, starting at 1, and Offset the
+	// byte offset of the start of the line
+	Number int
+	Offset int64
+
+	// Stream is the stream a program wrote the line to, stdout or stderr, set
+	// by inputs that read both
+	Stream string
+}
+
+// Size returns the number of bytes the line holds on to.
+func (l *Line) Size() int64 {
+	return int64(len(l.Raw) + len(l.JSON) + len(l.Prefix) + len(l.Suffix))
 }
 
 // Stream lets you scan through the lines of a io.Reader and return each line
@@ -29,48 +47,90 @@ type Stream interface {
 
 type stream struct {
 	scanner *bufio.Scanner
+	source  string
+	read    int64
 	result  chan *Line
 	stop    chan struct{}
+	once    sync.Once
 }
 
 // New will construct a new Stream and start it.
 func New(r io.Reader) Stream {
+	return NewSource(r, "")
+}
+
+// NewSource constructs a new Stream of which every line has the given source
+// and starts it.
+func NewSource(r io.Reader, source string) Stream {
 	scanner := bufio.NewScanner(r)
 	l := &stream{
 		scanner: scanner,
+		source:  source,
 		result:  make(chan *Line),
 		stop:    make(chan struct{}),
 	}
+	scanner.Split(l.scanLines)
 	go l.run()
 	return l
 }
 
+// scanLines splits lines like bufio.ScanLines, counting the bytes read to
+// know the offset of every line.
+func (l *stream) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	l.read += int64(advance)
+	return advance, token, err
+}
+
 func (l *stream) run() {
+	defer close(l.result)
+	number := 0
+	var offset int64
 	for l.scanner.Scan() {
-		raw := l.scanner.Bytes()
-		json := l.parse(raw)
-		prefix, suffix := split(raw, json)
-		line := &Line{
-			Raw:    make([]byte, len(raw)),
-			Prefix: prefix,
-			Suffix: suffix,
-		}
-		copy(line.Raw, raw)
-		if json != nil {
-			line.JSON = make([]byte, len(json))
-			copy(line.JSON, json)
-		}
-		select {
-		case <-l.stop:
+		number++
+		line := newLine(l.scanner.Bytes(), l.source, number, offset)
+		offset = l.read
+		if !send(l.result, l.stop, line) {
 			return
-		case l.result <- line:
-			continue
 		}
 	}
-	close(l.result)
 }
 
-func (l *stream) parse(raw []byte) json.RawMessage {
+// send sends the line unless the stream is stopped, which takes precedence
+// over a waiting reader. It reports whether the line was sent.
+func send(result chan<- *Line, stop <-chan struct{}, line *Line) bool {
+	select {
+	case <-stop:
+		return false
+	default:
+	}
+	select {
+	case <-stop:
+		return false
+	case result <- line:
+		return true
+	}
+}
+
+// newLine copies the raw bytes into a Line, detecting the json in it.
+func newLine(raw []byte, source string, number int, offset int64) *Line {
+	line := &Line{
+		Raw:    make([]byte, len(raw)),
+		Source: source,
+		Number: number,
+		Offset: offset,
+	}
+	copy(line.Raw, raw)
+	line.JSON = parse(line.Raw)
+	line.Prefix, line.Suffix = split(line.Raw, line.JSON)
+	return line
+}
+
+func parse(raw []byte) json.RawMessage {
+	// most lines are a single object, which doesn't need to be tokenized:
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 1 && trimmed[0] == '{' && trimmed[len(trimmed)-1] == '}' && json.Valid(trimmed) {
+		return trimmed
+	}
 	var s scanner.Scanner
 	s.Init(bytes.NewReader(raw))
 	s.Error = func(s *scanner.Scanner, msg string) {}
@@ -94,18 +154,17 @@ func (l *stream) parse(raw []byte) json.RawMessage {
 	}
 	if start != -1 && end != -1 {
 		slice := raw[start:end]
-		var v interface{}
-		err := json.Unmarshal(slice, &v)
-		if err == nil {
+		if json.Valid(slice) {
 			return slice
 		}
 	}
 	return nil
 }
 
+// Close stops the stream without waiting for it, a read it's blocked in
+// still finishes. It's safe to call more than once.
 func (l *stream) Close() {
-	l.stop <- struct{}{}
-	close(l.result)
+	l.once.Do(func() { close(l.stop) })
 }
 
 func (l *stream) Lines() <-chan *Line {
@@ -3,7 +3,11 @@ package stream_test
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"testing/iotest"
@@ -21,13 +25,13 @@ fifth is {broken
 {"then": "we have"} trailing text
 json in {"the": "middle"} of the line`
 	expected := []*stream.Line{
-		{Raw: []byte("first line is normal"), JSON: nil, Prefix: nil, Suffix: nil},
-		{Raw: []byte(`{"second": "line is json"}`), JSON: json.RawMessage(`{"second": "line is json"}`), Prefix: nil, Suffix: nil},
-		{Raw: []byte(`{"third": "as well"}`), JSON: json.RawMessage(`{"third": "as well"}`), Prefix: nil, Suffix: nil},
-		{Raw: []byte(`forth line: {"is": "mixed"}`), JSON: json.RawMessage(`{"is": "mixed"}`), Prefix: []byte(`forth line: `), Suffix: nil},
-		{Raw: []byte(`fifth is {broken`), JSON: nil, Prefix: nil, Suffix: nil},
-		{Raw: []byte(`{"then": "we have"} trailing text`), JSON: json.RawMessage(`{"then": "we have"}`), Prefix: nil, Suffix: []byte(` trailing text`)},
-		{Raw: []byte(`json in {"the": "middle"} of the line`), JSON: json.RawMessage(`{"the": "middle"}`), Prefix: []byte(`json in `), Suffix: []byte(` of the line`)},
+		{Raw: []byte("first line is normal"), JSON: nil, Prefix: nil, Suffix: nil, Number: 1, Offset: 0},
+		{Raw: []byte(`{"second": "line is json"}`), JSON: json.RawMessage(`{"second": "line is json"}`), Prefix: nil, Suffix: nil, Number: 2, Offset: 21},
+		{Raw: []byte(`{"third": "as well"}`), JSON: json.RawMessage(`{"third": "as well"}`), Prefix: nil, Suffix: nil, Number: 3, Offset: 48},
+		{Raw: []byte(`forth line: {"is": "mixed"}`), JSON: json.RawMessage(`{"is": "mixed"}`), Prefix: []byte(`forth line: `), Suffix: nil, Number: 4, Offset: 69},
+		{Raw: []byte(`fifth is {broken`), JSON: nil, Prefix: nil, Suffix: nil, Number: 5, Offset: 97},
+		{Raw: []byte(`{"then": "we have"} trailing text`), JSON: json.RawMessage(`{"then": "we have"}`), Prefix: nil, Suffix: []byte(` trailing text`), Number: 6, Offset: 114},
+		{Raw: []byte(`json in {"the": "middle"} of the line`), JSON: json.RawMessage(`{"the": "middle"}`), Prefix: []byte(`json in `), Suffix: []byte(` of the line`), Number: 7, Offset: 148},
 	}
 	s := stream.New(strings.NewReader(in))
 	for i, line := range expected {
@@ -52,15 +56,17 @@ func test(t *testing.T, input string, expected *stream.Line) {
 
 func TestFullJSON(t *testing.T) {
 	test(t, `{"msg": "Hello", "key": "value"}`, &stream.Line{
-		Raw:  []byte(`{"msg": "Hello", "key": "value"}`),
-		JSON: json.RawMessage(`{"msg": "Hello", "key": "value"}`),
+		Raw:    []byte(`{"msg": "Hello", "key": "value"}`),
+		JSON:   json.RawMessage(`{"msg": "Hello", "key": "value"}`),
+		Number: 1,
 	})
 }
 
 func TestPlainText(t *testing.T) {
 	test(t, `Hello, world!!`, &stream.Line{
-		Raw:  []byte(`Hello, world!!`),
-		JSON: nil,
+		Raw:    []byte(`Hello, world!!`),
+		JSON:   nil,
+		Number: 1,
 	})
 }
 
@@ -69,6 +75,7 @@ func TestTrailingText(t *testing.T) {
 		Raw:    []byte(`{"json": 1} Hello, world!!`),
 		JSON:   json.RawMessage(`{"json": 1}`),
 		Suffix: []byte(` Hello, world!!`),
+		Number: 1,
 	})
 }
 
@@ -77,12 +84,14 @@ func TestLeadingText(t *testing.T) {
 		Raw:    []byte(`Sup? {"json": 2}`),
 		JSON:   json.RawMessage(`{"json": 2}`),
 		Prefix: []byte(`Sup? `),
+		Number: 1,
 	})
 }
 
 func TestBacktick(t *testing.T) {
 	test(t, "Single backtick: `", &stream.Line{
-		Raw: []byte("Single backtick: `"),
+		Raw:    []byte("Single backtick: `"),
+		Number: 1,
 	})
 }
 
@@ -90,6 +99,7 @@ func TestClose(t *testing.T) {
 	t.Parallel()
 	s := stream.New(iotest.TimeoutReader(strings.NewReader("one\ntwo\n")))
 	s.Close()
+	s.Close()
 	line := <-s.Lines()
 	if line != nil {
 		t.Error("expected nil on a closed stream")
@@ -105,3 +115,140 @@ func TestErrors(t *testing.T) {
 		t.Errorf("expecting ErrTimeout, got %v", err)
 	}
 }
+
+func TestConcat(t *testing.T) {
+	t.Parallel()
+	s := stream.Concat(
+		stream.NewSource(strings.NewReader("first\nsecond"), "a.log"),
+		stream.NewSource(strings.NewReader(`{"third": true}`), "b.log"),
+	)
+	var got []string
+	for line := range s.Lines() {
+		got = append(got, line.Source+": "+string(line.Raw))
+	}
+	expected := []string{"a.log: first", "a.log: second", `b.log: {"third": true}`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("lines didnt match, got %q expected %q", got, expected)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	t.Parallel()
+	s := stream.NewSource(strings.NewReader("plain text\n{\"msg\": \"ok\"}\n{\"msg\": \"truncated\", \"level\": \"in\nlog: {\"msg\": 'quoted', \"level\": \"info\"}\n"), "app.log")
+	var got []stream.Diagnostic
+	for line := range s.Lines() {
+		if diagnostic, ok := stream.Diagnose(line); ok {
+			got = append(got, *diagnostic)
+		}
+	}
+	expected := []stream.Diagnostic{
+		{Source: "app.log", Line: 3, Offset: 25, Column: 33, Error: "unexpected end of JSON input", Snippet: `"level": "in`},
+		{Source: "app.log", Line: 4, Offset: 59, Column: 14, Error: "invalid character '\\'' looking for beginning of value", Snippet: `g: {"msg": 'quoted', "le`},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("diagnostics didnt match, got %+v expected %+v", got, expected)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	t.Parallel()
+	in := "plain\r\n{\"json\": true}\n\nprefix {\"json\": false} suffix\nlast"
+	var expected, got []stream.Line
+	for line := range stream.NewSource(strings.NewReader(in), "app.log").Lines() {
+		expected = append(expected, *line)
+	}
+	released := false
+	s := stream.NewBytes([]byte(in), "app.log", func() error {
+		released = true
+		return nil
+	})
+	for line := range s.Lines() {
+		got = append(got, *line)
+	}
+	if len(got) != 5 || !reflect.DeepEqual(got, expected) {
+		t.Errorf("lines didnt match, got %q expected %q", got, expected)
+	}
+	if !released {
+		t.Error("expected the data to be released")
+	}
+}
+
+func TestSections(t *testing.T) {
+	t.Parallel()
+	in := "first\nsecond\n{\"third\": 3}\nfourth\nfifth\n"
+	var all []stream.Line
+	for line := range stream.NewSource(strings.NewReader(in), "app.log").Lines() {
+		all = append(all, *line)
+	}
+	sections := []stream.Section{{Offset: 6, End: 26, Line: 2}, {Offset: 33, End: int64(len(in)), Line: 5}}
+	var got []stream.Line
+	s := stream.NewSections(strings.NewReader(in), "app.log", sections)
+	for line := range s.Lines() {
+		got = append(got, *line)
+	}
+	expected := []stream.Line{all[1], all[2], all[4]}
+	if err := s.Err(); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("lines didnt match, got %+v expected %+v (%v)", got, expected, err)
+	}
+}
+
+func TestBytesLongLine(t *testing.T) {
+	t.Parallel()
+	long := `{"msg": "` + strings.Repeat("x", 100000) + `"}`
+	line := <-stream.NewBytes([]byte(long+"\n"), "", nil).Lines()
+	if string(line.JSON) != long {
+		t.Errorf("expected the long line to be parsed as json, got %d bytes", len(line.JSON))
+	}
+}
+
+func TestMapFile(t *testing.T) {
+	t.Parallel()
+	file := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(file, []byte("one\n{\"two\": 2}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, unmap, err := stream.MapFile(f)
+	if runtime.GOOS == "windows" {
+		if err == nil {
+			t.Error("expected an error on windows")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "one\n{\"two\": 2}\n" {
+		t.Errorf("data didnt match, got %q", data)
+	}
+	if err := unmap(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMergeOutput(t *testing.T) {
+	t.Parallel()
+	s := stream.MergeOutput(
+		stream.NewSource(strings.NewReader("started\n"), "app"),
+		stream.NewSource(strings.NewReader("deprecated flag\n"), "app"),
+	)
+	var got []string
+	for line := range s.Lines() {
+		got = append(got, line.Stream+": "+string(line.Raw))
+	}
+	sort.Strings(got)
+	expected := []string{"stderr: deprecated flag", "stdout: started"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("lines didnt match, got %q expected %q", got, expected)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
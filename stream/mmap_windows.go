@@ -0,0 +1,8 @@
+package stream
+
+import "os"
+
+// MapFile isn't supported on windows, files are read as any other reader.
+func MapFile(f *os.File) ([]byte, func() error, error) {
+	return nil, nil, errNotMappable
+}
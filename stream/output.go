@@ -0,0 +1,61 @@
+package stream
+
+import "sync"
+
+// output is a Stream of the lines of the stdout and stderr of a program, in
+// the order they arrive.
+type output struct {
+	result chan *Line
+	stop   chan struct{}
+	once   sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// MergeOutput returns a Stream of the lines of the stdout and stderr streams
+// of a program, as they arrive, with the Stream of every line set.
+func MergeOutput(stdout, stderr Stream) Stream {
+	o := &output{
+		result: make(chan *Line),
+		stop:   make(chan struct{}),
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go o.run(&wg, stdout, "stdout")
+	go o.run(&wg, stderr, "stderr")
+	go func() {
+		wg.Wait()
+		close(o.result)
+	}()
+	return o
+}
+
+func (o *output) run(wg *sync.WaitGroup, s Stream, name string) {
+	defer wg.Done()
+	for line := range s.Lines() {
+		line.Stream = name
+		if !send(o.result, o.stop, line) {
+			return
+		}
+	}
+	if err := s.Err(); err != nil {
+		o.mu.Lock()
+		o.err = err
+		o.mu.Unlock()
+	}
+}
+
+func (o *output) Close() {
+	o.once.Do(func() { close(o.stop) })
+}
+
+func (o *output) Lines() <-chan *Line {
+	return o.result
+}
+
+func (o *output) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
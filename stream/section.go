@@ -0,0 +1,78 @@
+package stream
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// Section is a part of a file, from the byte offset of its first line, which
+// has the line number, up to End.
+type Section struct {
+	Offset, End int64
+	Line        int
+}
+
+// sectionStream is a Stream of the lines in sections of a file, like the
+// parts of an indexed file that match a query. The lines keep the offsets
+// and line numbers they have in the file.
+type sectionStream struct {
+	r        io.ReaderAt
+	source   string
+	sections []Section
+	result   chan *Line
+	stop     chan struct{}
+	once     sync.Once
+	err      error
+}
+
+// NewSections constructs a new Stream of the lines in the sections of r, of
+// which every line has the given source, and starts it.
+func NewSections(r io.ReaderAt, source string, sections []Section) Stream {
+	s := &sectionStream{
+		r:        r,
+		source:   source,
+		sections: sections,
+		result:   make(chan *Line),
+		stop:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *sectionStream) run() {
+	defer close(s.result)
+	for _, section := range s.sections {
+		scanner := bufio.NewScanner(io.NewSectionReader(s.r, section.Offset, section.End-section.Offset))
+		read := section.Offset
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			advance, token, err := bufio.ScanLines(data, atEOF)
+			read += int64(advance)
+			return advance, token, err
+		})
+		number, offset := section.Line, section.Offset
+		for scanner.Scan() {
+			line := newLine(scanner.Bytes(), s.source, number, offset)
+			number, offset = number+1, read
+			if !send(s.result, s.stop, line) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			s.err = err
+			return
+		}
+	}
+}
+
+func (s *sectionStream) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *sectionStream) Lines() <-chan *Line {
+	return s.result
+}
+
+func (s *sectionStream) Err() error {
+	return s.err
+}
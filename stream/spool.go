@@ -0,0 +1,181 @@
+package stream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Budget is an amount of memory shared by the features that hold on to
+// lines, so together they stay within it.
+type Budget struct {
+	max  int64
+	used atomic.Int64
+}
+
+// NewBudget returns a Budget of max bytes, zero or less means no limit.
+func NewBudget(max int64) *Budget {
+	return &Budget{max: max}
+}
+
+// Take reserves n bytes of the budget and reports whether they fit. A nil
+// Budget has no limit.
+func (b *Budget) Take(n int64) bool {
+	if b == nil || b.max <= 0 {
+		return true
+	}
+	for {
+		used := b.used.Load()
+		if used+n > b.max {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+n) {
+			return true
+		}
+	}
+}
+
+// Release returns n bytes that were taken.
+func (b *Budget) Release(n int64) {
+	if b == nil || b.max <= 0 {
+		return
+	}
+	b.used.Add(-n)
+}
+
+// Spool buffers lines in memory up to a budget of bytes and spills the lines
+// that don't fit to a temporary file, so features that have to hold on to
+// lines never exhaust the memory on pathological input.
+type Spool struct {
+	budget *Budget
+	used   int64
+	lines  [][]byte
+
+	file    *os.File
+	writer  *bufio.Writer
+	spilled int
+}
+
+// NewSpool returns a Spool keeping the lines in memory as long as they fit
+// in the budget, a nil budget means no limit.
+func NewSpool(budget *Budget) *Spool {
+	return &Spool{budget: budget}
+}
+
+// Add appends a copy of the line to the spool.
+func (s *Spool) Add(line []byte) error {
+	if s.file == nil && s.budget.Take(int64(len(line))) {
+		s.lines = append(s.lines, append([]byte(nil), line...))
+		s.used += int64(len(line))
+		return nil
+	}
+	if s.file == nil {
+		f, err := os.CreateTemp("", "jl-spool-")
+		if err != nil {
+			return err
+		}
+		s.file, s.writer = f, bufio.NewWriter(f)
+	}
+	s.spilled++
+	_, err := fmt.Fprintf(s.writer, "%d\n%s", len(line), line)
+	return err
+}
+
+// Len returns the number of lines in the spool.
+func (s *Spool) Len() int {
+	return len(s.lines) + s.spilled
+}
+
+// Spilled returns the number of lines that were written to disk.
+func (s *Spool) Spilled() int {
+	return s.spilled
+}
+
+// Each calls fn for every line in the order they were added, stopping at the
+// first error.
+func (s *Spool) Each(fn func(line []byte) error) (err error) {
+	for _, line := range s.lines {
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if s.file == nil {
+		return nil
+	}
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	defer func() {
+		// continue spilling at the end of the file:
+		_, seekErr := s.file.Seek(0, io.SeekEnd)
+		err = errors.Join(err, seekErr)
+	}()
+	r := bufio.NewReader(s.file)
+	for i := 0; i < s.spilled; i++ {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		size, err := strconv.Atoi(strings.TrimSuffix(header, "\n"))
+		if err != nil {
+			return err
+		}
+		line := make([]byte, size)
+		if _, err := io.ReadFull(r, line); err != nil {
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the lines and removes the temporary file.
+func (s *Spool) Close() error {
+	s.budget.Release(s.used)
+	s.lines, s.used, s.spilled = nil, 0, 0
+	if s.file == nil {
+		return nil
+	}
+	f := s.file
+	s.file, s.writer = nil, nil
+	return errors.Join(f.Close(), os.Remove(f.Name()))
+}
+
+// sizeUnits are the units of ParseSize, decimal and binary.
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseSize parses a number of bytes with an optional unit, like 256MB or
+// 1GiB.
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a size, like 256MB", size)
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,91 @@
+package stream_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/koenbollen/jl/stream"
+)
+
+func TestSpool(t *testing.T) {
+	t.Parallel()
+	spool := stream.NewSpool(stream.NewBudget(10))
+	defer spool.Close()
+	expected := []string{"first", "second", "", "third line", "fourth"}
+	for _, line := range expected[:3] {
+		if err := spool.Add([]byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	var got []string
+	collect := func(line []byte) error {
+		got = append(got, string(line))
+		return nil
+	}
+	if err := spool.Each(collect); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range expected[3:] {
+		if err := spool.Add([]byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	got = nil
+	if err := spool.Each(collect); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("lines didnt match, got %q expected %q", got, expected)
+	}
+	if spool.Len() != 5 || spool.Spilled() != 4 {
+		t.Errorf("expected 5 lines of which 4 spilled, got %d and %d", spool.Len(), spool.Spilled())
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+	tests := map[string]int64{
+		"256MB":   256e6,
+		"1GiB":    1 << 30,
+		"512k":    512 << 10,
+		"1.5 GB":  15e8,
+		"4096":    4096,
+		"100b":    100,
+		"256 MiB": 256 << 20,
+	}
+	for input, expected := range tests {
+		if got, err := stream.ParseSize(input); err != nil || got != expected {
+			t.Errorf("ParseSize(%q) = %d, %v, expected %d", input, got, err, expected)
+		}
+	}
+	for _, input := range []string{"", "MB", "-1MB", "lots"} {
+		if _, err := stream.ParseSize(input); err == nil {
+			t.Errorf("ParseSize(%q) = nil, want error", input)
+		}
+	}
+}
+
+func TestSpoolSharedBudget(t *testing.T) {
+	t.Parallel()
+	budget := stream.NewBudget(10)
+	if !budget.Take(6) {
+		t.Fatalf("expected 6 bytes to fit")
+	}
+	spool := stream.NewSpool(budget)
+	defer spool.Close()
+	for _, line := range []string{"abc", "def"} {
+		if err := spool.Add([]byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if spool.Spilled() != 1 {
+		t.Errorf("expected 1 spilled line, got %d", spool.Spilled())
+	}
+	budget.Release(6)
+	if err := spool.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !budget.Take(10) {
+		t.Errorf("expected the budget to be released")
+	}
+}
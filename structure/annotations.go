@@ -0,0 +1,17 @@
+package structure
+
+import "strings"
+
+// outputAnnotations renders the annotations of the entry as marked lines
+// below it.
+func (f *Formatter) outputAnnotations(entry *Entry) error {
+	if len(entry.Annotations) == 0 {
+		return nil
+	}
+	lines := make([]string, len(entry.Annotations))
+	for i, annotation := range entry.Annotations {
+		lines[i] = "    " + annotationColor("! ") + annotation
+	}
+	_, err := f.output.Write([]byte("\n" + strings.Join(lines, "\n")))
+	return err
+}
@@ -0,0 +1,17 @@
+package structure
+
+import "regexp"
+
+// ansiCode matches the color codes of a terminal.
+var ansiCode = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// escapeSequence matches any escape sequence of a terminal: CSI sequences
+// like colors and cursor movements, OSC sequences like window titles and
+// links, and the short two byte sequences.
+var escapeSequence = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(\x07|\x1b\\\\)|\x1b[@-Z\\\\-_]")
+
+// StripANSI removes the escape sequences from the text, so they can't mess
+// with the colors of the output, or the terminal itself.
+func StripANSI(text string) string {
+	return escapeSequence.ReplaceAllString(text, "")
+}
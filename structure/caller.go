@@ -0,0 +1,84 @@
+package structure
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Caller path rules, used by the Formatter to shorten the file paths of
+// caller-like fields.
+const (
+	CallerAuto  = "auto"
+	CallerShort = "short"
+	CallerFull  = "full"
+)
+
+var callerFields = []string{"caller", "source", "file", "logger.caller"}
+
+// callerPattern matches a file:line location, like pkg/main.go:42.
+var callerPattern = regexp.MustCompile(`^\S+\.\w+:\d+$`)
+
+// isCaller reports whether the field is a caller of which the path can be
+// shortened. The source and file fields are only a caller when they hold a
+// file:line location, a file field may as well be a path of the application.
+func isCaller(key, value string) bool {
+	switch key {
+	case "caller", "logger.caller":
+		return true
+	}
+	return contains(callerFields, key) && callerPattern.MatchString(value)
+}
+
+// compactSource replaces the source object of slog's AddSource, with the
+// function, file and line, by a file:line caller.
+func compactSource(fields map[string]interface{}) {
+	source, ok := fields["source"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	file, _ := source["file"].(string)
+	line, ok := source["line"].(float64)
+	if file != "" && ok {
+		fields["source"] = fmt.Sprintf("%s:%d", file, int(line))
+	}
+}
+
+// ShortenCaller shortens a caller path, e.g. "/go/pkg/mod/x/y@v1/z/file.go:42",
+// according to the given rule. The auto rule makes the path relative to its
+// module root (or the working directory) and falls back to keeping the last
+// two segments, the short rule always keeps the last two segments.
+func ShortenCaller(caller, rule, workdir string) string {
+	switch rule {
+	case CallerFull, "":
+		return caller
+	case CallerAuto:
+		if rel, ok := moduleRelative(caller, workdir); ok {
+			return rel
+		}
+	}
+	return lastSegments(caller, 2)
+}
+
+func moduleRelative(caller, workdir string) (string, bool) {
+	if at := strings.LastIndex(caller, "@"); at != -1 {
+		if slash := strings.Index(caller[at:], "/"); slash != -1 {
+			return caller[at+slash+1:], true
+		}
+	}
+	if workdir != "" && filepath.IsAbs(caller) {
+		if rel, err := filepath.Rel(workdir, caller); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+func lastSegments(caller string, n int) string {
+	parts := strings.Split(caller, "/")
+	if len(parts) <= n {
+		return caller
+	}
+	return strings.Join(parts[len(parts)-n:], "/")
+}
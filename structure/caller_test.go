@@ -0,0 +1,47 @@
+package structure
+
+import "testing"
+
+func TestShortenCaller(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		caller, rule, workdir, want string
+	}{
+		{"/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/qux.go:42", CallerAuto, "", "baz/qux.go:42"},
+		{"/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/qux.go:42", CallerShort, "", "baz/qux.go:42"},
+		{"/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/qux.go:42", CallerAuto, "", "qux.go:42"},
+		{"/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/qux.go:42", CallerFull, "", "/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/qux.go:42"},
+		{"/src/project/internal/server/http.go:12", CallerAuto, "/src/project", "internal/server/http.go:12"},
+		{"/usr/local/go/src/net/http/server.go:3210", CallerAuto, "/src/project", "http/server.go:3210"},
+		{"kafka/consumer.go:63", CallerAuto, "/src/project", "kafka/consumer.go:63"},
+		{"main.go:10", CallerShort, "", "main.go:10"},
+	}
+	for _, tt := range tests {
+		if got := ShortenCaller(tt.caller, tt.rule, tt.workdir); got != tt.want {
+			t.Errorf("ShortenCaller(%q, %q) = %q, want %q", tt.caller, tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestIsCaller(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key, value string
+		want       bool
+	}{
+		{"caller", "server/http.go:12", true},
+		{"caller", "main", true},
+		{"source", "/src/project/main.go:42", true},
+		{"file", "pkg/a/b.go:7", true},
+		{"file", "/var/lib/app/data/x.db", false},
+		{"source", "kafka", false},
+		{"path", "main.go:10", false},
+	}
+	for _, tt := range tests {
+		if got := isCaller(tt.key, tt.value); got != tt.want {
+			t.Errorf("isCaller(%q, %q) = %v, want %v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
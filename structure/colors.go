@@ -3,6 +3,7 @@ package structure
 import "github.com/fatih/color"
 
 var messageColor = color.New(color.FgHiCyan, color.Bold).SprintFunc()
+var substitutionColor = color.New(color.FgHiMagenta, color.Bold).SprintFunc()
 var severityColors = map[string]func(a ...interface{}) string{
 	"TRACE":   color.New(color.FgHiBlack).SprintFunc(),
 	"DEBUG":   color.New(color.FgHiBlack).SprintFunc(),
@@ -11,3 +12,11 @@ var severityColors = map[string]func(a ...interface{}) string{
 	"ERROR":   color.New(color.FgHiRed, color.Bold).SprintFunc(),
 	"FATAL":   color.New(color.FgHiRed, color.Bold).SprintFunc(),
 }
+
+var separatorColor = color.New(color.FgHiBlack).SprintFunc()
+var ownFrameColor = color.New(color.FgHiWhite, color.Bold).SprintFunc()
+var dependencyFrameColor = color.New(color.FgHiBlack).SprintFunc()
+var causeColor = color.New(color.FgRed).SprintFunc()
+var hiddenColor = color.New(color.FgHiBlack).SprintFunc()
+var annotationColor = color.New(color.FgYellow).SprintFunc()
+var threadColor = color.New(color.FgHiBlack).SprintFunc()
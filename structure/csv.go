@@ -0,0 +1,117 @@
+package structure
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// DefaultColumns are used by the csv and tsv output when no columns are
+// given.
+var DefaultColumns = []string{"time", "level", "msg"}
+
+// csvPrinter writes the columns of the entries as csv or tsv records.
+type csvPrinter struct {
+	f *Formatter
+}
+
+func (p csvPrinter) Print(w io.Writer, record *Record) error {
+	if record.Plain {
+		return p.f.formatCSVRaw([]byte(record.Message))
+	}
+	return p.f.formatCSV(record.Entry, record.Raw)
+}
+
+// formatCSV outputs the columns of the entry as a csv (or tsv) record, the
+// header with the column names is written before the first record.
+func (f *Formatter) formatCSV(entry *Entry, raw json.RawMessage) error {
+	columns := f.columns()
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = f.columnValue(column, entry, raw)
+	}
+	return f.writeRecord(record)
+}
+
+// formatCSVRaw outputs a line that couldn't be parsed with the line as
+// message.
+func (f *Formatter) formatCSVRaw(line []byte) error {
+	columns := f.columns()
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		if column == "msg" || column == "message" {
+			record[i] = string(line)
+		}
+	}
+	return f.writeRecord(record)
+}
+
+func (f *Formatter) columns() []string {
+	if len(f.Columns) == 0 {
+		return DefaultColumns
+	}
+	return f.Columns
+}
+
+func (f *Formatter) writeRecord(record []string) error {
+	if f.csv == nil {
+		f.csv = csv.NewWriter(f.output)
+		if f.Output == OutputTSV {
+			f.csv.Comma = '\t'
+		}
+		if err := f.csv.Write(f.columns()); err != nil {
+			return err
+		}
+	}
+	if err := f.csv.Write(record); err != nil {
+		return err
+	}
+	f.csv.Flush()
+	return f.csv.Error()
+}
+
+// columnValue returns the value of a column to print, redacted like the
+// fields of the other outputs.
+func (f *Formatter) columnValue(column string, entry *Entry, raw json.RawMessage) string {
+	value, _ := ColumnValue(column, entry, raw)
+	switch column {
+	case "time", "timestamp", "level", "severity", "msg", "message", "logger", "thread":
+		return value
+	}
+	return fmt.Sprint(f.redact(value))
+}
+
+// ColumnValue returns the value of a column, time, level, msg, logger and
+// thread refer to the timestamp, severity, message, logger and thread of the
+// entry, all other columns are looked up as (nested) json keys. It returns
+// false if there is no value.
+func ColumnValue(column string, entry *Entry, raw json.RawMessage) (string, bool) {
+	switch column {
+	case "time", "timestamp":
+		if entry.Timestamp != nil {
+			return entry.Timestamp.Format(time.RFC3339Nano), true
+		}
+		return entry.RawTimestamp, entry.RawTimestamp != ""
+	case "level", "severity":
+		severity := NormalizeSeverity(entry.Severity)
+		return severity, severity != ""
+	case "msg", "message":
+		return entry.Message, true
+	case "logger":
+		if entry.Logger != "" {
+			return entry.Logger, true
+		}
+	case "thread":
+		thread, _ := Thread(raw)
+		return thread, thread != ""
+	}
+	value := lookup(raw, column)
+	if value.Type == gjson.String {
+		return value.String(), true
+	}
+	return value.Raw, value.Exists() && value.Type != gjson.Null
+}
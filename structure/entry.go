@@ -1,6 +1,10 @@
 package structure
 
-import "time"
+import (
+	"reflect"
+	"strings"
+	"time"
+)
 
 // Entry represents a structured logline to be formatted.
 type Entry struct {
@@ -12,9 +16,63 @@ type Entry struct {
 
 	Name string `djson:"app,name,service.name"`
 
+	// Logger is the component that wrote the entry, set by processors of
+	// formats that have one
+	Logger string
+
+	// Boot is the id of the boot of the system the entry was logged in, a
+	// separator is written when it changes
+	Boot string
+
+	// Thread is the thread or goroutine that logged the entry, set when it's
+	// shown as a column
+	Thread string
+
+	// Annotations are notes about the entry shown below it, like violations
+	// of a logging contract
+	Annotations []string
+
+	// Substitutions are the byte ranges of the message of the values that
+	// were substituted into its message template, to highlight them
+	Substitutions [][2]int
+
 	// IncludeFields is used by processors to indicate which fields should be included
 	IncludeFields []string
 
 	// ExcludeFields is used by processors to indicate which fields should be skipped
 	ExcludeFields []string
+
+	// FlattenFields is used by processors to indicate which objects should be
+	// shown as top-level fields (like labels), these may be encoded as a json
+	// string
+	FlattenFields []string
+}
+
+// entryKeys contains all json keys that can be loaded into an Entry.
+var entryKeys = func() []string {
+	var keys []string
+	elem := reflect.TypeOf(Entry{})
+	for i := 0; i < elem.NumField(); i++ {
+		if tag, ok := elem.Field(i).Tag.Lookup("djson"); ok {
+			keys = append(keys, strings.Split(tag, ",")...)
+		}
+	}
+	return keys
+}()
+
+// entryTags returns the json keys loaded into the named fields of an Entry.
+func entryTags(names ...string) []string {
+	var keys []string
+	elem := reflect.TypeOf(Entry{})
+	for _, name := range names {
+		field, _ := elem.FieldByName(name)
+		keys = append(keys, strings.Split(field.Tag.Get("djson"), ",")...)
+	}
+	return keys
+}
+
+// IsEntryKey reports whether the json key is loaded into one of the fields of
+// an Entry.
+func IsEntryKey(key string) bool {
+	return contains(entryKeys, key)
 }
@@ -0,0 +1,80 @@
+package structure
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// minChainLength is the number of ": " separated parts an error message
+// needs to be rendered as a chain, shorter messages are most likely just a
+// prefixed message instead of wrapped errors.
+const minChainLength = 3
+
+// outputErrorChain renders wrapped errors as an indented tree of causes
+// below the entry. Both Go style wrapped messages ("a: b: c") and
+// structured causes (error.cause and errors[]) are supported.
+func (f *Formatter) outputErrorChain(raw []byte) error {
+	var lines []string
+	if cause := lookup(raw, "error.cause"); cause.Exists() {
+		lines = causeTree(lookup(raw, "error"), 0)
+	} else if errors := gjson.GetBytes(raw, "errors"); errors.IsArray() {
+		for _, err := range errors.Array() {
+			lines = append(lines, causeTree(err, 0)...)
+		}
+	} else {
+		for _, field := range f.ErrorFields {
+			value := lookup(raw, field)
+			if value.Type != gjson.String {
+				continue
+			}
+			parts := strings.Split(value.String(), ": ")
+			if len(parts) < minChainLength {
+				break
+			}
+			for depth, part := range parts {
+				lines = append(lines, chainLine(part, depth))
+			}
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := f.output.Write([]byte("\n" + strings.Join(lines, "\n")))
+	return err
+}
+
+// causeTree renders a structured error, either a string or an object with
+// a message and an optional cause or list of errors.
+func causeTree(err gjson.Result, depth int) []string {
+	if err.Type == gjson.String {
+		return []string{chainLine(err.String(), depth)}
+	}
+	if !err.IsObject() {
+		return nil
+	}
+	message := ""
+	for _, key := range []string{"message", "msg", "error"} {
+		if value := err.Get(key); value.Type == gjson.String {
+			message = value.String()
+			break
+		}
+	}
+	var lines []string
+	if message != "" {
+		lines = append(lines, chainLine(message, depth))
+		depth++
+	}
+	if cause := err.Get("cause"); cause.Exists() {
+		lines = append(lines, causeTree(cause, depth)...)
+	}
+	for _, nested := range err.Get("errors").Array() {
+		lines = append(lines, causeTree(nested, depth)...)
+	}
+	return lines
+}
+
+func chainLine(message string, depth int) string {
+	return "    " + strings.Repeat("  ", depth) + causeColor("↳ ") + message
+}
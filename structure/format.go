@@ -1,6 +1,7 @@
 package structure
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,7 +15,7 @@ import (
 )
 
 // DefaultTemplate is used when no template is given.
-const DefaultTemplate = `{{if .Timestamp}}[{{.Timestamp.Format "2006-01-02 15:04:05"}}] {{else if .RawTimestamp}}[{{.RawTimestamp}}] {{end}}{{if .Severity}}{{.Severity}}: {{end}}{{.Message}}`
+const DefaultTemplate = `{{if .Timestamp}}[{{.Timestamp.Format "2006-01-02 15:04:05"}}] {{else if .RawTimestamp}}[{{.RawTimestamp}}] {{end}}{{if .Severity}}{{.Severity}}: {{end}}{{if .Thread}}{{.Thread}} {{end}}{{if .Logger}}{{.Logger}} {{end}}{{.Message}}`
 
 var severityMapping = map[string]string{
 	"10":   "TRACE",
@@ -24,12 +25,52 @@ var severityMapping = map[string]string{
 	"WARN": "WARNING",
 	"50":   "ERROR",
 	"60":   "FATAL",
+
+	// the levels of Serilog and .NET:
+	"VERBOSE":     "TRACE",
+	"INFORMATION": "INFO",
 }
 
 var defaultExcludes = []string{
 	"@timestamp", "hostname", "level", "message", "msg", "name", "pid", "severity", "text", "time", "timestamp", "ts", "v",
 }
 
+// infrastructureFields are excluded by default, like the other
+// defaultExcludes, but shown when the verbosity is at least VerboseAll.
+var infrastructureFields = []string{"hostname", "pid", "v"}
+
+// Array styles, used by the Formatter to render json arrays in the fields.
+const (
+	ArrayInline = "inline"
+	ArrayIndex  = "index"
+	ArrayCount  = "count"
+)
+
+// Verbosity levels of the Formatter, the default level hides nested, long
+// and infrastructure fields.
+const (
+	VerboseDefault = iota
+	// VerboseExpand reveals long fields and expands summarized values.
+	VerboseExpand
+	// VerboseAll shows every field, including nested and infrastructure
+	// fields.
+	VerboseAll
+)
+
+// Output modes of the Formatter. The html mode uses the text output, written
+// through an HTMLWriter.
+const (
+	OutputText     = "text"
+	OutputMarkdown = "markdown"
+	OutputHTML     = "html"
+	// OutputGitHub outputs errors and warnings as GitHub Actions annotations.
+	OutputGitHub = "gha"
+	OutputCSV    = "csv"
+	OutputTSV    = "tsv"
+	// OutputTable outputs fixed-width aligned columns.
+	OutputTable = "table"
+)
+
 // NewLine contains ['\n']
 var NewLine = []byte("\n")
 
@@ -39,13 +80,52 @@ type Formatter struct {
 	output   io.Writer
 	template *template.Template
 
-	Colorize       bool
-	ShowFields     bool
-	MaxFieldLength int
-	ShowPrefix     bool
-	ShowSuffix     bool
-	IncludeFields  []string
-	ExcludeFields  []string
+	Colorize        bool
+	ShowFields      bool
+	MaxFieldLength  int
+	ShowPrefix      bool
+	ShowSuffix      bool
+	DaySeparator    bool
+	Width           int
+	CallerRule      string
+	WorkDir         string
+	Modules         []string
+	SourceContext   int
+	ErrorFields     []string
+	StackFields     []string
+	ArrayStyle      string
+	MaxDepth        int
+	Verbosity       int
+	ShowHiddenCount bool
+	Quote           string
+	JWT             string
+	UserAgentFields []string
+	Output          string
+	Columns         []string
+	IncludeFields   []string
+	ExcludeFields   []string
+	LoggerFields    []string
+	ShowThread      bool
+	Highlight       string
+	InferLevels     bool
+	KeepANSI        bool
+	HighlightValues bool
+
+	// Printer writes the entries instead of the printer of the output mode,
+	// for the json, logfmt, compact and expanded outputs
+	Printer Printer
+
+	// Hidden counts the entries and fields left out of the output
+	Hidden Hidden
+
+	lastDay     string
+	lastBoot    string
+	wroteHeader bool
+	loggerWidth int
+	threadWidth int
+	csv         *csv.Writer
+	sources     map[string][]string
+	group       *group
 }
 
 // NewFormatter compiles the given fmt as a go template and returns a Formatter
@@ -53,7 +133,7 @@ func NewFormatter(w io.Writer, fmt string) (*Formatter, error) {
 	if fmt == "" {
 		fmt = DefaultTemplate
 	}
-	tmpl, err := template.New("out").Parse(fmt)
+	tmpl, err := template.New("out").Funcs(templateFuncs).Parse(fmt)
 	if err != nil {
 		return nil, err
 	}
@@ -66,56 +146,179 @@ func NewFormatter(w io.Writer, fmt string) (*Formatter, error) {
 		MaxFieldLength: 30,
 		ShowPrefix:     true,
 		ShowSuffix:     true,
+		Width:          80,
+		CallerRule:     CallerAuto,
+		ErrorFields:    DefaultErrorFields,
+		StackFields:    DefaultStackFields,
+		ArrayStyle:     ArrayInline,
+		MaxDepth:       3,
+		Quote:          QuoteNever,
+		JWT:            JWTRedact,
+		Output:         OutputText,
+		LoggerFields:   DefaultLoggerFields,
 		ExcludeFields:  defaultExcludes,
+		InferLevels:    true,
 	}, nil
 }
 
-// Format takes a structured log entry and formats it according the template.
+// Clone returns a Formatter with the same options that writes to w. It has
+// to be called before the formatter is used, the state of the output, like
+// the last day or the counts of hidden fields, isn't shared.
+func (f *Formatter) Clone(w io.Writer) *Formatter {
+	clone := *f
+	clone.output = w
+	return &clone
+}
+
+// Format takes a structured log entry and writes it with the printer of the
+// output mode.
 func (f *Formatter) Format(entry *Entry, raw json.RawMessage, prefix, suffix []byte) error {
 	color.NoColor = !f.Colorize
-	f.enhance(entry)
+	hidden, restore, err := f.folded(entry.Severity)
+	if hidden {
+		f.Hidden.AddEntry(HiddenFolded, len(raw))
+	}
+	if hidden || err != nil {
+		return err
+	}
+	defer restore()
+	f.extractLogger(entry, raw)
+	if f.ShowThread {
+		f.extractThread(entry, raw)
+	}
+	f.normalize(entry)
+	record := &Record{Entry: entry, Raw: raw, Prefix: prefix, Suffix: suffix}
+	if f.Printer != nil {
+		record.Fields = f.Fields(entry, raw)
+	}
+	return f.printer().Print(f.output, record)
+}
 
-	err := f.outputSimple(prefix, f.ShowPrefix)
+// FormatRaw outputs a line that couldn't be parsed as a structured entry.
+func (f *Formatter) FormatRaw(line []byte) error {
+	message := string(line)
+	if !f.KeepANSI {
+		message = StripANSI(message)
+	}
+	return f.printer().Print(f.output, &Record{Entry: &Entry{Message: message}, Plain: true})
+}
+
+// textPrinter writes the text output of the formatter: the template, the
+// fields, and the stacktraces and source code below the line.
+type textPrinter struct {
+	f *Formatter
+}
+
+func (p textPrinter) Print(w io.Writer, record *Record) error {
+	if record.Plain {
+		return p.f.formatPlain([]byte(record.Message))
+	}
+	highlight := p.f.highlighted(record.Severity)
+	p.f.enhance(record.Entry)
+	return p.f.formatText(record, highlight)
+}
+
+// formatText writes the text output of an enhanced entry.
+func (f *Formatter) formatText(record *Record, highlight string) error {
+	entry, raw := record.Entry, record.Raw
+	err := f.outputBootSeparator(entry)
 	if err != nil {
 		return err
 	}
 
-	err = f.template.Execute(f.output, entry)
+	err = f.outputDaySeparator(entry)
 	if err != nil {
 		return err
 	}
 
-	f.outputFields(entry, raw)
+	if highlight == HighlightRule {
+		err = f.outputHighlightRule(NormalizeSeverity(entry.Severity))
+		if err != nil {
+			return err
+		}
+	}
+
+	if highlight == HighlightBackground {
+		err = f.outputHighlighted(func() error {
+			return f.outputLine(entry, raw, record.Prefix, record.Suffix)
+		})
+	} else {
+		err = f.outputLine(entry, raw, record.Prefix, record.Suffix)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = f.outputAnnotations(entry)
+	if err != nil {
+		return err
+	}
 
-	err = f.outputSimple(suffix, f.ShowSuffix)
+	hasStacktrace, err := f.outputStacktrace(raw)
 	if err != nil {
 		return err
 	}
 
-	err = stacktrace(f.output, raw)
+	if !hasStacktrace {
+		err = f.outputErrorChain(raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = f.outputSourceContext(raw)
 	if err != nil {
 		return err
 	}
 
 	_, err = f.output.Write(NewLine)
-	return err
+	if err != nil || highlight != HighlightRule {
+		return err
+	}
+	return f.outputHighlightRule("")
 }
 
-func (f *Formatter) enhance(entry *Entry) {
-	if entry.Timestamp != nil && entry.Timestamp.IsZero() {
-		entry.Timestamp = nil
+// outputLine writes the line of the entry: the prefix, the template, the
+// fields and the suffix.
+func (f *Formatter) outputLine(entry *Entry, raw json.RawMessage, prefix, suffix []byte) error {
+	err := f.outputSimple(prefix, f.ShowPrefix)
+	if err != nil {
+		return err
 	}
 
-	if entry.Timestamp != nil && entry.Timestamp.Year() > 3000 { // timestamp was probably in milliseconds
-		t := *entry.Timestamp
-		t = time.Unix(t.Unix()/int64(time.Second/time.Millisecond), 0).UTC()
-		entry.Timestamp = &t
+	err = f.template.Execute(f.output, templateEntry{entry, raw})
+	if err != nil {
+		return err
 	}
 
-	entry.Severity = strings.ToUpper(entry.Severity)
-	if level, ok := severityMapping[entry.Severity]; ok {
-		entry.Severity = level
+	f.outputFields(entry, raw)
+
+	return f.outputSimple(suffix, f.ShowSuffix)
+}
+
+// formatPlain writes a line of plain text as it is.
+func (f *Formatter) formatPlain(line []byte) error {
+	hidden, restore, err := f.folded("")
+	if hidden {
+		f.Hidden.AddEntry(HiddenFolded, len(line))
+	}
+	if hidden || err != nil {
+		return err
+	}
+	defer restore()
+	if f.InferLevels && f.Colorize {
+		// plain text is colored like an entry of the level it mentions:
+		if paint, ok := severityColors[InferSeverity(string(line))]; ok {
+			color.NoColor = false
+			line = []byte(paint(string(line)))
+		}
 	}
+	_, err = f.output.Write(append(line, NewLine...))
+	return err
+}
+
+func (f *Formatter) enhance(entry *Entry) {
+	f.normalize(entry)
 	if entry.Severity != "" {
 		padding := 7 - len(entry.Severity)
 		if color, ok := severityColors[entry.Severity]; ok {
@@ -126,7 +329,56 @@ func (f *Formatter) enhance(entry *Entry) {
 		}
 	}
 
-	entry.Message = messageColor(entry.Message)
+	f.alignThread(entry)
+	f.alignLogger(entry)
+	entry.Message = f.colorMessage(entry)
+}
+
+// colorMessage colors the message, with the values substituted into its
+// message template in a color of their own when HighlightValues is set.
+func (f *Formatter) colorMessage(entry *Entry) string {
+	if !f.HighlightValues || len(entry.Substitutions) == 0 {
+		return messageColor(entry.Message)
+	}
+	var b strings.Builder
+	last := 0
+	for _, substitution := range entry.Substitutions {
+		start, end := substitution[0], substitution[1]
+		if start < last || end > len(entry.Message) {
+			break
+		}
+		if start > last {
+			b.WriteString(messageColor(entry.Message[last:start]))
+		}
+		b.WriteString(substitutionColor(entry.Message[start:end]))
+		last = end
+	}
+	if last < len(entry.Message) {
+		b.WriteString(messageColor(entry.Message[last:]))
+	}
+	return b.String()
+}
+
+// normalize fixes the timestamp and severity of the entry, without coloring
+// them, and strips the escape sequences of the message.
+func (f *Formatter) normalize(entry *Entry) {
+	if entry.Timestamp != nil && entry.Timestamp.IsZero() {
+		entry.Timestamp = nil
+	}
+
+	if entry.Timestamp != nil && entry.Timestamp.Year() > 3000 { // timestamp was probably in milliseconds
+		t := *entry.Timestamp
+		t = time.Unix(t.Unix()/int64(time.Second/time.Millisecond), 0).UTC()
+		entry.Timestamp = &t
+	}
+
+	entry.Severity = NormalizeSeverity(entry.Severity)
+
+	// escape sequences of the producer would reset or corrupt our own colors:
+	if stripped := StripANSI(entry.Message); !f.KeepANSI && stripped != entry.Message {
+		entry.Message = stripped
+		entry.Substitutions = nil
+	}
 }
 
 func (f *Formatter) outputSimple(txt []byte, toggle bool) error {
@@ -143,66 +395,221 @@ func (f *Formatter) outputFields(entry *Entry, raw json.RawMessage) {
 	if !f.ShowFields {
 		return
 	}
-	fields := make(map[string]interface{})
-	err := json.Unmarshal(raw, &fields)
-
-	if labels, ok := fields["labels"]; ok {
-		for k, v := range labels.(map[string]interface{}) {
-			fields[k] = v
+	output, hidden := f.fields(entry, raw)
+	if len(output) > 0 {
+		if f.Quote == QuoteShell {
+			fmt.Fprintf(f.output, " %s", strings.Join(output, " "))
+		} else {
+			fmt.Fprintf(f.output, " %v", output)
 		}
-		delete(fields, "labels")
 	}
+	if f.ShowHiddenCount && hidden > 0 {
+		fmt.Fprintf(f.output, " %s", hiddenColor(pluralize(hidden, "field")))
+	}
+}
 
+// fields returns the sorted key=value pairs of the fields of the entry that
+// should be shown and the number of fields that were hidden.
+func (f *Formatter) fields(entry *Entry, raw json.RawMessage) ([]string, int) {
 	output := make([]string, 0)
-	if err == nil {
+	hidden := 0
+	maxDepth := f.MaxDepth
+	if f.Verbosity >= VerboseExpand {
+		maxDepth = 0
+	}
+	fields, ok := f.selectFields(entry, raw, maxDepth)
+	if ok {
 		path := ""
-		for key, value := range walkFields(fields, "") {
+		for key, value := range fields {
 			if _, ok := value.(map[string]interface{}); ok {
 				continue
 			}
-			if _, ok := value.([]interface{}); ok {
-				continue
+			if str, ok := value.(string); ok && strings.Contains(str, "\n") && contains(f.StackFields, key) {
+				continue // printed as stacktrace below the entry
+			}
+			if str, ok := value.(string); ok && isCaller(key, str) {
+				value = ShortenCaller(str, f.CallerRule, f.WorkDir)
 			}
-			if !f.shouldSkipField(entry, key, path+"."+key, value) {
-				switch v := value.(type) {
-				case float64:
-					output = append(output, key+"="+strconv.FormatFloat(v, 'f', -1, 64))
+			if str, ok := value.(string); ok && contains(f.UserAgentFields, key) && f.Verbosity < VerboseExpand {
+				if ua := SummarizeUserAgent(str); ua != "" {
+					value = summary(ua)
+				}
+			}
+			if array, ok := value.([]interface{}); ok {
+				switch f.ArrayStyle {
+				case ArrayIndex:
+					for i, elem := range array {
+						if reason := f.skipReason(entry, key, path+"."+key, elem); reason == "" && f.Quote == QuoteShell {
+							output = append(output, shellWord(fmt.Sprintf("%s.%d", key, i), f.formatValue(elem)))
+						} else if reason == "" {
+							output = append(output, fmt.Sprintf("%s.%d=%s", key, i, f.formatValue(elem)))
+						} else if !isConsumed(entry, key) {
+							f.Hidden.addField(reason, key, elem)
+							hidden++
+						}
+					}
+					continue
+				case ArrayCount:
+					if f.Verbosity < VerboseExpand {
+						value = summary(fmt.Sprintf("(%d items)", len(array)))
+					}
+				case ArrayInline:
 				default:
-					output = append(output, fmt.Sprintf("%s=%v", key, value))
+					continue
 				}
 			}
+			if reason := f.skipReason(entry, key, path+"."+key, value); reason == "" && f.Quote == QuoteShell {
+				output = append(output, shellWord(key, f.formatValue(value)))
+			} else if reason == "" {
+				output = append(output, key+"="+colorStatus(key, value, f.formatValue(value)))
+			} else if !isConsumed(entry, key) {
+				f.Hidden.addField(reason, key, value)
+				hidden++
+			}
 		}
-		if len(output) > 0 {
-			sort.Strings(output)
-			fmt.Fprintf(f.output, " %v", output)
+		sort.Strings(output)
+	}
+	return output, hidden
+}
+
+// selectFields is the first step of printing the fields of an entry, shared
+// by all outputs. It flattens nested objects into dotted keys, summarizing
+// those deeper than maxDepth when positive, and redacts the values: tokens
+// are redacted and escape sequences stripped. It returns false when the raw
+// json isn't an object.
+func (f *Formatter) selectFields(entry *Entry, raw json.RawMessage, maxDepth int) (map[string]interface{}, bool) {
+	fields := make(map[string]interface{})
+	if json.Unmarshal(raw, &fields) != nil {
+		return nil, false
+	}
+	compactSource(fields)
+
+	for _, key := range append([]string{"labels"}, entry.FlattenFields...) {
+		nested, ok := fields[key].(map[string]interface{})
+		if str, isString := fields[key].(string); isString {
+			ok = json.Unmarshal([]byte(str), &nested) == nil
+		}
+		if ok {
+			for k, v := range nested {
+				fields[k] = v
+			}
+			delete(fields, key)
 		}
 	}
+
+	flat := walkFields(fields, "", maxDepth)
+	for key, value := range flat {
+		if str, ok := value.(string); ok {
+			flat[key] = f.redact(str)
+		}
+	}
+	return flat, true
 }
 
-func (f *Formatter) shouldSkipField(entry *Entry, field, path string, value interface{}) bool {
-	if contains(f.IncludeFields, field) || contains(f.IncludeFields, path) {
+// redact returns the value of a field to print: tokens are redacted and the
+// escape sequences of the producer are stripped.
+func (f *Formatter) redact(value string) interface{} {
+	if token, ok := f.formatJWT(value); ok {
+		return token
+	}
+	if !f.KeepANSI {
+		return StripANSI(value)
+	}
+	return value
+}
+
+// excluded returns true when the field is excluded and not explicitly
+// included.
+func (f *Formatter) excluded(entry *Entry, field string) bool {
+	if contains(f.IncludeFields, field) || contains(entry.IncludeFields, field) {
 		return false
 	}
+	return contains(entry.ExcludeFields, field) || contains(f.ExcludeFields, field)
+}
+
+// isConsumed returns true if the field is already part of the formatted
+// entry (like the message or level), as opposed to being hidden.
+func isConsumed(entry *Entry, field string) bool {
+	consumed := contains(defaultExcludes, field) && !contains(infrastructureFields, field)
+	return consumed || contains(entryKeys, field) || contains(entry.ExcludeFields, field)
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("(+%d %s)", n, noun)
+	}
+	return fmt.Sprintf("(+%d %ss)", n, noun)
+}
+
+// skipReason returns why a field is hidden, or an empty string when the field
+// should be shown.
+func (f *Formatter) skipReason(entry *Entry, field, path string, value interface{}) string {
+	if contains(f.IncludeFields, field) || contains(f.IncludeFields, path) {
+		return ""
+	}
 	if contains(entry.IncludeFields, field) {
-		return false
+		return ""
 	}
 	if contains(entry.ExcludeFields, field) {
-		return true
+		return HiddenExcluded
 	}
-	if strings.Count(path, ".") > 1 {
+	if f.Verbosity >= VerboseAll {
+		if contains(f.ExcludeFields, field) && !contains(infrastructureFields, field) {
+			return HiddenExcluded
+		}
+		return ""
+	}
+	// the objects at the depth limit are summarized, and expanded by -v, so
+	// these are shown rather than hidden as nested:
+	depth := strings.Count(path, ".")
+	if s, ok := value.(summary); (ok && strings.HasPrefix(string(s), "{…")) || (f.MaxDepth > 0 && depth > f.MaxDepth) {
+		depth = 1
+	}
+	if depth > 1 {
 		first, _, _ := strings.Cut(strings.Trim(path, "."), ".")
 		if contains(f.IncludeFields, first) {
-			return false
+			return ""
 		}
 		if contains(entry.IncludeFields, first) {
-			return false
+			return ""
 		}
-		return true
+		return HiddenNested
+	}
+	if _, ok := value.(summary); !ok && f.Verbosity < VerboseExpand && f.MaxFieldLength > 0 && len(path+fmt.Sprintf("%v", value)) >= f.MaxFieldLength {
+		return HiddenTooLong
 	}
-	if f.MaxFieldLength > 0 && len(path+fmt.Sprintf("%v", value)) >= f.MaxFieldLength {
-		return true
+	if contains(infrastructureFields, field) {
+		return HiddenInfrastructure
+	}
+	if contains(f.ExcludeFields, field) {
+		return HiddenExcluded
+	}
+	return ""
+}
+
+// summary is a textual replacement of a value which is never quoted.
+type summary string
+
+// formatValue returns the textual representation of a json value, arrays are
+// rendered inline as [a,b,c] and objects as compact json.
+func (f *Formatter) formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return f.quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i, elem := range v {
+			elems[i] = f.formatValue(elem)
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", value)
 	}
-	return contains(f.ExcludeFields, field)
 }
 
 func contains(lst []string, val string) bool {
@@ -214,14 +621,25 @@ func contains(lst []string, val string) bool {
 	return false
 }
 
-func walkFields(fields map[string]interface{}, path string) map[string]interface{} {
+// walkFields flattens nested objects into dotted keys. Objects nested
+// deeper than maxDepth (when positive) are summarized as "{…N}", where N is
+// the number of keys in the object.
+func walkFields(fields map[string]interface{}, path string, maxDepth int) map[string]interface{} {
 	result := make(map[string]interface{})
+	depth := 1
+	if path != "" {
+		depth = strings.Count(path, ".") + 2
+	}
 	for key, value := range fields {
 		if path != "" {
 			key = path + "." + key
 		}
 		if nested, ok := value.(map[string]interface{}); ok {
-			for k, v := range walkFields(nested, key) {
+			if maxDepth > 0 && depth >= maxDepth {
+				result[key] = summary(fmt.Sprintf("{…%d}", len(nested)))
+				continue
+			}
+			for k, v := range walkFields(nested, key, maxDepth) {
 				result[k] = v
 			}
 		} else {
@@ -0,0 +1,90 @@
+package structure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// githubPrinter writes errors and warnings as GitHub Actions annotations,
+// and the other entries as text.
+type githubPrinter struct {
+	f *Formatter
+}
+
+func (p githubPrinter) Print(w io.Writer, record *Record) error {
+	if record.Plain {
+		return p.f.formatPlain([]byte(record.Message))
+	}
+	highlight := p.f.highlighted(record.Severity)
+	p.f.enhance(record.Entry)
+	if handled, err := p.f.formatGitHub(record.Entry, record.Raw); handled {
+		return err
+	}
+	return p.f.formatText(record, highlight)
+}
+
+// formatGitHub outputs ERROR and WARNING entries as GitHub Actions workflow
+// commands, so they are shown as annotations. It returns false for entries
+// of other levels, those are formatted as text.
+func (f *Formatter) formatGitHub(entry *Entry, raw json.RawMessage) (bool, error) {
+	command := ""
+	switch strings.TrimSpace(entry.Severity) {
+	case "ERROR", "FATAL", "CRITICAL", "ALERT", "EMERGENCY", "PANIC":
+		command = "error"
+	case "WARNING":
+		command = "warning"
+	default:
+		return false, nil
+	}
+
+	var properties []string
+	if file, line, ok := callerLocation(raw); ok {
+		file = githubFile(file, f.WorkDir)
+		properties = append(properties, "file="+githubEscapeProperty(file), fmt.Sprintf("line=%d", line))
+	}
+	if entry.Name != "" {
+		properties = append(properties, "title="+githubEscapeProperty(entry.Name))
+	}
+
+	message := entry.Message
+	if f.ShowFields {
+		if fields, _ := f.fields(entry, raw); len(fields) > 0 {
+			message += fmt.Sprintf(" %v", fields)
+		}
+	}
+
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	_, err := fmt.Fprintf(f.output, "::%s::%s\n", command, githubEscapeData(message))
+	return true, err
+}
+
+// githubFile makes an absolute file path relative to the working directory,
+// the checkout in a workflow, so the annotation is attached to that file.
+// Other paths are kept whole, a truncated path would not match any file.
+func githubFile(file, workdir string) string {
+	if workdir == "" || !filepath.IsAbs(file) {
+		return file
+	}
+	rel, err := filepath.Rel(workdir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+func githubEscapeData(data string) string {
+	data = strings.ReplaceAll(data, "%", "%25")
+	data = strings.ReplaceAll(data, "\r", "%0D")
+	return strings.ReplaceAll(data, "\n", "%0A")
+}
+
+func githubEscapeProperty(property string) string {
+	property = githubEscapeData(property)
+	property = strings.ReplaceAll(property, ":", "%3A")
+	return strings.ReplaceAll(property, ",", "%2C")
+}
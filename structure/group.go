@@ -0,0 +1,104 @@
+package structure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// group is a range of lines with a title, like a ##[group] of a CI log.
+type group struct {
+	title  string
+	hidden int
+	opened bool
+}
+
+// StartGroup starts a range of lines with the given title, ending any
+// previous group. Unless verbose, the lines of the group are folded into a
+// single line, only its warnings and errors are shown. Groups are only
+// rendered by the text and html output.
+func (f *Formatter) StartGroup(title string) error {
+	if err := f.EndGroup(); err != nil {
+		return err
+	}
+	if f.Output != "" && f.Output != OutputText && f.Output != OutputHTML {
+		return nil
+	}
+	f.group = &group{title: title}
+	if f.Verbosity >= VerboseExpand {
+		return f.openGroup()
+	}
+	return nil
+}
+
+// EndGroup ends the current group, writing the number of folded lines.
+func (f *Formatter) EndGroup() error {
+	g := f.group
+	f.group = nil
+	if g == nil || g.hidden == 0 && g.opened {
+		return nil
+	}
+	color.NoColor = !f.Colorize
+	var err error
+	if g.opened {
+		_, err = fmt.Fprintf(f.output, "  %s\n", hiddenColor(pluralize(g.hidden, "line")))
+	} else {
+		lines := "lines"
+		if g.hidden == 1 {
+			lines = "line"
+		}
+		_, err = fmt.Fprintf(f.output, "%s %s\n", separatorColor("▸ "+g.title), hiddenColor(fmt.Sprintf("(%d %s)", g.hidden, lines)))
+	}
+	return err
+}
+
+func (f *Formatter) openGroup() error {
+	f.group.opened = true
+	color.NoColor = !f.Colorize
+	_, err := fmt.Fprintln(f.output, separatorColor("▾ "+f.group.title))
+	return err
+}
+
+// folded returns true when the line is hidden in the current group,
+// otherwise the output is indented until the returned function is called.
+func (f *Formatter) folded(severity string) (bool, func(), error) {
+	if f.group == nil {
+		return false, func() {}, nil
+	}
+	if f.Verbosity < VerboseExpand && SeverityRank(severity) < severityRanks["WARNING"] {
+		f.group.hidden++
+		return true, nil, nil
+	}
+	if !f.group.opened {
+		if err := f.openGroup(); err != nil {
+			return false, nil, err
+		}
+	}
+	output := f.output
+	f.output = &indentWriter{output: output, indent: []byte("  "), lineStart: true}
+	return false, func() { f.output = output }, nil
+}
+
+// indentWriter indents every line written to it.
+type indentWriter struct {
+	output    io.Writer
+	indent    []byte
+	lineStart bool
+}
+
+func (w *indentWriter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, b := range p {
+		if w.lineStart && b != '\n' {
+			buf.Write(w.indent)
+		}
+		buf.WriteByte(b)
+		w.lineStart = b == '\n'
+	}
+	if _, err := w.output.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,91 @@
+package structure
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The reasons entries and fields are hidden, counted by Hidden.
+const (
+	HiddenExcluded       = "excluded"
+	HiddenNested         = "nested"
+	HiddenTooLong        = "too long"
+	HiddenInfrastructure = "infrastructure"
+	HiddenFolded         = "folded"
+	HiddenInvalid        = "invalid"
+	HiddenBelowLevel     = "below level"
+	HiddenOtherThread    = "other thread"
+	HiddenOutsideTime    = "outside time range"
+	HiddenNotMatching    = "not matching"
+	HiddenSampled        = "sampled"
+)
+
+// Hidden counts the entries and fields that were left out of the output by
+// their reason, and their size in bytes.
+type Hidden struct {
+	Entries map[string]int
+	Fields  map[string]int
+	Bytes   int
+}
+
+// AddEntry counts an entry of the given size that was hidden.
+func (h *Hidden) AddEntry(reason string, size int) {
+	if h.Entries == nil {
+		h.Entries = map[string]int{}
+	}
+	h.Entries[reason]++
+	h.Bytes += size
+}
+
+func (h *Hidden) addField(reason, key string, value interface{}) {
+	if h.Fields == nil {
+		h.Fields = map[string]int{}
+	}
+	h.Fields[reason]++
+	h.Bytes += len(key) + len(fmt.Sprintf("%v", value))
+}
+
+// WriteHidden writes the totals of the hidden entries and fields, like
+// "hidden: 2 entries (2 folded), 3 fields (1 excluded, 2 too long), 154 bytes".
+func WriteHidden(w io.Writer, h Hidden) error {
+	if len(h.Entries) == 0 && len(h.Fields) == 0 {
+		_, err := fmt.Fprintln(w, "hidden: nothing")
+		return err
+	}
+	var parts []string
+	if len(h.Entries) > 0 {
+		parts = append(parts, countReasons(h.Entries, "entry", "entries"))
+	}
+	if len(h.Fields) > 0 {
+		parts = append(parts, countReasons(h.Fields, "field", "fields"))
+	}
+	parts = append(parts, fmt.Sprintf("%d bytes", h.Bytes))
+	_, err := fmt.Fprintf(w, "hidden: %s\n", strings.Join(parts, ", "))
+	return err
+}
+
+func countReasons(counts map[string]int, singular, plural string) string {
+	reasons := make([]string, 0, len(counts))
+	total := 0
+	for reason, n := range counts {
+		reasons = append(reasons, reason)
+		total += n
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	details := make([]string, len(reasons))
+	for i, reason := range reasons {
+		details[i] = fmt.Sprintf("%d %s", counts[reason], reason)
+	}
+	noun := plural
+	if total == 1 {
+		noun = singular
+	}
+	return fmt.Sprintf("%d %s (%s)", total, noun, strings.Join(details, ", "))
+}
@@ -0,0 +1,62 @@
+package structure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Highlights of critical entries, the entries logged at error or above.
+const (
+	// HighlightBackground colors the background of the whole line.
+	HighlightBackground = "background"
+	// HighlightRule writes a full-width rule above and below the entry.
+	HighlightRule = "rule"
+)
+
+var highlightColor = color.New(color.BgRed, color.FgHiWhite, color.Bold).SprintFunc()
+var highlightRuleColor = color.New(color.FgHiRed).SprintFunc()
+
+// highlighted returns the highlight of an entry with the severity, or an
+// empty string when it isn't highlighted. Backgrounds need colors, without
+// them the entry gets rules instead.
+func (f *Formatter) highlighted(severity string) string {
+	if f.Highlight == "" || SeverityRank(severity) < severityRanks["ERROR"] {
+		return ""
+	}
+	if f.Highlight == HighlightBackground && !f.Colorize {
+		return HighlightRule
+	}
+	return f.Highlight
+}
+
+// outputHighlightRule writes a full-width rule, with the label when it isn't
+// empty.
+func (f *Formatter) outputHighlightRule(label string) error {
+	rule := strings.Repeat(separatorRune, f.Width)
+	if label != "" {
+		rule = separator(label, f.Width)
+	}
+	_, err := fmt.Fprintln(f.output, highlightRuleColor(rule))
+	return err
+}
+
+// outputHighlighted writes the line written by write with a background as
+// wide as the terminal. The colors of the line are removed, they would reset
+// the background.
+func (f *Formatter) outputHighlighted(write func() error) error {
+	output := f.output
+	line := &bytes.Buffer{}
+	f.output = line
+	err := write()
+	f.output = output
+	if err != nil {
+		return err
+	}
+	text := padRight(ansiCode.ReplaceAllString(line.String(), ""), f.Width)
+	_, err = io.WriteString(f.output, highlightColor(text))
+	return err
+}
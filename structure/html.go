@@ -0,0 +1,109 @@
+package structure
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strings"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>jl</title>
+<style>
+body { background: #1e1e1e; color: #d4d4d4; }
+pre { font-family: monospace; white-space: pre-wrap; }
+.bold { font-weight: bold; }
+.fg-31 { color: #cd3131; } .fg-32 { color: #0dbc79; } .fg-33 { color: #e5e510; }
+.fg-34 { color: #2472c8; } .fg-35 { color: #bc3fbc; } .fg-36 { color: #11a8cd; }
+.fg-37 { color: #e5e5e5; } .fg-90 { color: #767676; } .fg-91 { color: #f14c4c; }
+.fg-92 { color: #23d18b; } .fg-93 { color: #f5f543; } .fg-94 { color: #3b8eea; }
+.fg-95 { color: #d670d6; } .fg-96 { color: #29b8db; } .fg-97 { color: #ffffff; }
+.bg-41 { background: #cd3131; } .bg-43 { background: #e5e510; } .bg-101 { background: #f14c4c; }
+</style>
+</head>
+<body>
+<pre>
+`
+
+const htmlFooter = `</pre>
+</body>
+</html>
+`
+
+// HTMLWriter converts the colorized output of a Formatter into a standalone
+// html page, translating the ANSI escape codes into styled spans.
+type HTMLWriter struct {
+	output  io.Writer
+	started bool
+	open    bool
+}
+
+// NewHTMLWriter returns an HTMLWriter writing the page to w, the page is
+// completed by calling Close.
+func NewHTMLWriter(w io.Writer) *HTMLWriter {
+	return &HTMLWriter{output: w}
+}
+
+func (h *HTMLWriter) Write(p []byte) (int, error) {
+	buf := &bytes.Buffer{}
+	if !h.started {
+		h.started = true
+		buf.WriteString(htmlHeader)
+	}
+	text := string(p)
+	for {
+		start := strings.Index(text, "\x1b[")
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(text[start:], 'm')
+		if end == -1 {
+			break
+		}
+		buf.WriteString(html.EscapeString(text[:start]))
+		h.style(buf, strings.Split(text[start+2:start+end], ";"))
+		text = text[start+end+1:]
+	}
+	buf.WriteString(html.EscapeString(text))
+	_, err := h.output.Write(buf.Bytes())
+	return len(p), err
+}
+
+func (h *HTMLWriter) style(buf *bytes.Buffer, codes []string) {
+	if h.open {
+		buf.WriteString("</span>")
+		h.open = false
+	}
+	var classes []string
+	for _, code := range codes {
+		switch {
+		case code == "0" || code == "":
+		case code == "1":
+			classes = append(classes, "bold")
+		case strings.HasPrefix(code, "4") && len(code) == 2, strings.HasPrefix(code, "10"):
+			classes = append(classes, "bg-"+code)
+		default:
+			classes = append(classes, "fg-"+code)
+		}
+	}
+	if len(classes) > 0 {
+		buf.WriteString(`<span class="` + strings.Join(classes, " ") + `">`)
+		h.open = true
+	}
+}
+
+// Close writes the end of the html page.
+func (h *HTMLWriter) Close() error {
+	footer := htmlFooter
+	if !h.started {
+		footer = htmlHeader + footer
+	}
+	if h.open {
+		footer = "</span>" + footer
+	}
+	_, err := h.output.Write([]byte(footer))
+	return err
+}
@@ -0,0 +1,79 @@
+package structure
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// JWT styles, used by the Formatter to render field values that are JSON
+// Web Tokens.
+const (
+	// JWTRedact replaces the signature of a token, so it can't be reused.
+	JWTRedact  = "redact"
+	JWTSummary = "summary"
+	JWTRaw     = "raw"
+)
+
+// jwtClaims are the claims shown in the summary of a token.
+var jwtClaims = []string{"sub", "aud", "exp"}
+
+var expiredColor = color.New(color.FgRed).SprintFunc()
+
+// formatJWT returns the value to show for a field that holds a JSON Web
+// Token, optionally prefixed with "Bearer ". It returns false when the
+// value isn't a token.
+func (f *Formatter) formatJWT(value string) (interface{}, bool) {
+	if f.JWT == JWTRaw {
+		return nil, false
+	}
+	scheme, token, found := strings.Cut(value, " ")
+	if !found {
+		scheme, token = "", value
+	} else if !strings.EqualFold(scheme, "bearer") {
+		return nil, false
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	var header, claims map[string]interface{}
+	if !decodeJWTPart(parts[0], &header) || header["alg"] == nil || !decodeJWTPart(parts[1], &claims) {
+		return nil, false
+	}
+	if f.JWT != JWTSummary {
+		if parts[2] == "" {
+			return value, true
+		}
+		return strings.TrimPrefix(scheme+" ", " ") + parts[0] + "." + parts[1] + ".<redacted>", true
+	}
+
+	var pairs []string
+	for _, claim := range jwtClaims {
+		switch v := claims[claim].(type) {
+		case nil:
+		case float64:
+			expires := time.Unix(int64(v), 0).UTC()
+			text := expires.Format(time.RFC3339)
+			if expires.Before(time.Now()) {
+				text = expiredColor(text + "(expired)")
+			}
+			pairs = append(pairs, claim+"="+text)
+		case []interface{}:
+			pairs = append(pairs, claim+"="+f.formatValue(v))
+		default:
+			pairs = append(pairs, fmt.Sprintf("%s=%v", claim, v))
+		}
+	}
+	return summary("jwt{" + strings.Join(pairs, ",") + "}"), true
+}
+
+// decodeJWTPart decodes a base64url encoded json part of a token.
+func decodeJWTPart(part string, v interface{}) bool {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	return err == nil && json.Unmarshal(data, v) == nil
+}
@@ -0,0 +1,79 @@
+package structure
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultLoggerFields are the json keys that are used as the logger of an
+// entry, the first one that is present is used.
+var DefaultLoggerFields = []string{"logger", "component", "module", "@module"}
+
+// maxColumnWidth is the width up to which the logger and thread columns are
+// aligned, longer values are not padded to keep the messages readable.
+const maxColumnWidth = 24
+
+var loggerColors = []color.Attribute{
+	color.FgGreen, color.FgYellow, color.FgBlue, color.FgMagenta,
+	color.FgHiGreen, color.FgHiYellow, color.FgHiBlue, color.FgHiMagenta,
+}
+
+// nameColor returns the color of a name, picked by a hash of the name so
+// every name keeps its color.
+func nameColor(name string) *color.Color {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return color.New(loggerColors[h.Sum32()%uint32(len(loggerColors))])
+}
+
+// ColorName colors the name like the logger column, when colorize is set.
+func ColorName(name string, colorize bool) string {
+	if !colorize {
+		return name
+	}
+	c := nameColor(name)
+	c.EnableColor()
+	return c.Sprint(name)
+}
+
+// extractLogger uses the first of the LoggerFields that is present as the
+// logger of the entry, unless a processor already set one.
+func (f *Formatter) extractLogger(entry *Entry, raw []byte) {
+	if entry.Logger != "" {
+		return
+	}
+	for _, field := range f.LoggerFields {
+		value := lookup(raw, field)
+		if value.Type == gjson.String && value.String() != "" {
+			entry.Logger = value.String()
+			entry.ExcludeFields = append(entry.ExcludeFields, field)
+			return
+		}
+	}
+}
+
+// alignLogger colors the logger by a hash of its name, so every logger keeps
+// its color, and pads it to the widest logger seen so far.
+func (f *Formatter) alignLogger(entry *Entry) {
+	if entry.Logger == "" {
+		return
+	}
+	padding := align(entry.Logger, &f.loggerWidth)
+	entry.Logger = nameColor(entry.Logger).Sprint(entry.Logger) + ":" + padding
+}
+
+// align returns the padding to align the text with the widest text of a
+// column, which is updated when the text is wider.
+func align(text string, widest *int) string {
+	width := len(text)
+	if width <= maxColumnWidth && width > *widest {
+		*widest = width
+	}
+	if width < *widest {
+		return strings.Repeat(" ", *widest-width)
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package structure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var markdownHeader = "| Time | Level | Message | Fields |\n|------|-------|---------|--------|\n"
+
+// markdownPrinter writes the entries as the rows of a markdown table.
+type markdownPrinter struct {
+	f *Formatter
+}
+
+func (p markdownPrinter) Print(w io.Writer, record *Record) error {
+	if record.Plain {
+		return p.f.formatMarkdownRaw([]byte(record.Message))
+	}
+	p.f.enhance(record.Entry)
+	return p.f.formatMarkdown(record.Entry, record.Raw)
+}
+
+// formatMarkdown outputs the entry as a row of a markdown table, the header
+// of the table is written before the first row.
+func (f *Formatter) formatMarkdown(entry *Entry, raw json.RawMessage) error {
+	timestamp := entry.RawTimestamp
+	if entry.Timestamp != nil {
+		timestamp = entry.Timestamp.Format("2006-01-02 15:04:05")
+	}
+	var fields []string
+	if f.ShowFields {
+		fields, _ = f.fields(entry, raw)
+	}
+	level := strings.TrimSpace(entry.Severity)
+	switch level {
+	case "":
+	case "ERROR", "FATAL", "CRITICAL", "ALERT", "EMERGENCY", "PANIC":
+		level = "**" + level + "**"
+	case "WARNING":
+		level = "_" + level + "_"
+	}
+	return f.markdownRow(timestamp, level, MarkdownEscape(entry.Message), MarkdownEscape(strings.Join(fields, " ")))
+}
+
+// formatMarkdownRaw outputs a line that couldn't be parsed as a row with only
+// a message.
+func (f *Formatter) formatMarkdownRaw(line []byte) error {
+	return f.markdownRow("", "", MarkdownEscape(string(line)), "")
+}
+
+func (f *Formatter) markdownRow(columns ...string) error {
+	if !f.wroteHeader {
+		f.wroteHeader = true
+		if _, err := f.output.Write([]byte(markdownHeader)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(f.output, "| %s |\n", strings.Join(columns, " | "))
+	return err
+}
+
+// markdownEscaper escapes html and the markdown syntax that works within a
+// cell, so the text is shown as is.
+var markdownEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", "\n", "<br>", "|", `\|`,
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "~", `\~`,
+)
+
+// MarkdownEscape escapes text for a cell of a markdown table.
+func MarkdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// MarkdownCode formats text as a code span for a cell of a markdown table.
+// Text in a code span is shown as is, only the pipe has to be escaped for
+// the table, and the span is fenced by more backticks than the text has.
+func MarkdownCode(text string) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	text = strings.ReplaceAll(text, "|", `\|`)
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
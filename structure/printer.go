@@ -0,0 +1,315 @@
+package structure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// More output modes, written by a Printer.
+const (
+	// OutputJSON outputs normalized json lines.
+	OutputJSON = "json"
+	// OutputLogfmt outputs key=value lines.
+	OutputLogfmt = "logfmt"
+	// OutputCompact outputs short lines with an abbreviated level.
+	OutputCompact = "compact"
+	// OutputExpanded outputs every field on a line of its own.
+	OutputExpanded = "expanded"
+)
+
+// Record is an entry as it's given to a Printer: the entry, the raw json of
+// its fields and the bytes around the json on its line. Lines that aren't
+// structured are Plain, with the line as the message.
+type Record struct {
+	*Entry
+	Raw    json.RawMessage
+	Prefix []byte
+	Suffix []byte
+	Plain  bool
+
+	// Fields are the fields to print, selected and redacted by the
+	// Formatter. They're only set for the Printer of the Formatter, its own
+	// outputs select the fields themselves.
+	Fields []Field
+}
+
+// Field is a field of an entry, with its value as text.
+type Field struct {
+	Key, Value string
+	Raw        interface{}
+}
+
+// Printer writes a record to w. Every output mode has a Printer, those of
+// the json, logfmt, compact and expanded outputs have no state and can be
+// used on their own.
+type Printer interface {
+	Print(w io.Writer, record *Record) error
+}
+
+// NewPrinter returns the printer of the output mode, or nil when the mode is
+// written by a printer of the Formatter itself.
+func NewPrinter(output string, colorize bool) Printer {
+	switch output {
+	case OutputJSON:
+		return JSONPrinter{}
+	case OutputLogfmt:
+		return LogfmtPrinter{}
+	case OutputCompact:
+		return CompactPrinter{Colorize: colorize}
+	case OutputExpanded:
+		return ExpandedPrinter{Colorize: colorize}
+	}
+	return nil
+}
+
+// printer returns the Printer of the formatter, or the printer of its own
+// output mode.
+func (f *Formatter) printer() Printer {
+	if f.Printer != nil {
+		return f.Printer
+	}
+	switch f.Output {
+	case OutputMarkdown:
+		return markdownPrinter{f}
+	case OutputCSV, OutputTSV:
+		return csvPrinter{f}
+	case OutputTable:
+		return tablePrinter{f}
+	case OutputGitHub:
+		return githubPrinter{f}
+	}
+	return textPrinter{f}
+}
+
+// Fields returns the fields of the entry to print, sorted by key: nested
+// objects are given as dotted keys, the fields that are part of its time,
+// level, logger or message and the excluded fields are left out. Unlike the
+// text output, the other fields, like hostname and pid, are all kept. None
+// are returned when the fields aren't shown.
+func (f *Formatter) Fields(entry *Entry, raw json.RawMessage) []Field {
+	if !f.ShowFields {
+		return nil
+	}
+	fields, ok := f.selectFields(entry, raw, 0)
+	if !ok {
+		return nil
+	}
+	excludes := f.ExcludeFields
+	if len(excludes) >= len(defaultExcludes) && slices.Equal(excludes[:len(defaultExcludes)], defaultExcludes) {
+		excludes = excludes[len(defaultExcludes):]
+	}
+	var result []Field
+	for key, value := range fields {
+		included := contains(f.IncludeFields, key) || contains(entry.IncludeFields, key)
+		if !included && (contains(printedKeys, key) || contains(entry.ExcludeFields, key) || contains(excludes, key)) {
+			continue
+		}
+		text, ok := value.(string)
+		if s, isSummary := value.(summary); isSummary {
+			text, ok, value = string(s), true, string(s)
+		}
+		if !ok {
+			data, _ := json.Marshal(value)
+			text = string(data)
+		}
+		result = append(result, Field{key, text, value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// printedKeys are the keys loaded into the time, level and message of an
+// entry, which the printers write under keys of their own.
+var printedKeys = entryTags("Timestamp", "Severity", "Message")
+
+// printerTime returns the time of the entry in the given layout, or the raw
+// timestamp when it couldn't be parsed.
+func printerTime(entry *Entry, layout string) string {
+	if entry.Timestamp != nil && !entry.Timestamp.IsZero() {
+		return entry.Timestamp.Format(layout)
+	}
+	return entry.RawTimestamp
+}
+
+// JSONPrinter writes every entry as a line of json with the same keys: time,
+// level, logger, thread and msg, followed by the fields.
+type JSONPrinter struct{}
+
+func (JSONPrinter) Print(w io.Writer, record *Record) error {
+	entry := record.Entry
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	add := func(key string, value interface{}) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := marshalJSON(key)
+		v, err := marshalJSON(value)
+		if err != nil {
+			v, _ = marshalJSON(fmt.Sprint(value))
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	if t := printerTime(entry, time.RFC3339Nano); t != "" {
+		add("time", t)
+	}
+	if entry.Severity != "" {
+		add("level", strings.ToLower(NormalizeSeverity(entry.Severity)))
+	}
+	if entry.Logger != "" {
+		add("logger", entry.Logger)
+	}
+	if entry.Thread != "" {
+		add("thread", entry.Thread)
+	}
+	add("msg", entry.Message)
+	for _, field := range record.Fields {
+		add(field.Key, field.Raw)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// marshalJSON encodes the value without escaping <, > and &, which are
+// common in messages and redacted values.
+func marshalJSON(value interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// LogfmtPrinter writes every entry as a line of logfmt: time, level, logger,
+// thread and msg, followed by the fields.
+type LogfmtPrinter struct{}
+
+func (LogfmtPrinter) Print(w io.Writer, record *Record) error {
+	entry := record.Entry
+	var pairs []string
+	add := func(key, value string) {
+		pairs = append(pairs, key+"="+logfmtValue(value))
+	}
+	if t := printerTime(entry, time.RFC3339Nano); t != "" {
+		add("time", t)
+	}
+	if entry.Severity != "" {
+		add("level", strings.ToLower(NormalizeSeverity(entry.Severity)))
+	}
+	if entry.Logger != "" {
+		add("logger", entry.Logger)
+	}
+	if entry.Thread != "" {
+		add("thread", entry.Thread)
+	}
+	add("msg", entry.Message)
+	for _, field := range record.Fields {
+		add(field.Key, field.Value)
+	}
+	_, err := io.WriteString(w, strings.Join(pairs, " ")+"\n")
+	return err
+}
+
+// logfmtValue quotes values that are empty or contain spaces, quotes or an
+// equals sign.
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\t\n\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// compactLevels are the abbreviations of the levels in the compact output.
+var compactLevels = map[string]string{
+	"TRACE":     "TRC",
+	"DEBUG":     "DBG",
+	"INFO":      "INF",
+	"NOTICE":    "NTC",
+	"WARNING":   "WRN",
+	"ERROR":     "ERR",
+	"CRITICAL":  "CRT",
+	"FATAL":     "FTL",
+	"PANIC":     "PNC",
+	"ALERT":     "ALR",
+	"EMERGENCY": "EMR",
+}
+
+// CompactPrinter writes every entry on a short line: the time of day, an
+// abbreviated level, the message and the fields.
+type CompactPrinter struct {
+	Colorize bool
+}
+
+func (p CompactPrinter) Print(w io.Writer, record *Record) error {
+	entry := record.Entry
+	var parts []string
+	if t := printerTime(entry, "15:04:05"); t != "" {
+		parts = append(parts, t)
+	}
+	if severity := NormalizeSeverity(entry.Severity); severity != "" {
+		level, ok := compactLevels[severity]
+		if !ok {
+			level = severity
+		}
+		parts = append(parts, p.level(severity, level))
+	}
+	message := entry.Message
+	if entry.Logger != "" {
+		message = entry.Logger + ": " + message
+	}
+	parts = append(parts, message)
+	for _, field := range record.Fields {
+		parts = append(parts, field.Key+"="+logfmtValue(field.Value))
+	}
+	_, err := io.WriteString(w, strings.Join(parts, " ")+"\n")
+	return err
+}
+
+func (p CompactPrinter) level(severity, text string) string {
+	if color, ok := severityColors[severity]; ok && p.Colorize {
+		return color(text)
+	}
+	return text
+}
+
+// ExpandedPrinter writes every entry with its fields below it, a field per
+// line, so long values are easy to read.
+type ExpandedPrinter struct {
+	Colorize bool
+}
+
+func (p ExpandedPrinter) Print(w io.Writer, record *Record) error {
+	entry := record.Entry
+	var b strings.Builder
+	if t := printerTime(entry, "2006-01-02 15:04:05"); t != "" {
+		fmt.Fprintf(&b, "[%s] ", t)
+	}
+	if level := NormalizeSeverity(entry.Severity); level != "" {
+		if color, ok := severityColors[level]; ok && p.Colorize {
+			level = color(level)
+		}
+		fmt.Fprintf(&b, "%s: ", level)
+	}
+	if entry.Logger != "" {
+		fmt.Fprintf(&b, "%s: ", entry.Logger)
+	}
+	b.WriteString(entry.Message)
+	b.WriteString("\n")
+	for _, field := range record.Fields {
+		fmt.Fprintf(&b, "    %s: %s\n", field.Key, strings.ReplaceAll(field.Value, "\n", "\n      "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,61 @@
+package structure
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Quote styles, used by the Formatter to quote string values of fields.
+const (
+	QuoteAuto   = "auto"
+	QuoteAlways = "always"
+	QuoteNever  = "never"
+	// QuoteShell escapes every key=value word so fields can be copied as
+	// shell arguments or environment assignments.
+	QuoteShell = "shell"
+)
+
+// quote quotes the string value of a field according to the quote style,
+// the auto style only quotes values that would otherwise be ambiguous.
+func (f *Formatter) quote(value string) string {
+	switch f.Quote {
+	case QuoteAlways:
+		return strconv.Quote(value)
+	case QuoteShell:
+		return value // the whole key=value word is quoted, by shellWord
+	case QuoteAuto:
+		if value == "" || strings.ContainsAny(value, " \t\n\"=[],") {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
+
+// shellQuote wraps the value in single quotes unless it's a plain word, so
+// the shell expands nothing in it: no history, like !, no home directory,
+// like a leading ~, and no newlines. Values that start with a dash are
+// quoted too, to tell them apart from options.
+func shellQuote(value string) string {
+	if value != "" && value[0] != '-' && strings.IndexFunc(value, isShellUnsafe) == -1 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellWord returns the field as a single word of the shell. The value is
+// quoted after the = when the key is a plain word, like an environment
+// assignment, otherwise the whole word is quoted.
+func shellWord(key, value string) string {
+	if key != "" && key[0] != '-' && strings.IndexFunc(key, isShellUnsafe) == -1 {
+		return key + "=" + shellQuote(value)
+	}
+	return shellQuote(key + "=" + value)
+}
+
+func isShellUnsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("@%+:,./-_", r)
+}
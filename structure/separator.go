@@ -0,0 +1,66 @@
+package structure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+const separatorRune = "─"
+
+// outputDaySeparator writes a full-width line containing the date of the
+// entry when it differs from the date of the previous entry. Nothing is
+// written before the first timestamped entry.
+func (f *Formatter) outputDaySeparator(entry *Entry) error {
+	if entry.Timestamp == nil {
+		return nil
+	}
+	day := entry.Timestamp.Format("2006-01-02")
+	previous := f.lastDay
+	f.lastDay = day
+	if !f.DaySeparator || previous == "" || previous == day {
+		return nil
+	}
+	_, err := fmt.Fprintln(f.output, separatorColor(separator(day, f.Width)))
+	return err
+}
+
+// outputBootSeparator writes a line with the id of the boot of the entry when
+// it differs from the boot of the previous entry, like journalctl does.
+func (f *Formatter) outputBootSeparator(entry *Entry) error {
+	if entry.Boot == "" {
+		return nil
+	}
+	previous := f.lastBoot
+	f.lastBoot = entry.Boot
+	if previous == "" || previous == entry.Boot {
+		return nil
+	}
+	_, err := fmt.Fprintln(f.output, separatorColor("-- Boot "+entry.Boot+" --"))
+	return err
+}
+
+// Heading writes a full-width line with the title, to start a section of
+// entries. Headings are only rendered by the text and html output.
+func (f *Formatter) Heading(title string) error {
+	if f.Output != "" && f.Output != OutputText && f.Output != OutputHTML {
+		return nil
+	}
+	color.NoColor = !f.Colorize
+	_, err := fmt.Fprintln(f.output, separatorColor(separator(title, f.Width)))
+	return err
+}
+
+func separator(label string, width int) string {
+	label = " " + label + " "
+	side := (width - len(label)) / 2
+	if side < 6 {
+		side = 6
+	}
+	right := width - side - len(label)
+	if right < 6 {
+		right = side
+	}
+	return strings.Repeat(separatorRune, side) + label + strings.Repeat(separatorRune, right)
+}
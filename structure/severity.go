@@ -0,0 +1,105 @@
+package structure
+
+import (
+	"regexp"
+	"strings"
+)
+
+var severityRanks = map[string]int{
+	"TRACE":     0,
+	"DEBUG":     1,
+	"INFO":      2,
+	"NOTICE":    3,
+	"WARNING":   4,
+	"ERROR":     5,
+	"CRITICAL":  6,
+	"FATAL":     6,
+	"PANIC":     6,
+	"ALERT":     7,
+	"EMERGENCY": 8,
+}
+
+// NormalizeSeverity returns the upper case, well known name of the given
+// severity, mapping numeric (bunyan) levels and aliases like WARN.
+func NormalizeSeverity(severity string) string {
+	severity = strings.ToUpper(strings.TrimSpace(severity))
+	if level, ok := severityMapping[severity]; ok {
+		return level
+	}
+	return severity
+}
+
+// severityKeywords are the words that give away the level of a line of plain
+// text, like ERROR or panic:.
+var severityKeywords = regexp.MustCompile(`(?i)\b(fatal|panic|critical|error|warn|warning)\b`)
+
+// InferSeverity returns the severity of a line of plain text, read from the
+// first level keyword in it, or an empty string when there is none.
+func InferSeverity(text string) string {
+	keyword := strings.ToUpper(severityKeywords.FindString(text))
+	switch keyword {
+	case "PANIC", "CRITICAL":
+		return "FATAL"
+	case "":
+		return ""
+	}
+	return NormalizeSeverity(keyword)
+}
+
+// SeverityRank returns the rank of the severity, a higher rank is more
+// severe. Unknown severities return -1.
+func SeverityRank(severity string) int {
+	if rank, ok := severityRanks[NormalizeSeverity(severity)]; ok {
+		return rank
+	}
+	return -1
+}
+
+// StatusSeverity returns the severity of a request with the given http
+// status: errors for 5xx, warnings for 4xx and info otherwise.
+func StatusSeverity(status int) string {
+	switch {
+	case status >= 500:
+		return "ERROR"
+	case status >= 400:
+		return "WARNING"
+	}
+	return "INFO"
+}
+
+// grpcCodes are the names of the gRPC status codes, by number.
+var grpcCodes = []string{
+	"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded",
+	"NotFound", "AlreadyExists", "PermissionDenied", "ResourceExhausted",
+	"FailedPrecondition", "Aborted", "OutOfRange", "Unimplemented", "Internal",
+	"Unavailable", "DataLoss", "Unauthenticated",
+}
+
+// grpcServerErrors are the gRPC status codes caused by the server, the others
+// are considered to be mistakes of the client.
+var grpcServerErrors = map[string]bool{
+	"Unknown": true, "DeadlineExceeded": true, "Unimplemented": true,
+	"Internal": true, "Unavailable": true, "DataLoss": true,
+}
+
+// GRPCCodeName returns the name of a numeric gRPC status code, like NotFound
+// for 5, or an empty string for unknown codes.
+func GRPCCodeName(code int) string {
+	if code < 0 || code >= len(grpcCodes) {
+		return ""
+	}
+	return grpcCodes[code]
+}
+
+// GRPCSeverity returns the severity of a request with the given gRPC status
+// name: errors for codes caused by the server, warnings for other failures
+// and info for OK.
+func GRPCSeverity(code string) string {
+	switch {
+	case strings.EqualFold(code, "OK"):
+		return "INFO"
+	case grpcServerErrors[code]:
+		return "ERROR"
+	}
+	return "WARNING"
+}
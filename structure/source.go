@@ -0,0 +1,109 @@
+package structure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// outputSourceContext writes the lines surrounding the caller of the entry,
+// if the file it references can be found locally.
+func (f *Formatter) outputSourceContext(raw []byte) error {
+	if f.SourceContext <= 0 {
+		return nil
+	}
+	file, line, ok := callerLocation(raw)
+	if !ok {
+		return nil
+	}
+	lines := f.readSource(file)
+	if lines == nil || line > len(lines) {
+		return nil
+	}
+	start := max(line-f.SourceContext, 1)
+	end := min(line+f.SourceContext, len(lines))
+	width := len(strconv.Itoa(end))
+	for n := start; n <= end; n++ {
+		marker := " "
+		text := fmt.Sprintf("%*d | %s", width, n, lines[n-1])
+		if n == line {
+			marker = ">"
+			text = ownFrameColor(text)
+		} else {
+			text = dependencyFrameColor(text)
+		}
+		if _, err := fmt.Fprintf(f.output, "\n  %s %s", marker, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callerLocation extracts the file and line number from a caller-like field,
+// either a "file:line" string or a slog style source object.
+func callerLocation(raw []byte) (string, int, bool) {
+	if source := gjson.GetBytes(raw, "source"); source.IsObject() {
+		file, line := source.Get("file").String(), int(source.Get("line").Int())
+		return file, line, file != "" && line > 0
+	}
+	for _, key := range callerFields {
+		value := gjson.GetBytes(raw, key)
+		if value.Type != gjson.String {
+			continue
+		}
+		file, num, found := strings.Cut(value.String(), ":")
+		if !found {
+			continue
+		}
+		line, err := strconv.Atoi(num)
+		if err == nil && line > 0 {
+			return file, line, true
+		}
+	}
+	return "", 0, false
+}
+
+// readSource returns the lines of the given file, resolved relative to the
+// working directory, caching the result (also when it's not found).
+func (f *Formatter) readSource(file string) []string {
+	if f.sources == nil {
+		f.sources = make(map[string][]string)
+	}
+	if lines, ok := f.sources[file]; ok {
+		return lines
+	}
+	var lines []string
+	for _, candidate := range sourceCandidates(file, f.WorkDir) {
+		data, err := os.ReadFile(candidate)
+		if err == nil {
+			lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+			break
+		}
+	}
+	f.sources[file] = lines
+	return lines
+}
+
+// sourceCandidates lists the local paths that might contain the given file,
+// the path itself and every suffix of it relative to the working directory,
+// the longest suffix first. The base name alone isn't a candidate for a path
+// with directories: it's ambiguous, like the main.go of another package,
+// so no source is better than the wrong one.
+func sourceCandidates(file, workdir string) []string {
+	candidates := []string{file}
+	if workdir == "" {
+		return candidates
+	}
+	parts := strings.Split(filepath.ToSlash(file), "/")
+	for i := range parts {
+		if i > 0 && i == len(parts)-1 {
+			break
+		}
+		candidates = append(candidates, filepath.Join(workdir, filepath.Join(parts[i:]...)))
+	}
+	return candidates
+}
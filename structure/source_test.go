@@ -0,0 +1,67 @@
+package structure
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceContext(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "pkg"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "pkg", "main.go"), []byte("one\ntwo\nthree\nfour\nfive\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{output: buf, WorkDir: dir, SourceContext: 1}
+	err = formatter.outputSourceContext([]byte(`{"caller":"/build/app/pkg/main.go:3"}`))
+	if err != nil {
+		t.Fatalf("outputSourceContext() = %v", err)
+	}
+	expect := "\n    2 | two\n  > 3 | three\n    4 | four"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+
+	buf.Reset()
+	err = formatter.outputSourceContext([]byte(`{"source":{"file":"pkg/main.go","line":5}}`))
+	if err != nil {
+		t.Fatalf("outputSourceContext() = %v", err)
+	}
+	expect = "\n    4 | four\n  > 5 | five"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+
+	// only the base name matches, which could be any main.go:
+	buf.Reset()
+	err = os.WriteFile(filepath.Join(dir, "main.go"), []byte("one\ntwo\nthree\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = formatter.outputSourceContext([]byte(`{"caller":"cmd/tool/main.go:2"}`))
+	if err != nil {
+		t.Fatalf("outputSourceContext() = %v", err)
+	}
+	if buf.String() != "" {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), "")
+	}
+
+	// a caller without directories is looked up by its name:
+	err = formatter.outputSourceContext([]byte(`{"caller":"main.go:2"}`))
+	if err != nil {
+		t.Fatalf("outputSourceContext() = %v", err)
+	}
+	expect = "\n    1 | one\n  > 2 | two\n    3 | three"
+	if buf.String() != expect {
+		t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", buf.String(), expect)
+	}
+}
@@ -2,9 +2,18 @@ package structure
 
 import (
 	"encoding/json"
-	"io"
+	"strings"
+
+	"github.com/tidwall/gjson"
 )
 
+// DefaultErrorFields are the keys that could contain the error message
+// belonging to a stacktrace.
+var DefaultErrorFields = []string{"error", "err", "error.message", "exception"}
+
+// DefaultStackFields are the keys that could contain a multi-line stacktrace.
+var DefaultStackFields = []string{"stacktrace", "stack", "stack_trace", "error.stack_trace", "exc_info", "exception", "errorVerbose"}
+
 // StacktraceFormatter interfaces with the Formatter to format a possible
 // stacktrace in a JSON log line. The Detect method returns true if it's
 // compatible.
@@ -20,15 +29,105 @@ func RegisterStacktracer(tracer StacktraceFormatter) {
 	stacktracers = append(stacktracers, tracer)
 }
 
-func stacktrace(w io.Writer, raw []byte) error {
+func (f *Formatter) outputStacktrace(raw []byte) (bool, error) {
 	var root map[string]interface{}
 	_ = json.Unmarshal(raw, &root)
+	stack := ""
 	for _, tracer := range stacktracers {
 		if tracer.Detect(root) {
-			stack := tracer.Format(root)
-			_, err := w.Write([]byte(stack))
-			return err
+			stack = tracer.Format(root)
+			break
+		}
+	}
+	if stack == "" {
+		stack = genericStacktrace(raw, f.ErrorFields, f.StackFields)
+	}
+	if stack == "" {
+		return false, nil
+	}
+	if len(f.Modules) > 0 {
+		stack = highlightFrames(stack, f.Modules)
+	}
+	_, err := f.output.Write([]byte(stack))
+	return true, err
+}
+
+// genericStacktrace formats the first multi-line value of the given stack
+// fields, preceded by the error message if one of the error fields has one.
+// A first line of the stack that repeats the message is left out.
+func genericStacktrace(raw []byte, errorFields, stackFields []string) string {
+	stack := ""
+	stackField := ""
+	for _, field := range stackFields {
+		value := lookup(raw, field)
+		if value.Type == gjson.String && strings.Contains(strings.TrimSpace(value.String()), "\n") {
+			stack, stackField = value.String(), field
+			break
+		}
+	}
+	if stack == "" {
+		return ""
+	}
+
+	result := ""
+	stack = strings.TrimSpace(stack)
+	for _, field := range errorFields {
+		value := lookup(raw, field)
+		if field != stackField && value.Type == gjson.String && value.String() != "" {
+			result = "\n    " + value.String()
+			// like errorVerbose of pkg/errors, the stack can start with the
+			// message itself:
+			if first, rest, ok := strings.Cut(stack, "\n"); ok && strings.TrimSpace(first) == strings.TrimSpace(value.String()) {
+				stack = strings.TrimSpace(rest)
+			}
+			break
+		}
+	}
+
+	stack = strings.Replace(stack, "\t", "  ", -1)
+	return result + "\n    " + strings.Replace(stack, "\n", "\n    ", -1)
+}
+
+// lookup finds the value of a dotted key, either nested or a literal key.
+func lookup(raw []byte, key string) gjson.Result {
+	value := gjson.GetBytes(raw, key)
+	if !value.Exists() {
+		value = gjson.GetBytes(raw, strings.ReplaceAll(key, ".", "\\."))
+	}
+	return value
+}
+
+// highlightFrames colors the lines of a formatted stacktrace that belong to
+// one of the given modules (or directories) differently than the frames of
+// dependencies and the standard library. The first line, the error message,
+// is left untouched.
+func highlightFrames(stack string, modules []string) string {
+	lines := strings.Split(stack, "\n")
+	seenMessage := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if !seenMessage {
+			seenMessage = true
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if isOwnFrame(trimmed, modules) {
+			lines[i] = indent + ownFrameColor(trimmed)
+		} else {
+			lines[i] = indent + dependencyFrameColor(trimmed)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isOwnFrame(frame string, modules []string) bool {
+	for _, module := range modules {
+		if module != "" && strings.Contains(frame, module) && !strings.Contains(frame, "/vendor/") {
+			return true
 		}
 	}
-	return nil
+	return false
 }
@@ -0,0 +1,24 @@
+package structure
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestHighlightFrames(t *testing.T) {
+	noColor := color.NoColor
+	t.Cleanup(func() { color.NoColor = noColor })
+	color.NoColor = false
+
+	stack := "\n    boom\n    github.com/me/app.run\n      /src/app/run.go:12\n    net/http.(*conn).serve\n      /usr/local/go/src/net/http/server.go:3210"
+	got := highlightFrames(stack, []string{"github.com/me/app", "/src/app"})
+	want := "\n    boom\n" +
+		"    " + ownFrameColor("github.com/me/app.run") + "\n" +
+		"      " + ownFrameColor("/src/app/run.go:12") + "\n" +
+		"    " + dependencyFrameColor("net/http.(*conn).serve") + "\n" +
+		"      " + dependencyFrameColor("/usr/local/go/src/net/http/server.go:3210")
+	if got != want {
+		t.Errorf("highlightFrames() =\n%q\nwant\n%q", got, want)
+	}
+}
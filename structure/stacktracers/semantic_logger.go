@@ -0,0 +1,43 @@
+package stacktracers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+type semanticLogger struct {
+}
+
+func init() {
+	structure.RegisterStacktracer(&semanticLogger{})
+}
+
+// Detect matches the exception of Ruby's semantic_logger, an object with the
+// name and message of the exception and its backtrace as array of lines.
+func (s *semanticLogger) Detect(json map[string]interface{}) bool {
+	exception, ok := json["exception"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = exception["stack_trace"].([]interface{})
+	return ok
+}
+
+func (s *semanticLogger) Format(json map[string]interface{}) string {
+	result := ""
+	exception := json["exception"].(map[string]interface{})
+	for exception != nil {
+		if result != "" {
+			result += "\n    Caused by:"
+		}
+		result += fmt.Sprintf("\n    %v: %v", exception["name"], exception["message"])
+		frames, _ := exception["stack_trace"].([]interface{})
+		for _, frame := range frames {
+			result += "\n      " + strings.TrimSpace(fmt.Sprint(frame))
+		}
+		exception, _ = exception["cause"].(map[string]interface{})
+	}
+	return result
+}
@@ -20,6 +20,12 @@ func (b *zap) Detect(json map[string]interface{}) bool {
 		return false
 	}
 
+	// errorVerbose contains the full error chain with stacks, which is left to
+	// the generic stacktrace formatting:
+	if _, ok := json["errorVerbose"].(string); ok {
+		return false
+	}
+
 	for _, value := range json {
 		var str string
 		if str, ok = value.(string); ok {
@@ -29,13 +35,10 @@ func (b *zap) Detect(json map[string]interface{}) bool {
 		}
 	}
 
-	_, ok = json["stacktrace"].(string)
-	if !ok {
-		return true
-	}
-
-	_, ok = json["stack"].(string)
-	return !ok
+	// the error text is printed above the frames, so only when there are any:
+	stacktrace, _ := json["stacktrace"].(string)
+	stack, _ := json["stack"].(string)
+	return strings.TrimSpace(stacktrace) != "" || strings.TrimSpace(stack) != ""
 }
 
 func (b *zap) Format(json map[string]interface{}) string {
@@ -0,0 +1,50 @@
+package stacktracers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestZapErrorAboveFrames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		logline string
+		expect  string
+	}{
+		{
+			`{"error": "i/o timeout", "stacktrace": "main.run\n\tmain.go:12"}`,
+			"\n    i/o timeout\n    main.run\n      main.go:12",
+		},
+		{
+			`{"error": "querying users: i/o timeout", "stack": "main.run\n\tmain.go:12"}`,
+			"\n    querying users: i/o timeout\n    main.run\n      main.go:12",
+		},
+		{
+			`{"error": "i/o timeout", "trace": "go.uber.org/zap.Stack\n\tzap.go:1"}`,
+			"\n    i/o timeout\n    go.uber.org/zap.Stack\n      zap.go:1",
+		},
+		{
+			`{"error": "i/o timeout", "stack": ""}`,
+			"",
+		},
+		{
+			`{"error": "i/o timeout"}`,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		var root map[string]interface{}
+		if err := json.Unmarshal([]byte(tt.logline), &root); err != nil {
+			t.Fatal(err)
+		}
+		tracer := &zap{}
+		result := ""
+		if tracer.Detect(root) {
+			result = tracer.Format(root)
+		}
+		if result != tt.expect {
+			t.Errorf("\n\tnot match: %q\n\t   expect: %q\n", result, tt.expect)
+		}
+	}
+}
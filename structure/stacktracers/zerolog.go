@@ -0,0 +1,44 @@
+package stacktracers
+
+import (
+	"fmt"
+
+	"github.com/koenbollen/jl/structure"
+)
+
+type zerolog struct {
+}
+
+func init() {
+	structure.RegisterStacktracer(&zerolog{})
+}
+
+// Detect matches the stack of zerolog's pkgerrors marshaler, an array of
+// frames with their func, source and line.
+func (z *zerolog) Detect(json map[string]interface{}) bool {
+	stack, ok := json["stack"].([]interface{})
+	if !ok || len(stack) == 0 {
+		return false
+	}
+	frame, ok := stack[0].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = frame["func"]
+	return ok
+}
+
+func (z *zerolog) Format(json map[string]interface{}) string {
+	result := ""
+	if err, ok := json["error"].(string); ok && err != "" {
+		result += "\n    " + err
+	}
+	for _, frame := range json["stack"].([]interface{}) {
+		frame, ok := frame.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		result += fmt.Sprintf("\n    %v\n      %v:%v", frame["func"], frame["source"], frame["line"])
+	}
+	return result
+}
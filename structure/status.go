@@ -0,0 +1,69 @@
+package structure
+
+import (
+	"strconv"
+
+	"github.com/fatih/color"
+)
+
+// StatusFields are the json keys holding the status of an http request.
+var StatusFields = []string{
+	"status", "status_code", "statusCode", "response_code", "http_status",
+	"http.status_code", "http.response.status_code",
+}
+
+// GRPCFields are the json keys holding the status of a gRPC call.
+var GRPCFields = []string{"grpc_code", "grpc_status", "grpc.code"}
+
+var statusColors = map[string]func(a ...interface{}) string{
+	"INFO":    color.New(color.FgGreen).SprintFunc(),
+	"WARNING": color.New(color.FgYellow).SprintFunc(),
+	"ERROR":   color.New(color.FgRed).SprintFunc(),
+}
+
+// FieldStatusSeverity returns the severity of the status in a status field,
+// like WARNING for status=404 or grpc_code=NotFound. It returns an empty
+// string for other fields and for values that aren't a status.
+func FieldStatusSeverity(key string, value interface{}) string {
+	if contains(GRPCFields, key) {
+		switch v := value.(type) {
+		case float64:
+			if name := GRPCCodeName(int(v)); name != "" {
+				return GRPCSeverity(name)
+			}
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				return FieldStatusSeverity(key, float64(n))
+			}
+			for _, name := range grpcCodes {
+				if v == name {
+					return GRPCSeverity(name)
+				}
+			}
+		}
+		return ""
+	}
+	if !contains(StatusFields, key) {
+		return ""
+	}
+	status := 0
+	switch v := value.(type) {
+	case float64:
+		status = int(v)
+	case string:
+		status, _ = strconv.Atoi(v)
+	}
+	if status < 200 || status >= 600 || (status >= 300 && status < 400) {
+		return ""
+	}
+	return StatusSeverity(status)
+}
+
+// colorStatus colors the formatted value of a status field by its class:
+// green for success, yellow for client errors and red for server errors.
+func colorStatus(key string, value interface{}, formatted string) string {
+	if c, ok := statusColors[FieldStatusSeverity(key, value)]; ok {
+		return c(formatted)
+	}
+	return formatted
+}
@@ -0,0 +1,29 @@
+package structure
+
+import "testing"
+
+func TestFieldStatusSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key   string
+		value interface{}
+		want  string
+	}{
+		{"status", 200.0, "INFO"},
+		{"status", 302.0, ""},
+		{"status_code", "404", "WARNING"},
+		{"http.status_code", 503.0, "ERROR"},
+		{"status", "active", ""},
+		{"grpc_code", "OK", "INFO"},
+		{"grpc_code", "NotFound", "WARNING"},
+		{"grpc.code", 14.0, "ERROR"},
+		{"grpc_status", "13", "ERROR"},
+		{"code", 500.0, ""},
+	}
+	for _, tt := range tests {
+		if got := FieldStatusSeverity(tt.key, tt.value); got != tt.want {
+			t.Errorf("FieldStatusSeverity(%q, %v) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package structure
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// DefaultTableColumns are used by the table output when no columns are
+// given.
+var DefaultTableColumns = []string{"time", "level", "msg"}
+
+// tableWidths are the widths of the columns of the table output, the message
+// column gets the width that is left and other columns get the default.
+var tableWidths = map[string]int{
+	"time":      19,
+	"timestamp": 19,
+	"level":     7,
+	"severity":  7,
+}
+
+const defaultTableWidth = 16
+
+// tablePrinter writes the columns of the entries as aligned rows.
+type tablePrinter struct {
+	f *Formatter
+}
+
+func (p tablePrinter) Print(w io.Writer, record *Record) error {
+	if record.Plain {
+		return p.f.formatTableRaw([]byte(record.Message))
+	}
+	// the columns are aligned and cut off before they're colored:
+	return p.f.formatTable(record.Entry, record.Raw)
+}
+
+// formatTable outputs the entry as a row of fixed-width aligned columns, the
+// header with the column names is written before the first row.
+func (f *Formatter) formatTable(entry *Entry, raw json.RawMessage) error {
+	columns := f.tableColumns()
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "time", "timestamp":
+			values[i] = entry.RawTimestamp
+			if entry.Timestamp != nil {
+				values[i] = entry.Timestamp.Format("2006-01-02 15:04:05")
+			}
+		default:
+			values[i] = f.columnValue(column, entry, raw)
+		}
+	}
+	return f.tableRow(values, entry.Severity)
+}
+
+// formatTableRaw outputs a line that couldn't be parsed as a row with only a
+// message.
+func (f *Formatter) formatTableRaw(line []byte) error {
+	columns := f.tableColumns()
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		if column == "msg" || column == "message" {
+			values[i] = string(line)
+		}
+	}
+	return f.tableRow(values, "")
+}
+
+func (f *Formatter) tableColumns() []string {
+	if len(f.Columns) == 0 {
+		return DefaultTableColumns
+	}
+	return f.Columns
+}
+
+func (f *Formatter) tableRow(values []string, severity string) error {
+	columns := f.tableColumns()
+	widths := f.tableWidths(columns)
+	if !f.wroteHeader {
+		f.wroteHeader = true
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = padRight(strings.ToUpper(column), widths[i])
+		}
+		if _, err := f.output.Write([]byte(strings.TrimRight(strings.Join(header, "  "), " ") + "\n")); err != nil {
+			return err
+		}
+	}
+	cells := make([]string, len(columns))
+	for i, value := range values {
+		cell := padRight(truncate(value, widths[i]), widths[i])
+		if columns[i] == "level" || columns[i] == "severity" {
+			if colorize, ok := severityColors[severity]; ok {
+				cell = colorize(cell)
+			}
+		}
+		cells[i] = cell
+	}
+	_, err := f.output.Write([]byte(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n"))
+	return err
+}
+
+// tableWidths returns the width of every column, the message column is
+// truncated to the width of the terminal that is left by the other columns.
+func (f *Formatter) tableWidths(columns []string) []int {
+	widths := make([]int, len(columns))
+	message := -1
+	used := 0
+	for i, column := range columns {
+		if column == "msg" || column == "message" {
+			message = i
+			continue
+		}
+		widths[i] = defaultTableWidth
+		if width, ok := tableWidths[column]; ok {
+			widths[i] = width
+		}
+		used += widths[i]
+	}
+	if message != -1 {
+		used += 2 * (len(columns) - 1)
+		widths[message] = f.Width - used
+		if widths[message] < defaultTableWidth {
+			widths[message] = defaultTableWidth
+		}
+	}
+	return widths
+}
+
+// truncate shortens the text to the width, ending it with an ellipsis when
+// it's cut off.
+func truncate(text string, width int) string {
+	return truncateRight(strings.ReplaceAll(text, "\n", " "), width)
+}
@@ -0,0 +1,113 @@
+package structure
+
+import (
+	"encoding/json"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// templateFuncs are the functions available in the template of the text
+// output, to keep the columns of a hand-built format aligned. Widths are
+// counted in visible characters, color codes don't count.
+var templateFuncs = template.FuncMap{
+	"pad":    padLeft,
+	"rpad":   padRight,
+	"trunc":  truncateRight,
+	"ltrunc": truncateLeft,
+}
+
+// visibleWidth returns the number of characters of the text that are shown
+// in a terminal.
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(ansiCode.ReplaceAllString(text, ""))
+}
+
+// padLeft right-aligns the text in the width.
+func padLeft(text string, width int) string {
+	if n := visibleWidth(text); n < width {
+		return strings.Repeat(" ", width-n) + text
+	}
+	return text
+}
+
+// padRight left-aligns the text in the width.
+func padRight(text string, width int) string {
+	if n := visibleWidth(text); n < width {
+		return text + strings.Repeat(" ", width-n)
+	}
+	return text
+}
+
+// truncateRight cuts off the end of the text when it's wider than the width,
+// replacing it with an ellipsis. Color codes are kept, and reset after the
+// ellipsis.
+func truncateRight(text string, width int) string {
+	if width < 1 || visibleWidth(text) <= width {
+		return text
+	}
+	var b strings.Builder
+	colored := false
+	for n := 0; text != "" && n < width-1; {
+		if code := ansiCode.FindString(text); code != "" && strings.HasPrefix(text, code) {
+			b.WriteString(code)
+			text = text[len(code):]
+			colored = true
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text)
+		b.WriteString(text[:size])
+		text = text[size:]
+		n++
+	}
+	b.WriteString("…")
+	if colored {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// truncateLeft cuts off the start of the text when it's wider than the width,
+// which keeps the most specific part of names like loggers and paths. The
+// color codes of the part that is cut off are kept, after the ellipsis.
+func truncateLeft(text string, width int) string {
+	n := visibleWidth(text)
+	if width < 1 || n <= width {
+		return text
+	}
+	var b strings.Builder
+	b.WriteString("…")
+	for cut := n - width + 1; cut > 0; {
+		if code := ansiCode.FindString(text); code != "" && strings.HasPrefix(text, code) {
+			b.WriteString(code)
+			text = text[len(code):]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		cut--
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// templateEntry is the data of the template: the entry, with the fields of
+// its raw json.
+type templateEntry struct {
+	*Entry
+	raw json.RawMessage
+}
+
+// Level returns the severity of the entry without the padding that aligns
+// it.
+func (e templateEntry) Level() string {
+	return strings.TrimLeft(e.Severity, " ")
+}
+
+// Field returns a field of the raw json as text, a dotted key for nested
+// fields, or an empty string when it's missing.
+func (e templateEntry) Field(key string) string {
+	return gjson.GetBytes(e.raw, key).String()
+}
@@ -0,0 +1,39 @@
+package structure
+
+import "github.com/tidwall/gjson"
+
+// ThreadFields are the json keys that hold the thread (or goroutine) that
+// logged an entry, the first one that is present is used.
+var ThreadFields = []string{"thread_name", "threadName", "thread", "goroutine", "goroutine_id", "goid"}
+
+// Thread returns the name or id of the thread that logged the entry and the
+// key it was found in, or empty strings when the entry has no thread.
+func Thread(raw []byte) (string, string) {
+	for _, field := range ThreadFields {
+		value := lookup(raw, field)
+		if (value.Type == gjson.String || value.Type == gjson.Number) && value.String() != "" {
+			return value.String(), field
+		}
+	}
+	return "", ""
+}
+
+// extractThread shows the thread of the entry as its own column instead of
+// as a field.
+func (f *Formatter) extractThread(entry *Entry, raw []byte) {
+	thread, field := Thread(raw)
+	if thread == "" {
+		return
+	}
+	entry.Thread = thread
+	entry.ExcludeFields = append(entry.ExcludeFields, field)
+}
+
+// alignThread pads the thread to the widest thread seen so far.
+func (f *Formatter) alignThread(entry *Entry) {
+	if entry.Thread == "" {
+		return
+	}
+	padding := align(entry.Thread, &f.threadWidth)
+	entry.Thread = threadColor("["+entry.Thread+"]") + padding
+}
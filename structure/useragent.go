@@ -0,0 +1,113 @@
+package structure
+
+import (
+	"regexp"
+	"strings"
+)
+
+// userAgentBrowsers are the products of a User-Agent header that name the
+// client, in order of precedence as most browsers claim to be others too.
+var userAgentBrowsers = []struct {
+	token string
+	name  string
+}{
+	{"Googlebot/", "Googlebot"},
+	{"bingbot/", "Bingbot"},
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chromium/", "Chromium"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+	{"curl/", "curl"},
+	{"Wget/", "Wget"},
+	{"python-requests/", "python-requests"},
+	{"Go-http-client/", "Go"},
+	{"okhttp/", "OkHttp"},
+	{"PostmanRuntime/", "Postman"},
+	{"kube-probe/", "kube-probe"},
+}
+
+var userAgentWindows = map[string]string{
+	"10.0": "10", "6.3": "8.1", "6.2": "8", "6.1": "7", "6.0": "Vista", "5.1": "XP",
+}
+
+var userAgentIOS = regexp.MustCompile(`(?:iPhone|CPU) OS (\d+)`)
+var userAgentAndroid = regexp.MustCompile(`Android (\d+)`)
+
+// SummarizeUserAgent returns the browser (or client) and operating system of
+// a User-Agent header, like "Chrome 114 / macOS". It returns an empty string
+// when the client isn't recognized.
+func SummarizeUserAgent(ua string) string {
+	browser := ""
+	for _, b := range userAgentBrowsers {
+		if i := strings.Index(ua, b.token); i != -1 {
+			browser = b.name
+			if version := userAgentMajor(ua[i+len(b.token):]); version != "" {
+				browser += " " + version
+			}
+			break
+		}
+	}
+	if browser == "" && strings.Contains(ua, "Trident/") {
+		browser = "Internet Explorer"
+	}
+	if browser == "" {
+		return ""
+	}
+	if os := userAgentOS(ua); os != "" {
+		return browser + " / " + os
+	}
+	return browser
+}
+
+// userAgentMajor returns the major version at the start of the text.
+func userAgentMajor(text string) string {
+	end := strings.IndexFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	if end == -1 {
+		return text
+	}
+	return text[:end]
+}
+
+func userAgentOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows NT "):
+		version := ua[strings.Index(ua, "Windows NT ")+len("Windows NT "):]
+		if end := strings.IndexAny(version, ";)"); end != -1 {
+			version = version[:end]
+		}
+		if name, ok := userAgentWindows[version]; ok {
+			return "Windows " + name
+		}
+		return "Windows"
+	case strings.Contains(ua, "iPad"):
+		if match := userAgentIOS.FindStringSubmatch(ua); match != nil {
+			return "iPadOS " + match[1]
+		}
+		return "iPadOS"
+	case strings.Contains(ua, "iPhone"):
+		if match := userAgentIOS.FindStringSubmatch(ua); match != nil {
+			return "iOS " + match[1]
+		}
+		return "iOS"
+	case strings.Contains(ua, "Mac OS X"):
+		return "macOS"
+	case strings.Contains(ua, "Android"):
+		if match := userAgentAndroid.FindStringSubmatch(ua); match != nil {
+			return "Android " + match[1]
+		}
+		return "Android"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package structure
+
+import "testing"
+
+func TestSummarizeUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ua, want string
+	}{
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36", "Chrome 114 / macOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36 Edg/114.0.1823.51", "Edge 114 / Windows 10"},
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/114.0", "Firefox 114 / Linux"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Mobile/15E148 Safari/604.1", "Safari 16 / iOS 16"},
+		{"Mozilla/5.0 (Linux; Android 13; SM-S911B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/21.0 Chrome/110.0.5481.154 Mobile Safari/537.36", "Samsung Internet 21 / Android 13"},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "Googlebot 2"},
+		{"curl/8.1.2", "curl 8"},
+		{"kube-probe/1.27", "kube-probe 1"},
+		{"my-custom-client", ""},
+	}
+	for _, tt := range tests {
+		if got := SummarizeUserAgent(tt.ua); got != tt.want {
+			t.Errorf("SummarizeUserAgent(%q) = %q, want %q", tt.ua, got, tt.want)
+		}
+	}
+}
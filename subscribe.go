@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/koenbollen/jl/stream"
+)
+
+// subscribeCommand returns the command that writes the payload of every
+// message on the subject (or topic) of the bus as a line. The password, of
+// --password-file or the server url, is kept out of the arguments, where it
+// would show up in ps: nats reads it from its environment and mosquitto_sub
+// from an options file in the returned directory, which is removed when the
+// subscription ends.
+func subscribeCommand(bus, server, subject, password string) (*exec.Cmd, string, error) {
+	if bus == "nats" {
+		args := []string{"sub", "--raw", subject}
+		var env []string
+		if u, err := url.Parse(server); err == nil && u.User != nil {
+			env = append(env, "NATS_USER="+u.User.Username())
+			if p, ok := u.User.Password(); ok && password == "" {
+				password = p
+			}
+			u.User = nil
+			server = u.String()
+		}
+		if server != "" {
+			args = append(args, "--server", server)
+		}
+		if password != "" {
+			env = append(env, "NATS_PASSWORD="+password)
+		}
+		cmd := exec.Command("nats", args...)
+		cmd.Env = append(os.Environ(), env...)
+		return cmd, "", nil
+	}
+
+	args := []string{"-t", subject}
+	if server != "" {
+		u, err := url.Parse(server)
+		if err != nil || u.Hostname() == "" {
+			return nil, "", fmt.Errorf("%q is not an mqtt://host:port url", server)
+		}
+		args = append(args, "-h", u.Hostname())
+		if port := u.Port(); port != "" {
+			args = append(args, "-p", port)
+		}
+		if u.User != nil {
+			args = append(args, "-u", u.User.Username())
+			if p, ok := u.User.Password(); ok && password == "" {
+				password = p
+			}
+		}
+	}
+	cmd := exec.Command("mosquitto_sub", args...)
+	if password == "" {
+		return cmd, "", nil
+	}
+	dir, err := os.MkdirTemp("", "jl-mqtt")
+	if err != nil {
+		return nil, "", err
+	}
+	err = os.WriteFile(filepath.Join(dir, "mosquitto_sub"), []byte("-P "+password+"\n"), 0o600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", err
+	}
+	cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+dir)
+	return cmd, dir, nil
+}
+
+// readPassword reads the password of --password-file, without the trailing
+// newline.
+func readPassword(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// openSubscription subscribes to the subject of a NATS server or the topic of
+// an MQTT broker and reads the payload of every message as a line, through
+// the nats and mosquitto_sub commands.
+func openSubscription(bus, server, subject, password string) (stream.Stream, error) {
+	cmd, dir, err := subscribeCommand(bus, server, subject, password)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = os.Stderr
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("the %s command is required to subscribe to %s", cmd.Args[0], bus)
+		}
+		return nil, err
+	}
+	r, w := io.Pipe()
+	go func() {
+		_, err := io.Copy(w, output)
+		if werr := cmd.Wait(); err == nil && werr != nil {
+			err = fmt.Errorf("%s: %w", cmd.Args[0], werr)
+		}
+		os.RemoveAll(dir)
+		w.CloseWithError(err)
+	}()
+	return stream.NewSource(r, subject), nil
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+const defaultWidth = 80
+
+// fallbackWidth is used when the width can't be requested from the terminal,
+// for example when the output is piped.
+func fallbackWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return defaultWidth
+}
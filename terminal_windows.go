@@ -0,0 +1,5 @@
+package main
+
+func terminalWidth() int {
+	return fallbackWidth()
+}
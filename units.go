@@ -0,0 +1,142 @@
+package main
+
+import (
+	"time"
+
+	"github.com/koenbollen/jl/stream"
+	"github.com/koenbollen/jl/structure"
+	"github.com/tidwall/gjson"
+)
+
+// unitKeys are the journald fields that name the unit of an entry, the first
+// one that is present is used.
+var unitKeys = []string{"_SYSTEMD_UNIT", "UNIT", "SYSLOG_IDENTIFIER", "_COMM"}
+
+const (
+	// unitSlice is the time slice of which the entries are grouped by unit.
+	unitSlice = time.Second
+	// unitDelay is how long entries are held back when no entries of a later
+	// slice arrive, like when following a journal.
+	unitDelay = 500 * time.Millisecond
+)
+
+type heldEntry struct {
+	line  *stream.Line
+	entry *structure.Entry
+}
+
+// unitGrouper holds back the entries of a time slice to output them grouped
+// under a heading of their unit, instead of interleaved. The entries are
+// written when an entry of a later slice arrives, after a short delay, or
+// early when they don't fit in the memory budget.
+type unitGrouper struct {
+	formatter *structure.Formatter
+	budget    *stream.Budget
+	held      int64
+	slice     time.Time
+	units     []string
+	entries   map[string][]heldEntry
+	timer     *time.Timer
+	last      string
+	err       error
+}
+
+func newUnitGrouper(formatter *structure.Formatter, budget *stream.Budget) *unitGrouper {
+	return &unitGrouper{formatter: formatter, budget: budget, entries: map[string][]heldEntry{}}
+}
+
+// add holds back the entry, entry is nil for a line that couldn't be parsed.
+// It returns the error of writing previous entries.
+func (g *unitGrouper) add(line *stream.Line, entry *structure.Entry) error {
+	if entry != nil && entry.Timestamp != nil {
+		slice := entry.Timestamp.Truncate(unitSlice)
+		if !slice.Equal(g.slice) {
+			g.write()
+			g.slice = slice
+		}
+	}
+	unit := ""
+	if entry != nil {
+		var key string
+		unit, key = unitOf(line.JSON)
+		entry.ExcludeFields = append(entry.ExcludeFields, key)
+	}
+	size := line.Size()
+	if !g.budget.Take(size) {
+		// the entries held back make room for this one:
+		g.write()
+		if !g.budget.Take(size) {
+			size = 0
+		}
+	}
+	g.held += size
+	if _, ok := g.entries[unit]; !ok {
+		g.units = append(g.units, unit)
+	}
+	g.entries[unit] = append(g.entries[unit], heldEntry{line, entry})
+	if g.timer == nil {
+		g.timer = time.NewTimer(unitDelay)
+	}
+	return g.err
+}
+
+// next receives the next line, meanwhile the entries that are held back are
+// written when no entry of a later slice arrives in time. Without a grouper
+// it just receives the next line.
+func (g *unitGrouper) next(lines <-chan *stream.Line) (*stream.Line, bool) {
+	for g != nil && g.timer != nil {
+		select {
+		case line, ok := <-lines:
+			return line, ok
+		case <-g.timer.C:
+			g.timer = nil
+			g.write()
+		}
+	}
+	line, ok := <-lines
+	return line, ok
+}
+
+// close writes the entries that are held back and returns the first error of
+// writing entries.
+func (g *unitGrouper) close() error {
+	g.write()
+	return g.err
+}
+
+func (g *unitGrouper) write() {
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	for _, unit := range g.units {
+		if unit != "" && unit != g.last && g.err == nil {
+			g.err = g.formatter.Heading(unit)
+			g.last = unit
+		}
+		for _, held := range g.entries[unit] {
+			if g.err != nil {
+				break
+			}
+			if held.entry == nil {
+				g.err = g.formatter.FormatRaw(held.line.Raw)
+			} else {
+				g.err = g.formatter.Format(held.entry, held.line.JSON, held.line.Prefix, held.line.Suffix)
+			}
+		}
+	}
+	g.budget.Release(g.held)
+	g.held = 0
+	g.units = nil
+	g.entries = map[string][]heldEntry{}
+}
+
+// unitOf returns the unit of the entry and the key it was found in.
+func unitOf(data []byte) (string, string) {
+	for _, key := range unitKeys {
+		if unit := gjson.GetBytes(data, key).String(); unit != "" {
+			return unit, key
+		}
+	}
+	return "", ""
+}